@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"razor-blade/internal/backup"
+	"razor-blade/internal/config"
+	"razor-blade/pkg/database"
+)
+
+// runBackup 执行 backup [file] 子命令，不指定文件时写入备份目录并按保留数量轮换
+func runBackup(cfg *config.Config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: backup [file]")
+	}
+
+	db, err := database.InitDB(&cfg.Database)
+	if err != nil {
+		return err
+	}
+	manager, err := backup.NewManager(db, cfg.Backup.Dir, cfg.Backup.Keep)
+	if err != nil {
+		return err
+	}
+
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+		err = manager.WriteFile(path)
+	} else {
+		path, err = manager.Create()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("backed up to %s\n", path)
+	return nil
+}
+
+// runRestore 执行 restore <file> 子命令，需先停止服务
+func runRestore(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: restore <file>")
+	}
+	if cfg.Database.Driver != "" && cfg.Database.Driver != "sqlite" {
+		return backup.ErrUnsupported
+	}
+	if cfg.Database.Path == "" || cfg.Database.Path == ":memory:" {
+		return fmt.Errorf("database.path must be a file to restore into")
+	}
+
+	previous, err := backup.Restore(args[0], cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	if previous != "" {
+		fmt.Printf("previous database kept at %s\n", previous)
+	}
+	fmt.Printf("restored %s from %s\n", cfg.Database.Path, args[0])
+	return nil
+}
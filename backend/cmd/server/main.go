@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"razor-blade/internal/alert"
+	"razor-blade/internal/backup"
 	"razor-blade/internal/config"
 	"razor-blade/internal/handler"
-	"razor-blade/internal/repository"
 	"razor-blade/internal/router"
 	"razor-blade/internal/service"
-	"razor-blade/pkg/database"
+	"razor-blade/internal/trash"
 	"razor-blade/pkg/logger"
+	"syscall"
+	"time"
+	_ "time/tzdata" // 运行镜像不带时区数据库，按时区统计时需要
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
@@ -22,42 +32,129 @@ func main() {
 
 	// 初始化日志
 	appLogger := logger.InitLogger(&cfg.Log)
+
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(cfg, os.Args[2:]); err != nil {
+				appLogger.Fatalf("Migrate failed: %v", err)
+			}
+			return
+		case "backup":
+			if err := runBackup(cfg, os.Args[2:]); err != nil {
+				appLogger.Fatalf("Backup failed: %v", err)
+			}
+			return
+		case "restore":
+			if err := runRestore(cfg, os.Args[2:]); err != nil {
+				appLogger.Fatalf("Restore failed: %v", err)
+			}
+			return
+		case "serve":
+		default:
+			appLogger.Fatalf("Unknown command %q, expected serve, migrate, backup or restore", os.Args[1])
+		}
+	}
+
+	runServer(cfg, appLogger)
+}
+
+func runServer(cfg *config.Config, appLogger *logrus.Logger) {
 	appLogger.Info("Starting Razor-Blade server...")
 
 	// 设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
 
-	// 初始化数据库
-	db, err := database.InitDB(cfg.Database.Path)
+	// 初始化存储，数据库无法使用时拒绝启动
+	store, db, storage, err := openStore(&cfg.Database, appLogger)
 	if err != nil {
-		appLogger.Warnf("Failed to initialize database: %v", err)
-		appLogger.Info("Running without database (CGO disabled)")
-		db = nil
-	} else {
-		appLogger.Info("Database connected successfully")
+		appLogger.Fatalf("Refusing to start: %v", err)
+	}
+	logStorage(storage, appLogger)
+	var backups *backup.Manager
+	if db != nil {
+		if backups, err = backup.NewManager(db, cfg.Backup.Dir, cfg.Backup.Keep); err != nil {
+			appLogger.Infof("Backups disabled: %v", err)
+		}
 	}
 
 	// 初始化各层
-	repo := repository.NewRepository(db)
-	svc := service.NewService(repo)
-	h := handler.NewHandler(svc, appLogger)
+	alerts := alert.NewEvaluator(store, alert.NewNotifiers(&cfg.Alerts, appLogger),
+		cfg.Inventory.LowStockThreshold, appLogger)
+	svc := service.NewService(store, cfg, alerts)
+	h := handler.NewHandler(svc, backups, storage, appLogger)
 
-	// 自动迁移数据库 (仅在数据库可用时)
-	if db != nil {
-		if err := repo.AutoMigrate(); err != nil {
-			appLogger.Fatalf("Failed to migrate database: %v", err)
-		}
-		appLogger.Info("Database migration completed")
-	} else {
-		appLogger.Info("Skipping database migration (no database connection)")
+	// 定期清理回收站，只读时不清理
+	purgeAfter := cfg.Trash.PurgeAfter
+	if storage.ReadOnly {
+		purgeAfter = 0
 	}
+	purger := trash.NewPurger(store, purgeAfter, cfg.Trash.PurgeInterval, appLogger)
+	purger.Start()
+
+	// 定期备份数据库
+	scheduler := backup.NewScheduler(backups, cfg.Backup.Interval, appLogger)
+	scheduler.Start()
 
 	// 设置路由
-	r := router.SetupRouter(h, appLogger)
+	r := router.SetupRouter(h, svc, svc, storage.ReadOnly, appLogger)
 
 	// 启动服务器
-	appLogger.Infof("Server starting on port %s", cfg.Server.Port)
-	if err := r.Run(":" + cfg.Server.Port); err != nil {
-		appLogger.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		appLogger.Infof("Server starting on port %s", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	failed := false
+	select {
+	case err := <-serveErr:
+		appLogger.Errorf("Failed to start server: %v", err)
+		failed = true
+	case sig := <-quit:
+		appLogger.Infof("Received %s, shutting down", sig)
+	}
+	signal.Stop(quit)
+
+	shutdownServer(srv, cfg.Server.ShutdownTimeout, appLogger)
+
+	// 请求都已结束，再停止后台任务并关闭数据库
+	scheduler.Stop()
+	purger.Stop()
+	alerts.Wait()
+	if db != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				appLogger.Warnf("Failed to close database: %v", err)
+			}
+		}
+	}
+	appLogger.Info("Server stopped")
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// shutdownServer 停止接收新请求，等待进行中的请求完成，超时后强制关闭连接
+func shutdownServer(srv *http.Server, timeout time.Duration, appLogger *logrus.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		appLogger.Warnf("Graceful shutdown timed out, closing remaining connections: %v", err)
+		srv.Close()
 	}
-}
\ No newline at end of file
+}
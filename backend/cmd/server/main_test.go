@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// startServer 在随机端口上启动服务，started在请求进入处理函数时关闭
+func startServer(t *testing.T, handle func()) (*http.Server, string, chan struct{}) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		handle()
+		w.WriteHeader(http.StatusOK)
+	})}
+	go srv.Serve(ln)
+	return srv, "http://" + ln.Addr().String(), started
+}
+
+// request 在后台发送请求，返回状态码或错误
+func request(url string) chan error {
+	done := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				err = fmt.Errorf("status %s", resp.Status)
+			}
+		}
+		done <- err
+	}()
+	return done
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	srv, url, started := startServer(t, func() { time.Sleep(200 * time.Millisecond) })
+	done := request(url)
+	<-started
+
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+	shutdownServer(srv, 5*time.Second, logger)
+
+	if err := <-done; err != nil {
+		t.Errorf("in-flight request failed: %v", err)
+	}
+	if strings.Contains(logs.String(), "timed out") {
+		t.Errorf("unexpected timeout: %s", logs.String())
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("server still accepts requests after shutdown")
+	}
+}
+
+func TestShutdownClosesConnectionsAfterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv, url, started := startServer(t, func() { <-release })
+	done := request(url)
+	<-started
+
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+	begin := time.Now()
+	shutdownServer(srv, 100*time.Millisecond, logger)
+
+	// 超时后不再等待卡住的请求
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("shutdown took %v, want about the 100ms timeout", elapsed)
+	}
+	if !strings.Contains(logs.String(), "timed out") {
+		t.Errorf("timeout not logged: %q", logs.String())
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("stuck request succeeded, want its connection closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("stuck request's connection was not closed")
+	}
+}
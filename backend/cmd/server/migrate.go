@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"razor-blade/internal/config"
+	"razor-blade/internal/migration"
+	"razor-blade/pkg/database"
+)
+
+// runMigrate 执行 migrate up|down|status 子命令
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate up|down|status")
+	}
+
+	db, err := database.InitDB(&cfg.Database)
+	if err != nil {
+		return err
+	}
+	migrator := migration.New(db)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		m, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("no migration to roll back")
+			return nil
+		}
+		fmt.Printf("rolled back %03d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d_%-30s %s\n", st.Version, st.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"razor-blade/internal/config"
+	"razor-blade/internal/migration"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"razor-blade/pkg/database"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// openStore 按database.mode打开存储。file模式下数据库无法使用时返回错误，不退回到内存存储；
+// SQLite数据库文件不可写时以只读方式打开，并设置cfg.Database.ReadOnly。db只在file模式下不为nil
+func openStore(cfg *config.DatabaseConfig, logger *logrus.Logger) (repository.Store, *gorm.DB, model.StorageInfo, error) {
+	info := model.StorageInfo{Mode: cfg.Mode}
+	switch cfg.Mode {
+	case config.DatabaseModeMemory:
+		return repository.NewMemoryStore(), nil, info, nil
+	case config.DatabaseModeDemo:
+		return repository.NewDemoStore(), nil, info, nil
+	case "", config.DatabaseModeFile:
+		info.Mode = config.DatabaseModeFile
+	default:
+		return nil, nil, info, fmt.Errorf("unknown database.mode %q, expected file, memory or demo", cfg.Mode)
+	}
+
+	if (cfg.Driver == "" || cfg.Driver == "sqlite") && !cfg.ReadOnly && cfg.Path != "" && !database.SQLiteWritable(cfg.Path) {
+		logger.Warnf("Database file %s is not writable, opening it read-only", cfg.Path)
+		cfg.ReadOnly = true
+	}
+	db, err := database.InitDB(cfg)
+	if err != nil {
+		return nil, nil, info, err
+	}
+	info.Driver = db.Dialector.Name()
+	info.Persistent = true
+	info.ReadOnly = cfg.ReadOnly
+	logger.Infof("Database connected successfully (driver: %s)", info.Driver)
+
+	// 检查并执行数据库迁移，拒绝在更新版本的数据库结构上运行
+	migrator := migration.New(db)
+	if err := migrator.CheckVersion(); err != nil {
+		return nil, nil, info, err
+	}
+	if cfg.AutoMigrate && !cfg.ReadOnly {
+		applied, err := migrator.Up()
+		if err != nil {
+			return nil, nil, info, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		for _, m := range applied {
+			logger.Infof("Applied migration %03d_%s", m.Version, m.Name)
+		}
+	}
+	current, err := migrator.CurrentVersion()
+	if err != nil {
+		return nil, nil, info, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if current < migrator.LatestVersion() {
+		return nil, nil, info, fmt.Errorf("database schema at version %d, expected %d; run `migrate up` first",
+			current, migrator.LatestVersion())
+	}
+	logger.Infof("Database schema at version %d", current)
+	return repository.NewGormStore(db), db, info, nil
+}
+
+// logStorage 在启动日志中说明存储方式，数据不会保留或不能修改时醒目地警告
+func logStorage(info model.StorageInfo, logger *logrus.Logger) {
+	switch {
+	case !info.Persistent:
+		logger.Warn("****************************************************************")
+		logger.Warnf("* Running with the in-memory %s store (database.mode: %s)", info.Mode, info.Mode)
+		logger.Warn("* ALL DATA WILL BE LOST WHEN THE SERVER STOPS")
+		logger.Warn("****************************************************************")
+	case info.ReadOnly:
+		logger.Warn("****************************************************************")
+		logger.Warnf("* Database (%s) is READ-ONLY, all changes will be rejected", info.Driver)
+		logger.Warn("****************************************************************")
+	default:
+		logger.Infof("Storage mode: %s (driver: %s)", info.Mode, info.Driver)
+	}
+}
@@ -0,0 +1,135 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Evaluator 在库存变化后检查刀片是否低于告警阈值。
+// 同一刀片同一级别的告警只发送一次，库存恢复后解除，再次低于阈值时重新告警
+type Evaluator struct {
+	store     repository.Store
+	notifiers []Notifier
+	threshold int // 刀片未单独设置时的默认阈值
+	logger    *logrus.Logger
+
+	mu sync.Mutex     // 串行化检查，避免并发请求重复创建告警
+	wg sync.WaitGroup // 未完成的通知发送
+}
+
+func NewEvaluator(store repository.Store, notifiers []Notifier, threshold int, logger *logrus.Logger) *Evaluator {
+	return &Evaluator{
+		store:     store,
+		notifiers: notifiers,
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+// Evaluate 检查指定刀片的库存，出错只记录日志，不影响触发检查的请求
+func (e *Evaluator) Evaluate(bladeIDs ...uint) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[uint]bool, len(bladeIDs))
+	for _, id := range bladeIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := e.evaluate(id); err != nil {
+			e.logger.WithError(err).WithField("blade_id", id).Error("Failed to evaluate stock alert")
+		}
+	}
+}
+
+func (e *Evaluator) evaluate(bladeID uint) error {
+	blade, err := e.store.GetBladeByID(bladeID)
+	if errors.Is(err, repository.ErrBladeNotFound) {
+		// 刀片已删除，告警随之级联删除
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	threshold := blade.EffectiveLowStockThreshold(e.threshold)
+	level := stockLevel(blade.RemainingQuantity, threshold)
+
+	open, err := e.store.GetOpenAlert(bladeID)
+	if err != nil && !errors.Is(err, repository.ErrAlertNotFound) {
+		return err
+	}
+	if open != nil {
+		if open.Level == level {
+			return nil
+		}
+		if err := e.store.ResolveAlert(open.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	if level == "" {
+		return nil
+	}
+
+	alert := &model.Alert{
+		UserID:            blade.UserID,
+		BladeID:           bladeID,
+		Level:             level,
+		RemainingQuantity: blade.RemainingQuantity,
+		Threshold:         threshold,
+		Message:           alertMessage(blade, level, threshold),
+		CreatedAt:         time.Now().UTC(),
+	}
+	if err := e.store.CreateAlert(alert); err != nil {
+		return err
+	}
+	e.dispatch(Event{Alert: *alert, Blade: *blade})
+	return nil
+}
+
+// dispatch 异步发送通知，避免外部服务拖慢请求
+func (e *Evaluator) dispatch(event Event) {
+	for _, n := range e.notifiers {
+		e.wg.Add(1)
+		go func(n Notifier) {
+			defer e.wg.Done()
+			if err := n.Notify(event); err != nil {
+				e.logger.WithError(err).WithFields(logrus.Fields{
+					"notifier": n.Name(),
+					"alert_id": event.Alert.ID,
+				}).Error("Failed to send stock alert")
+			}
+		}(n)
+	}
+}
+
+// Wait 等待已触发的通知发送完成，用于关闭服务前
+func (e *Evaluator) Wait() {
+	e.wg.Wait()
+}
+
+// stockLevel 返回库存对应的告警级别，库存充足时为空
+func stockLevel(remaining, threshold int) string {
+	switch {
+	case remaining <= 0:
+		return model.AlertLevelOutOfStock
+	case remaining <= threshold:
+		return model.AlertLevelLowStock
+	}
+	return ""
+}
+
+func alertMessage(blade *model.Blade, level string, threshold int) string {
+	if level == model.AlertLevelOutOfStock {
+		return fmt.Sprintf("刀片 %s %s 已用完", blade.Brand, blade.Model)
+	}
+	return fmt.Sprintf("刀片 %s %s 库存不足：剩余 %d 片（阈值 %d）",
+		blade.Brand, blade.Model, blade.RemainingQuantity, threshold)
+}
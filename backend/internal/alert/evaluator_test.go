@@ -0,0 +1,128 @@
+package alert
+
+import (
+	"io"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingNotifier 记录收到的告警级别
+type recordingNotifier struct {
+	mu     sync.Mutex
+	levels []string
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Notify(event Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.levels = append(n.levels, event.Alert.Level)
+	return nil
+}
+
+func (n *recordingNotifier) take() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	levels := n.levels
+	n.levels = nil
+	return levels
+}
+
+func TestEvaluatorNotifiesOncePerLevel(t *testing.T) {
+	store := repository.NewMemoryStore()
+	notifier := &recordingNotifier{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	e := NewEvaluator(store, []Notifier{notifier}, 2, logger)
+
+	razor := &model.Razor{Brand: "Merkur", Model: "34C"}
+	if err := store.CreateRazor(razor); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	blade := &model.Blade{Brand: "Astra", Model: "SP", CompatibleRazorIDs: []uint{razor.ID},
+		Purchases: []model.Purchase{{PurchaseDate: at, Quantity: 4}}}
+	if err := store.CreateBlade(blade); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每次换刀扣减一片库存后检查
+	changeBlade := func() {
+		t.Helper()
+		at = at.Add(24 * time.Hour)
+		record := &model.UsageRecord{UsageTime: at, RazorID: razor.ID, BladeID: blade.ID, NeedBladeChange: true}
+		if err := store.CreateUsageRecord(record); err != nil {
+			t.Fatal(err)
+		}
+		e.Evaluate(blade.ID)
+		e.Wait()
+	}
+	expect := func(step string, want ...string) {
+		t.Helper()
+		got := notifier.take()
+		if len(got) != len(want) {
+			t.Fatalf("%s: notifications = %v, want %v", step, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%s: notifications = %v, want %v", step, got, want)
+			}
+		}
+	}
+	openLevel := func() string {
+		t.Helper()
+		open, err := store.GetOpenAlert(blade.ID)
+		if err != nil {
+			return ""
+		}
+		return open.Level
+	}
+
+	changeBlade() // 剩余3
+	expect("above threshold")
+	changeBlade() // 剩余2
+	expect("reaches threshold", model.AlertLevelLowStock)
+	changeBlade() // 剩余1，仍是库存不足
+	expect("second mutation below threshold")
+	e.Evaluate(blade.ID)
+	e.Wait()
+	expect("re-evaluate without change")
+	changeBlade() // 剩余0
+	expect("runs out", model.AlertLevelOutOfStock)
+	if level := openLevel(); level != model.AlertLevelOutOfStock {
+		t.Errorf("open alert level = %q", level)
+	}
+
+	// 补货后解除告警，不发送通知
+	purchase := &model.Purchase{BladeID: &blade.ID, PurchaseDate: at, Quantity: 5}
+	if err := store.CreatePurchase(purchase); err != nil {
+		t.Fatal(err)
+	}
+	e.Evaluate(blade.ID)
+	e.Wait()
+	expect("restocked")
+	if level := openLevel(); level != "" {
+		t.Errorf("open alert after restock = %q", level)
+	}
+
+	// 再次低于阈值时重新告警
+	changeBlade()
+	changeBlade()
+	expect("still above threshold")
+	changeBlade() // 剩余2
+	expect("low again", model.AlertLevelLowStock)
+
+	alerts, total, err := store.GetAlerts(model.AlertFilter{BladeID: blade.ID}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Errorf("alert history = %d entries, want 3: %+v", total, alerts)
+	}
+}
@@ -0,0 +1,25 @@
+package alert
+
+import "github.com/sirupsen/logrus"
+
+// LogNotifier 将告警写入应用日志
+type LogNotifier struct {
+	logger *logrus.Logger
+}
+
+func NewLogNotifier(logger *logrus.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Name() string { return "log" }
+
+func (n *LogNotifier) Notify(event Event) error {
+	n.logger.WithFields(logrus.Fields{
+		"alert_id":           event.Alert.ID,
+		"blade_id":           event.Alert.BladeID,
+		"alert_level":        event.Alert.Level,
+		"remaining_quantity": event.Alert.RemainingQuantity,
+		"threshold":          event.Alert.Threshold,
+	}).Warn(event.Alert.Message)
+	return nil
+}
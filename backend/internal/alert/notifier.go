@@ -0,0 +1,35 @@
+package alert
+
+import (
+	"razor-blade/internal/config"
+	"razor-blade/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event 发送给通知渠道的告警内容
+type Event struct {
+	Alert model.Alert `json:"alert"`
+	Blade model.Blade `json:"blade"`
+}
+
+// Notifier 告警通知渠道
+type Notifier interface {
+	Name() string
+	Notify(event Event) error
+}
+
+// NewNotifiers 按配置创建启用的通知渠道
+func NewNotifiers(cfg *config.AlertConfig, logger *logrus.Logger) []Notifier {
+	var notifiers []Notifier
+	if cfg.Log {
+		notifiers = append(notifiers, NewLogNotifier(logger))
+	}
+	if cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(&cfg.Webhook))
+	}
+	if cfg.SMTP.Host != "" {
+		notifiers = append(notifiers, NewSMTPNotifier(&cfg.SMTP))
+	}
+	return notifiers
+}
@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"razor-blade/internal/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpTimeout 连接和整个发送过程的超时
+const smtpTimeout = 10 * time.Second
+
+// SMTPNotifier 通过邮件发送告警，服务器支持时使用STARTTLS
+type SMTPNotifier struct {
+	cfg config.SMTPConfig
+}
+
+func NewSMTPNotifier(cfg *config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: *cfg}
+}
+
+func (n *SMTPNotifier) Name() string { return "smtp" }
+
+func (n *SMTPNotifier) Notify(event Event) error {
+	if len(n.cfg.To) == 0 {
+		return fmt.Errorf("smtp: no recipients configured")
+	}
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range n.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.message(event)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message 构造纯文本邮件，主题按RFC 2047编码
+func (n *SMTPNotifier) message(event Event) []byte {
+	subject := fmt.Sprintf("[Razor-Blade] %s %s 库存告警", event.Blade.Brand, event.Blade.Model)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	fmt.Fprintf(&b, "%s\r\n\r\n", event.Alert.Message)
+	fmt.Fprintf(&b, "剩余数量: %d\r\n", event.Alert.RemainingQuantity)
+	fmt.Fprintf(&b, "告警阈值: %d\r\n", event.Alert.Threshold)
+	fmt.Fprintf(&b, "时间: %s\r\n", event.Alert.CreatedAt.Format(time.RFC3339))
+	return []byte(b.String())
+}
@@ -0,0 +1,145 @@
+package alert
+
+import (
+	"bufio"
+	"mime"
+	"net"
+	"net/textproto"
+	"razor-blade/internal/config"
+	"strings"
+	"testing"
+)
+
+// smtpSession 测试SMTP服务器收到的一封邮件
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer 启动只处理一个连接的最简SMTP服务器，不支持STARTTLS和认证。
+// rejectRcpt非空时对该收件人返回550
+func startSMTPServer(t *testing.T, rejectRcpt string) (host string, port int, done <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	result := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var session smtpSession
+		defer func() { result <- session }()
+
+		tp.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				rcpt := strings.Trim(line[len("RCPT TO:"):], "<>")
+				if rcpt == rejectRcpt {
+					tp.PrintfLine("550 no such user")
+					continue
+				}
+				session.to = append(session.to, rcpt)
+				tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				tp.PrintfLine("354 end with <CR><LF>.<CR><LF>")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				session.data = string(data)
+				tp.PrintfLine("250 OK")
+			case cmd == "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, result
+}
+
+func TestSMTPNotifierSendsMail(t *testing.T) {
+	host, port, done := startSMTPServer(t, "")
+	n := NewSMTPNotifier(&config.SMTPConfig{Host: host, Port: port,
+		From: "alerts@example.com", To: []string{"a@example.com", "b@example.com"}})
+
+	if err := n.Notify(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	session := <-done
+	if session.from != "alerts@example.com" || strings.Join(session.to, ",") != "a@example.com,b@example.com" {
+		t.Errorf("envelope from=%q to=%v", session.from, session.to)
+	}
+
+	header, body, _ := strings.Cut(session.data, "\n\n")
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(header + "\n\n"))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Get("Subject"))
+	if err != nil || subject != "[Razor-Blade] Astra SP 库存告警" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	if msg.Get("To") != "a@example.com, b@example.com" {
+		t.Errorf("To header = %q", msg.Get("To"))
+	}
+	for _, want := range []string{testEvent().Alert.Message, "剩余数量: 1", "告警阈值: 2", "2024-03-01T07:00:00Z"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestSMTPNotifierFailures(t *testing.T) {
+	t.Run("rejected recipient", func(t *testing.T) {
+		host, port, _ := startSMTPServer(t, "gone@example.com")
+		n := NewSMTPNotifier(&config.SMTPConfig{Host: host, Port: port,
+			From: "alerts@example.com", To: []string{"gone@example.com"}})
+		if err := n.Notify(testEvent()); err == nil || !strings.Contains(err.Error(), "550") {
+			t.Errorf("err = %v, want 550", err)
+		}
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		n := NewSMTPNotifier(&config.SMTPConfig{Host: "127.0.0.1", Port: 25, From: "alerts@example.com"})
+		if err := n.Notify(testEvent()); err == nil {
+			t.Error("expected error without recipients")
+		}
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		port := ln.Addr().(*net.TCPAddr).Port
+		ln.Close()
+		n := NewSMTPNotifier(&config.SMTPConfig{Host: "127.0.0.1", Port: port,
+			From: "alerts@example.com", To: []string{"a@example.com"}})
+		if err := n.Notify(testEvent()); err == nil {
+			t.Error("expected error for closed port")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"razor-blade/internal/config"
+	"time"
+)
+
+// WebhookNotifier 将告警以JSON POST到配置的地址，非2xx响应视为失败。
+// 连接失败或5xx响应时按配置的次数重试，4xx响应不重试
+type WebhookNotifier struct {
+	url        string
+	headers    map[string]string
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration // 首次重试前的等待时间，之后每次加倍
+}
+
+func NewWebhookNotifier(cfg *config.WebhookConfig) *WebhookNotifier {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &WebhookNotifier{
+		url:        cfg.URL,
+		headers:    cfg.Headers,
+		client:     &http.Client{Timeout: timeout},
+		retries:    cfg.Retries,
+		retryDelay: time.Second,
+	}
+}
+
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+func (n *WebhookNotifier) Notify(event Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event": "blade." + event.Alert.Level,
+		"alert": event.Alert,
+		"blade": event.Blade,
+	})
+	if err != nil {
+		return err
+	}
+
+	delay := n.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(body)
+		if err == nil || !retry || attempt >= n.retries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post 发送一次请求，返回失败时是否值得重试
+func (n *WebhookNotifier) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return false, nil
+}
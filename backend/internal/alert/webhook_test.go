@@ -0,0 +1,100 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"razor-blade/internal/config"
+	"razor-blade/internal/model"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testEvent() Event {
+	return Event{
+		Alert: model.Alert{ID: 7, BladeID: 3, Level: model.AlertLevelLowStock, RemainingQuantity: 1, Threshold: 2,
+			Message: "刀片 Astra SP 库存不足：剩余 1 片（阈值 2）", CreatedAt: time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)},
+		Blade: model.Blade{ID: 3, Brand: "Astra", Model: "SP", RemainingQuantity: 1},
+	}
+}
+
+func newTestWebhook(url string, retries int) *WebhookNotifier {
+	n := NewWebhookNotifier(&config.WebhookConfig{URL: url, Retries: retries,
+		Headers: map[string]string{"Authorization": "Bearer secret"}})
+	n.retryDelay = time.Millisecond
+	return n
+}
+
+func TestWebhookNotifierPayload(t *testing.T) {
+	var got struct {
+		Event string      `json:"event"`
+		Alert model.Alert `json:"alert"`
+		Blade model.Blade `json:"blade"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+			r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request = %s content-type=%q authorization=%q",
+				r.Method, r.Header.Get("Content-Type"), r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := newTestWebhook(server.URL, 0).Notify(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != "blade.low_stock" || got.Alert.ID != 7 || got.Alert.RemainingQuantity != 1 ||
+		got.Blade.Brand != "Astra" || got.Blade.ID != 3 {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestWebhookNotifierRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		statuses []int // 依次返回的状态码，超出后重复最后一个
+		retries  int
+		wantErr  bool
+		wantHits int32
+	}{
+		{"recovers after server error", []int{500, 502, 200}, 2, false, 3},
+		{"gives up after retries", []int{503}, 2, true, 3},
+		{"client error is not retried", []int{400}, 2, true, 1},
+		{"no retries configured", []int{500}, 0, true, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var hits int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := int(atomic.AddInt32(&hits, 1)) - 1
+				if i >= len(tc.statuses) {
+					i = len(tc.statuses) - 1
+				}
+				w.WriteHeader(tc.statuses[i])
+			}))
+			defer server.Close()
+
+			err := newTestWebhook(server.URL, tc.retries).Notify(testEvent())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("err = %v, want error %v", err, tc.wantErr)
+			}
+			if hits != tc.wantHits {
+				t.Errorf("requests = %d, want %d", hits, tc.wantHits)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	if err := newTestWebhook(url, 1).Notify(testEvent()); err == nil {
+		t.Error("expected error for closed server")
+	}
+}
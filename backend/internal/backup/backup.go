@@ -0,0 +1,221 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"razor-blade/internal/migration"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrUnsupported 只有SQLite数据库支持备份
+var ErrUnsupported = errors.New("只有SQLite数据库支持备份")
+
+// 备份目录中的文件名为 razor-blade-<时间>.db，按名称排序即按时间排序
+const (
+	filePrefix = "razor-blade-"
+	fileSuffix = ".db"
+	// timeLayout 精确到微秒，同一秒内多次备份不会重名
+	timeLayout = "20060102-150405.000000"
+)
+
+// Manager 在服务运行时生成一致的SQLite备份，并在备份目录中保留最近的若干份
+type Manager struct {
+	db   *gorm.DB
+	dir  string
+	keep int // 不大于0时保留全部
+}
+
+// NewManager 数据库不是SQLite时返回ErrUnsupported
+func NewManager(db *gorm.DB, dir string, keep int) (*Manager, error) {
+	if db.Dialector.Name() != "sqlite" {
+		return nil, ErrUnsupported
+	}
+	return &Manager{db: db, dir: dir, keep: keep}, nil
+}
+
+// WriteFile 以VACUUM INTO把数据库写入新文件，期间不阻塞其他读写，
+// 得到的是执行时刻的一致快照，不依赖WAL文件。path已存在时返回错误
+func (m *Manager) WriteFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("备份文件 %s 已存在", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return m.db.Exec("VACUUM INTO ?", path).Error
+}
+
+// Create 在备份目录中生成一份备份并删除超出保留数量的旧备份，返回备份文件路径
+func (m *Manager) Create() (string, error) {
+	path := filepath.Join(m.dir, Filename(time.Now()))
+	if err := m.WriteFile(path); err != nil {
+		return "", err
+	}
+	return path, m.rotate()
+}
+
+// rotate 删除超出保留数量的旧备份
+func (m *Manager) rotate() error {
+	if m.keep <= 0 {
+		return nil
+	}
+	files, err := m.List()
+	if err != nil {
+		return err
+	}
+	for len(files) > m.keep {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// List 返回备份目录中的备份文件，旧的在前
+func (m *Manager) List() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(m.dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Filename 在at时刻生成的备份的文件名
+func Filename(at time.Time) string {
+	return filePrefix + at.Format(timeLayout) + fileSuffix
+}
+
+// Verify 检查文件是完整的razor-blade数据库，且结构版本不高于当前程序
+func Verify(path string) error {
+	db, err := openSQLite(path)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	var problems []string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&problems).Error; err != nil {
+		return fmt.Errorf("无法检查 %s: %w", path, err)
+	}
+	if len(problems) != 1 || problems[0] != "ok" {
+		return fmt.Errorf("%s 已损坏: %s", path, strings.Join(problems, "; "))
+	}
+	var violations []map[string]interface{}
+	if err := db.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%s 中有%d处外键不一致", path, len(violations))
+	}
+	if !db.Migrator().HasTable("schema_migrations") {
+		return fmt.Errorf("%s 不是razor-blade的数据库", path)
+	}
+	return migration.New(db).CheckVersion()
+}
+
+// Restore 用备份文件替换dest处的数据库，只能在服务停止时执行，dest仍被打开时返回错误。
+// 备份先复制到dest旁的临时文件并通过校验，再整体替换，替换前的数据库保留为
+// <dest>.pre-restore-<时间>，返回该文件路径，dest不存在时返回空字符串
+func Restore(src, dest string) (string, error) {
+	if err := checkNotInUse(dest); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	tmp := dest + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := Verify(tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	var previous string
+	if _, err := os.Stat(dest); err == nil {
+		// 通过SQLite生成副本，包含WAL中尚未写回的修改
+		previous = dest + ".pre-restore-" + time.Now().Format(timeLayout)
+		if err := snapshot(dest, previous); err != nil {
+			os.Remove(tmp)
+			return "", fmt.Errorf("无法保留替换前的数据库: %w", err)
+		}
+	}
+	// 旧数据库的WAL和共享内存文件不能留给新数据库
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dest + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmp)
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return previous, nil
+}
+
+func snapshot(src, dest string) error {
+	db, err := openSQLite(src)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	m := &Manager{db: db}
+	return m.WriteFile(dest)
+}
+
+func openSQLite(path string) (*gorm.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(sqlite.Open(path+"?_busy_timeout=10000"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("无法打开 %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// copyFile 复制文件并写入磁盘
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"razor-blade/internal/config"
+	"razor-blade/internal/migration"
+	"razor-blade/pkg/database"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB 在path处打开并迁移SQLite数据库，返回关闭函数
+func openTestDB(t *testing.T, path string) (*gorm.DB, func()) {
+	t.Helper()
+	db, err := database.InitDB(&config.DatabaseConfig{Driver: "sqlite", Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = db.Logger.LogMode(logger.Silent)
+	if _, err := migration.New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+	closed := false
+	closeDB := func() {
+		if closed {
+			return
+		}
+		closed = true
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+	t.Cleanup(closeDB)
+	return db, closeDB
+}
+
+func TestCreateWithinSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	db, _ := openTestDB(t, filepath.Join(dir, "razor-blade.db"))
+	manager, err := NewManager(db, filepath.Join(dir, "backups"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var created []string
+	for i := 0; i < 3; i++ {
+		path, err := manager.Create()
+		if err != nil {
+			t.Fatalf("backup %d: %v", i+1, err)
+		}
+		created = append(created, path)
+	}
+
+	files, err := manager.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只保留最新的两份，且按名称排序与创建顺序一致
+	if len(files) != 2 || files[0] != created[1] || files[1] != created[2] {
+		t.Errorf("backups = %v, want %v", files, created[1:])
+	}
+	for _, file := range files {
+		if err := Verify(file); err != nil {
+			t.Errorf("verify %s: %v", file, err)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "razor-blade.db")
+	db, closeDB := openTestDB(t, dest)
+	manager, err := NewManager(db, filepath.Join(dir, "backups"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := manager.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 数据库仍被打开时拒绝替换，原文件保持不变
+	if _, err := Restore(src, dest); err == nil || !strings.Contains(err.Error(), "正在被使用") {
+		t.Fatalf("restore while open: err = %v, want in-use error", err)
+	}
+	if _, err := os.Stat(dest + ".restore"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	closeDB()
+	previous, err := Restore(src, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(previous), "razor-blade.db.pre-restore-") {
+		t.Errorf("previous = %s", previous)
+	}
+	for _, path := range []string{dest, previous} {
+		if err := Verify(path); err != nil {
+			t.Errorf("verify %s: %v", path, err)
+		}
+	}
+}
+
+func TestRestoreRejectsInvalidBackup(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "not-a-backup.db")
+	if err := os.WriteFile(src, []byte("not a database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "razor-blade.db")
+	if _, err := Restore(src, dest); err == nil {
+		t.Fatal("expected error for invalid backup")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("dest created from invalid backup: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// shmDMSOffset SQLite在-shm文件中的“dead man switch”字节，
+// 每个打开WAL数据库的连接在关闭前都持有该字节的读锁
+const shmDMSOffset = 128
+
+// checkNotInUse 数据库仍被打开时返回错误。
+// 通过尝试对-shm文件的DMS字节加写锁判断，加锁成功后立即释放
+func checkNotInUse(path string) error {
+	f, err := os.OpenFile(path+"-shm", os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lock := unix.Flock_t{Type: unix.F_WRLCK, Whence: 0, Start: shmDMSOffset, Len: 1}
+	if err := unix.FcntlFlock(f.Fd(), shmLockCmd, &lock); err != nil {
+		if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EACCES) {
+			return fmt.Errorf("%s 正在被使用，请先停止服务", path)
+		}
+		return err
+	}
+	lock.Type = unix.F_UNLCK
+	return unix.FcntlFlock(f.Fd(), shmLockCmd, &lock)
+}
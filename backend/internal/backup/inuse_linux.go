@@ -0,0 +1,6 @@
+package backup
+
+import "golang.org/x/sys/unix"
+
+// shmLockCmd 使用OFD锁，与同一进程中SQLite持有的POSIX锁也会冲突
+const shmLockCmd = unix.F_OFD_SETLK
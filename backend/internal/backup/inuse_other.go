@@ -0,0 +1,8 @@
+//go:build !unix
+
+package backup
+
+// checkNotInUse 其他系统不检查，Windows上替换仍被打开的文件会直接失败
+func checkNotInUse(path string) error {
+	return nil
+}
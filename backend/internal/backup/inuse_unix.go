@@ -0,0 +1,8 @@
+//go:build unix && !linux
+
+package backup
+
+import "golang.org/x/sys/unix"
+
+// shmLockCmd 没有OFD锁的系统只能发现其他进程打开的数据库
+const shmLockCmd = unix.F_SETLK
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scheduler 定期在备份目录中生成备份
+type Scheduler struct {
+	manager  *Manager
+	interval time.Duration
+	logger   *logrus.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler manager为nil时不启动，如数据库不是SQLite
+func NewScheduler(manager *Manager, interval time.Duration, logger *logrus.Logger) *Scheduler {
+	return &Scheduler{
+		manager:  manager,
+		interval: interval,
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start 按间隔生成备份，第一份在一个间隔之后生成。间隔不大于0时不启动
+func (s *Scheduler) Start() {
+	if s.manager == nil || s.interval <= 0 {
+		s.logger.Info("Scheduled backups disabled")
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Backup()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期备份，等待进行中的备份完成
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Backup 生成一份备份，出错只记录日志
+func (s *Scheduler) Backup() {
+	path, err := s.manager.Create()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to back up database")
+		return
+	}
+	s.logger.WithField("path", path).Info("Database backed up")
+}
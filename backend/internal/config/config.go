@@ -1,22 +1,133 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Log       LogConfig       `mapstructure:"log"`
+	Usage     UsageConfig     `mapstructure:"usage"`
+	Inventory InventoryConfig `mapstructure:"inventory"`
+	Alerts    AlertConfig     `mapstructure:"alerts"`
+	Cost      CostConfig      `mapstructure:"cost"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Trash     TrashConfig     `mapstructure:"trash"`
+	Backup    BackupConfig    `mapstructure:"backup"`
 }
 
 type ServerConfig struct {
 	Port string `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+	// 读取整个请求（含请求体）和只读取请求头的超时
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	// 写出响应的超时，导出和备份下载不受限制
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// 空闲的keep-alive连接保留多久
+	IdleTimeout    time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	// 收到SIGINT或SIGTERM后等待进行中的请求完成的最长时间
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
+// 数据存储方式
+const (
+	DatabaseModeFile   = "file"   // 使用driver指定的数据库，连接失败时拒绝启动
+	DatabaseModeMemory = "memory" // 空的内存存储，重启后数据丢失
+	DatabaseModeDemo   = "demo"   // 带示例数据的内存存储，重启后数据丢失
+)
+
 type DatabaseConfig struct {
-	Path string `mapstructure:"path"`
+	// 存储方式: file, memory, demo
+	Mode   string `mapstructure:"mode"`
+	Driver string `mapstructure:"driver"` // sqlite, postgres
+	Path   string `mapstructure:"path"`   // sqlite数据库文件路径
+	DSN    string `mapstructure:"dsn"`    // postgres连接串
+	// 启动时自动执行未应用的迁移，关闭后需手动运行 migrate up
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+	// 以只读方式运行，拒绝所有修改数据的请求。SQLite数据库文件不可写时自动开启
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+// 剃须刀与刀片兼容性检查策略
+const (
+	CompatibilityCheckOff    = "off"
+	CompatibilityCheckWarn   = "warn"
+	CompatibilityCheckReject = "reject"
+)
+
+type UsageConfig struct {
+	// 使用记录的剃须刀与刀片未声明兼容时的处理方式: off, warn, reject
+	CompatibilityCheck string `mapstructure:"compatibility_check"`
+}
+
+type InventoryConfig struct {
+	// 剩余数量不超过该值时视为库存不足
+	LowStockThreshold int `mapstructure:"low_stock_threshold"`
+	// 补货预测：按最近多少天的换刀频率估算消耗速度
+	ForecastWindowDays int `mapstructure:"forecast_window_days"`
+	// 补货预测：下单到收货需要的天数
+	ReorderLeadTimeDays int `mapstructure:"reorder_lead_time_days"`
+}
+
+// AlertConfig 库存告警的通知方式，可同时启用多种
+type AlertConfig struct {
+	Log     bool          `mapstructure:"log"` // 写入应用日志
+	Webhook WebhookConfig `mapstructure:"webhook"`
+	SMTP    SMTPConfig    `mapstructure:"smtp"`
+}
+
+type WebhookConfig struct {
+	URL     string            `mapstructure:"url"` // 为空时不启用，告警以JSON POST到该地址
+	Headers map[string]string `mapstructure:"headers"`
+	Timeout time.Duration     `mapstructure:"timeout"`
+	Retries int               `mapstructure:"retries"` // 连接失败或5xx响应时的重试次数，间隔每次加倍
+}
+
+type SMTPConfig struct {
+	Host     string   `mapstructure:"host"` // 为空时不启用
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// CostConfig 花费统计的货币设置
+type CostConfig struct {
+	// 统计结果使用的基础货币，未填写币种的购买记录视为该货币
+	BaseCurrency string `mapstructure:"base_currency"`
+	// 其他币种兑换为基础货币的汇率，如 USD: 7.1 表示1美元折合7.1基础货币
+	ExchangeRates map[string]float64 `mapstructure:"exchange_rates"`
+}
+
+// AuthConfig 登录会话设置
+type AuthConfig struct {
+	// 登录令牌的有效期
+	SessionTTL time.Duration `mapstructure:"session_ttl"`
+	// 管理员的用户名，可以访问 /api/v1/admin 下的接口，为空时没有管理员
+	AdminUsers []string `mapstructure:"admin_users"`
+}
+
+// TrashConfig 回收站的保留设置
+type TrashConfig struct {
+	// 移入回收站超过该时长的数据被彻底删除，为0时不自动清理
+	PurgeAfter time.Duration `mapstructure:"purge_after"`
+	// 后台检查回收站的间隔
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// BackupConfig SQLite数据库的定期备份设置
+type BackupConfig struct {
+	Dir string `mapstructure:"dir"` // 备份文件保存的目录
+	// 定期备份的间隔，为0时不定期备份
+	Interval time.Duration `mapstructure:"interval"`
+	// 备份目录中保留的备份数量，超出时删除最旧的，为0时全部保留
+	Keep int `mapstructure:"keep"`
 }
 
 type LogConfig struct {
@@ -33,9 +144,35 @@ func LoadConfig() (*Config, error) {
 	// 设置默认值
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.read_timeout", "60s")
+	viper.SetDefault("server.read_header_timeout", "10s")
+	viper.SetDefault("server.write_timeout", "60s")
+	viper.SetDefault("server.idle_timeout", "120s")
+	viper.SetDefault("server.max_header_bytes", 1<<20)
+	viper.SetDefault("server.shutdown_timeout", "15s")
+	viper.SetDefault("database.mode", DatabaseModeFile)
+	viper.SetDefault("database.driver", "sqlite")
 	viper.SetDefault("database.path", "./data/razor-blade.db")
+	viper.SetDefault("database.dsn", "")
+	viper.SetDefault("database.auto_migrate", true)
+	viper.SetDefault("database.read_only", false)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.format", "json")
+	viper.SetDefault("usage.compatibility_check", CompatibilityCheckWarn)
+	viper.SetDefault("inventory.low_stock_threshold", 2)
+	viper.SetDefault("inventory.forecast_window_days", 90)
+	viper.SetDefault("inventory.reorder_lead_time_days", 7)
+	viper.SetDefault("alerts.log", true)
+	viper.SetDefault("alerts.webhook.timeout", "5s")
+	viper.SetDefault("alerts.webhook.retries", 2)
+	viper.SetDefault("alerts.smtp.port", 587)
+	viper.SetDefault("cost.base_currency", "CNY")
+	viper.SetDefault("auth.session_ttl", "720h")
+	viper.SetDefault("trash.purge_after", "720h")
+	viper.SetDefault("trash.purge_interval", "1h")
+	viper.SetDefault("backup.dir", "./data/backups")
+	viper.SetDefault("backup.interval", "24h")
+	viper.SetDefault("backup.keep", 7)
 
 	// 支持环境变量
 	viper.AutomaticEnv()
@@ -54,4 +191,4 @@ func LoadConfig() (*Config, error) {
 	}
 
 	return &config, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,197 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Record 读取到的一行，Values以列名为键，值统一为CSV中的文本形式
+type Record struct {
+	Line   int // CSV和NDJSON为行号，JSON为数组中的序号，从1开始
+	Values map[string]string
+}
+
+// Decode 读取Write写出的内容，返回各实体的行。
+// 单个CSV文件不含实体名，需要由entity指定；zip中按文件名识别实体。
+// 不认识的列忽略，缺少的列视为空值
+func Decode(data []byte, format, entity string) (map[string][]Record, error) {
+	switch format {
+	case FormatCSV:
+		if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+			return decodeZip(data)
+		}
+		if _, ok := Columns[entity]; !ok {
+			return nil, fmt.Errorf("导入单个CSV文件时需要指定实体")
+		}
+		records, err := decodeCSV(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]Record{entity: records}, nil
+	case FormatJSON:
+		return decodeJSON(data)
+	case FormatNDJSON:
+		return decodeNDJSON(data)
+	}
+	return nil, fmt.Errorf("unknown import format %q", format)
+}
+
+func decodeZip(data []byte) (map[string][]Record, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("无法读取zip: %w", err)
+	}
+	result := make(map[string][]Record)
+	for _, f := range zr.File {
+		entity := strings.TrimSuffix(path.Base(f.Name), ".csv")
+		if _, ok := Columns[entity]; !ok {
+			return nil, fmt.Errorf("zip中的文件 %q 不对应任何实体", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		records, err := decodeCSV(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		result[entity] = records
+	}
+	return result, nil
+}
+
+func decodeCSV(r io.Reader) ([]Record, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 表格软件保存的CSV可能带BOM
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	cr.FieldsPerRecord = len(header)
+
+	var records []Record
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		values := make(map[string]string, len(header))
+		for i, column := range header {
+			values[strings.TrimSpace(column)] = fields[i]
+		}
+		records = append(records, Record{Line: line, Values: values})
+	}
+}
+
+func decodeJSON(data []byte) (map[string][]Record, error) {
+	var doc map[string][]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("无法解析JSON: %w", err)
+	}
+	result := make(map[string][]Record)
+	for entity, objects := range doc {
+		if _, ok := Columns[entity]; !ok {
+			return nil, fmt.Errorf("不支持导入 %q", entity)
+		}
+		for i, object := range objects {
+			values, err := textValues(object)
+			if err != nil {
+				return nil, fmt.Errorf("%s 第%d项: %w", entity, i+1, err)
+			}
+			result[entity] = append(result[entity], Record{Line: i + 1, Values: values})
+		}
+	}
+	return result, nil
+}
+
+func decodeNDJSON(data []byte) (map[string][]Record, error) {
+	result := make(map[string][]Record)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var item struct {
+			Entity string                     `json:"entity"`
+			Data   map[string]json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("第%d行: 无法解析JSON: %w", line, err)
+		}
+		if _, ok := Columns[item.Entity]; !ok {
+			return nil, fmt.Errorf("第%d行: 不支持导入 %q", line, item.Entity)
+		}
+		values, err := textValues(item.Data)
+		if err != nil {
+			return nil, fmt.Errorf("第%d行: %w", line, err)
+		}
+		result[item.Entity] = append(result[item.Entity], Record{Line: line, Values: values})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// textValues 把JSON值转为与CSV相同的文本形式：null为空，数组以分号连接
+func textValues(object map[string]json.RawMessage) (map[string]string, error) {
+	values := make(map[string]string, len(object))
+	for column, raw := range object {
+		text, err := textValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column, err)
+		}
+		values[column] = text
+	}
+	return values, nil
+}
+
+func textValue(raw json.RawMessage) (string, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			number, ok := item.(json.Number)
+			if !ok {
+				return "", errors.New("数组中只能是数字")
+			}
+			items[i] = number.String()
+		}
+		return strings.Join(items, ";"), nil
+	}
+	return "", errors.New("不支持嵌套对象")
+}
@@ -0,0 +1,269 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"razor-blade/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 导出格式
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// 可导出的实体，顺序即默认的导出顺序，被引用的实体在前
+const (
+	EntityRazors       = "razors"
+	EntityBlades       = "blades"
+	EntityUsageRecords = "usage_records"
+)
+
+// Entities 全部可导出的实体
+var Entities = []string{EntityRazors, EntityBlades, EntityUsageRecords}
+
+// Columns 各实体导出的列，CSV的表头和JSON的字段都按这里的顺序输出。
+// 已有的列不改名、不删除，新增列只追加在末尾
+var Columns = map[string][]string{
+	EntityRazors: {
+		"id", "brand", "model", "purchase_date", "price", "currency", "notes",
+		"created_at", "updated_at",
+	},
+	EntityBlades: {
+		"id", "brand", "model", "compatible_razor_ids", "purchase_date", "unit_price", "currency",
+		"total_quantity", "remaining_quantity", "low_stock_threshold", "notes",
+		"created_at", "updated_at",
+	},
+	EntityUsageRecords: {
+		"id", "usage_time", "razor_id", "blade_id", "blade_usage_count", "blade_usage_count_override",
+		"rating", "experience_text", "need_blade_change", "created_at", "updated_at",
+	},
+}
+
+// Row 一行数据，与Columns中的列一一对应。
+// 值为nil、string、bool、int、uint、float64、time.Time或[]uint
+type Row []interface{}
+
+func RazorRow(r *model.Razor) Row {
+	return Row{
+		r.ID, r.Brand, r.Model, timeValue(r.PurchaseDate), floatValue(r.Price), r.Currency, r.Notes,
+		r.CreatedAt.UTC(), r.UpdatedAt.UTC(),
+	}
+}
+
+func BladeRow(b *model.Blade) Row {
+	ids := b.CompatibleRazorIDs
+	if ids == nil {
+		ids = []uint{}
+	}
+	return Row{
+		b.ID, b.Brand, b.Model, ids, timeValue(b.PurchaseDate), floatValue(b.UnitPrice), b.Currency,
+		b.TotalQuantity, b.RemainingQuantity, intValue(b.LowStockThreshold), b.Notes,
+		b.CreatedAt.UTC(), b.UpdatedAt.UTC(),
+	}
+}
+
+func UsageRecordRow(r *model.UsageRecord) Row {
+	return Row{
+		r.ID, r.UsageTime.UTC(), r.RazorID, r.BladeID, r.BladeUsageCount, intValue(r.BladeUsageCountOverride),
+		intValue(r.Rating), r.ExperienceText, r.NeedBladeChange, r.CreatedAt.UTC(), r.UpdatedAt.UTC(),
+	}
+}
+
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func floatValue(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func intValue(i *int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return *i
+}
+
+// Source 依次把实体的每一行交给fn，fn返回错误时停止并返回该错误
+type Source func(entity string, fn func(Row) error) error
+
+// ContentType 导出内容的MIME类型，多个实体的CSV打包为zip
+func ContentType(format string, entities []string) string {
+	switch {
+	case format == FormatCSV && len(entities) > 1:
+		return "application/zip"
+	case format == FormatCSV:
+		return "text/csv; charset=utf-8"
+	case format == FormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
+// Filename 下载时的文件名
+func Filename(format string, entities []string, at time.Time) string {
+	name := "razor-blade-" + at.Format("20060102-150405")
+	if len(entities) == 1 {
+		name += "-" + entities[0]
+	}
+	if format == FormatCSV && len(entities) > 1 {
+		return name + ".zip"
+	}
+	return name + "." + format
+}
+
+// Write 按格式把实体逐行写入w，不在内存中保留已写出的数据：
+//   - csv：单个实体为带表头的CSV，多个实体为每个实体一个CSV文件的zip
+//   - json：以实体名为键、行对象数组为值的对象
+//   - ndjson：每行一个 {"entity": 实体名, "data": 行对象}
+func Write(w io.Writer, format string, entities []string, src Source) error {
+	switch format {
+	case FormatCSV:
+		if len(entities) == 1 {
+			return writeCSV(w, entities[0], src)
+		}
+		zw := zip.NewWriter(w)
+		now := time.Now()
+		for _, entity := range entities {
+			f, err := zw.CreateHeader(&zip.FileHeader{Name: entity + ".csv", Method: zip.Deflate, Modified: now})
+			if err != nil {
+				return err
+			}
+			if err := writeCSV(f, entity, src); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	case FormatJSON:
+		return writeJSON(w, entities, src)
+	case FormatNDJSON:
+		return writeNDJSON(w, entities, src)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func writeCSV(w io.Writer, entity string, src Source) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Columns[entity]); err != nil {
+		return err
+	}
+	record := make([]string, len(Columns[entity]))
+	err := src(entity, func(row Row) error {
+		for i, value := range row {
+			record[i] = csvValue(value)
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue 空值为空字符串，时间为RFC3339，ID列表以分号分隔
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []uint:
+		ids := make([]string, len(v))
+		for i, id := range v {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		return strings.Join(ids, ";")
+	}
+	return fmt.Sprint(value)
+}
+
+func writeJSON(w io.Writer, entities []string, src Source) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("{")
+	for i, entity := range entities {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		fmt.Fprintf(bw, "\n%q: [", entity)
+		first := true
+		err := src(entity, func(row Row) error {
+			if !first {
+				bw.WriteString(",")
+			}
+			first = false
+			bw.WriteString("\n  ")
+			return writeObject(bw, Columns[entity], row)
+		})
+		if err != nil {
+			return err
+		}
+		if !first {
+			bw.WriteString("\n")
+		}
+		bw.WriteString("]")
+	}
+	bw.WriteString("\n}\n")
+	return bw.Flush()
+}
+
+func writeNDJSON(w io.Writer, entities []string, src Source) error {
+	bw := bufio.NewWriter(w)
+	for _, entity := range entities {
+		err := src(entity, func(row Row) error {
+			fmt.Fprintf(bw, `{"entity":%q,"data":`, entity)
+			if err := writeObject(bw, Columns[entity], row); err != nil {
+				return err
+			}
+			_, err := bw.WriteString("}\n")
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// writeObject 按列顺序写出JSON对象，保证字段顺序稳定
+func writeObject(bw *bufio.Writer, columns []string, row Row) error {
+	bw.WriteString("{")
+	for i, column := range columns {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		value, err := json.Marshal(row[i])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "%q:", column)
+		bw.Write(value)
+	}
+	_, err := bw.WriteString("}")
+	return err
+}
@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
+	"razor-blade/internal/backup"
+	"razor-blade/internal/middleware"
 	"razor-blade/internal/model"
 	"razor-blade/internal/service"
 
@@ -14,16 +20,34 @@ import (
 
 type Handler struct {
 	service *service.Service
+	backups *backup.Manager // 为nil时不支持备份
+	storage model.StorageInfo
 	logger  *logrus.Logger
 }
 
-func NewHandler(service *service.Service, logger *logrus.Logger) *Handler {
+func NewHandler(service *service.Service, backups *backup.Manager, storage model.StorageInfo, logger *logrus.Logger) *Handler {
 	return &Handler{
 		service: service,
+		backups: backups,
+		storage: storage,
 		logger:  logger,
 	}
 }
 
+// svc 返回只访问当前登录用户数据的服务，修改以当前用户、API令牌和请求ID记入审计日志。
+// 路由未经过认证中间件时不按用户过滤
+func (h *Handler) svc(c *gin.Context) *service.Service {
+	actor := model.AuditActor{RequestID: middleware.RequestID(c)}
+	if token := middleware.CurrentAPIToken(c); token != nil {
+		actor.TokenID = &token.ID
+	}
+	if user := middleware.CurrentUser(c); user != nil {
+		actor.UserID = user.ID
+		return h.service.ForUser(user.ID).WithActor(actor)
+	}
+	return h.service.WithActor(actor)
+}
+
 // 通用响应方法
 func (h *Handler) successResponse(c *gin.Context, data interface{}, message string) {
 	c.JSON(http.StatusOK, model.APIResponse{
@@ -42,6 +66,44 @@ func (h *Handler) errorResponse(c *gin.Context, statusCode int, err string) {
 	})
 }
 
+// deleteErrorResponse 仍有依赖时返回409和依赖报告
+func (h *Handler) deleteErrorResponse(c *gin.Context, err error) {
+	var dep *service.DependencyError
+	if !errors.As(err, &dep) {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+	h.logger.Warn(err)
+	c.JSON(http.StatusConflict, model.APIResponse{
+		Success: false,
+		Data:    dep.Report,
+		Error:   err.Error(),
+		Message: "操作失败",
+	})
+}
+
+// statusForError 参数错误返回400，认证失败返回401，数据不存在返回404，库存不足返回409，其余返回500
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidParam), errors.Is(err, service.ErrIncompatibleBlade):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInsufficientStock):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// clearWriteDeadline 取消server.write_timeout对当前响应的限制，用于耗时与数据量相关的下载
+func (h *Handler) clearWriteDeadline(c *gin.Context) {
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear write deadline")
+	}
+}
+
 // 解析URL参数中的ID
 func (h *Handler) parseIDParam(c *gin.Context) (uint, error) {
 	idStr := c.Param("id")
@@ -60,9 +122,9 @@ func (h *Handler) CreateRazor(c *gin.Context) {
 		return
 	}
 
-	razor, err := h.service.CreateRazor(&req)
+	razor, err := h.svc(c).CreateRazor(&req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -76,9 +138,9 @@ func (h *Handler) GetRazor(c *gin.Context) {
 		return
 	}
 
-	razor, err := h.service.GetRazorByID(id)
+	razor, err := h.svc(c).GetRazorByID(id)
 	if err != nil {
-		h.errorResponse(c, http.StatusNotFound, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -86,15 +148,15 @@ func (h *Handler) GetRazor(c *gin.Context) {
 }
 
 func (h *Handler) GetRazors(c *gin.Context) {
-	var req model.PaginationRequest
+	var req model.RazorListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
 		return
 	}
 
-	result, err := h.service.GetRazors(&req)
+	result, err := h.svc(c).GetRazors(&req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -114,9 +176,9 @@ func (h *Handler) UpdateRazor(c *gin.Context) {
 		return
 	}
 
-	razor, err := h.service.UpdateRazor(id, &req)
+	razor, err := h.svc(c).UpdateRazor(id, &req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -130,14 +192,53 @@ func (h *Handler) DeleteRazor(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteRazor(id); err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+	var req model.DeleteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.svc(c).DeleteRazor(id, &req); err != nil {
+		h.deleteErrorResponse(c, err)
 		return
 	}
 
 	h.successResponse(c, nil, "剃须刀删除成功")
 }
 
+// GetCompatibleBlades 获取声明兼容指定剃须刀的刀片
+func (h *Handler) GetCompatibleBlades(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	blades, err := h.svc(c).GetCompatibleBlades(id)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, blades, "获取兼容刀片成功")
+}
+
+func (h *Handler) GetMountedBlade(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	mounted, err := h.svc(c).GetMountedBlade(id)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, mounted, "获取当前刀片成功")
+}
+
 // 刀片相关处理器
 func (h *Handler) CreateBlade(c *gin.Context) {
 	var req model.CreateBladeRequest
@@ -146,9 +247,9 @@ func (h *Handler) CreateBlade(c *gin.Context) {
 		return
 	}
 
-	blade, err := h.service.CreateBlade(&req)
+	blade, err := h.svc(c).CreateBlade(&req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -162,9 +263,9 @@ func (h *Handler) GetBlade(c *gin.Context) {
 		return
 	}
 
-	blade, err := h.service.GetBladeByID(id)
+	blade, err := h.svc(c).GetBladeByID(id)
 	if err != nil {
-		h.errorResponse(c, http.StatusNotFound, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -172,15 +273,15 @@ func (h *Handler) GetBlade(c *gin.Context) {
 }
 
 func (h *Handler) GetBlades(c *gin.Context) {
-	var req model.PaginationRequest
+	var req model.BladeListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
 		return
 	}
 
-	result, err := h.service.GetBlades(&req)
+	result, err := h.svc(c).GetBlades(&req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -200,9 +301,9 @@ func (h *Handler) UpdateBlade(c *gin.Context) {
 		return
 	}
 
-	blade, err := h.service.UpdateBlade(id, &req)
+	blade, err := h.svc(c).UpdateBlade(id, &req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -216,14 +317,75 @@ func (h *Handler) DeleteBlade(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteBlade(id); err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+	var req model.DeleteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.svc(c).DeleteBlade(id, &req); err != nil {
+		h.deleteErrorResponse(c, err)
 		return
 	}
 
 	h.successResponse(c, nil, "刀片删除成功")
 }
 
+// RecountBlade 根据使用记录重建刀片剩余库存
+func (h *Handler) RecountBlade(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	blade, err := h.svc(c).RecountBladeInventory(id)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, blade, "刀片库存重新计算成功")
+}
+
+func (h *Handler) GetBladeForecast(c *gin.Context) {
+	var req model.BladeForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	report, err := h.svc(c).GetBladeForecast(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, report, "获取补货预测成功")
+}
+
+func (h *Handler) GetBladeLifetime(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	var req model.BladeLifetimeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	lifetime, err := h.svc(c).GetBladeLifetime(id, &req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, lifetime, "获取刀片寿命分析成功")
+}
+
 // 使用记录相关处理器
 func (h *Handler) CreateUsageRecord(c *gin.Context) {
 	var req model.CreateUsageRecordRequest
@@ -232,9 +394,9 @@ func (h *Handler) CreateUsageRecord(c *gin.Context) {
 		return
 	}
 
-	record, err := h.service.CreateUsageRecord(&req)
+	record, err := h.svc(c).CreateUsageRecord(&req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -248,9 +410,9 @@ func (h *Handler) GetUsageRecord(c *gin.Context) {
 		return
 	}
 
-	record, err := h.service.GetUsageRecordByID(id)
+	record, err := h.svc(c).GetUsageRecordByID(id)
 	if err != nil {
-		h.errorResponse(c, http.StatusNotFound, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -258,15 +420,15 @@ func (h *Handler) GetUsageRecord(c *gin.Context) {
 }
 
 func (h *Handler) GetUsageRecords(c *gin.Context) {
-	var req model.PaginationRequest
+	var req model.UsageRecordListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
 		return
 	}
 
-	result, err := h.service.GetUsageRecords(&req)
+	result, err := h.svc(c).GetUsageRecords(&req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -286,9 +448,9 @@ func (h *Handler) UpdateUsageRecord(c *gin.Context) {
 		return
 	}
 
-	record, err := h.service.UpdateUsageRecord(id, &req)
+	record, err := h.svc(c).UpdateUsageRecord(id, &req)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -302,8 +464,8 @@ func (h *Handler) DeleteUsageRecord(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteUsageRecord(id); err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := h.svc(c).DeleteUsageRecord(id); err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -312,9 +474,9 @@ func (h *Handler) DeleteUsageRecord(c *gin.Context) {
 
 // 统计相关处理器
 func (h *Handler) GetDashboard(c *gin.Context) {
-	data, err := h.service.GetDashboardData()
+	data, err := h.svc(c).GetDashboardData()
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
@@ -322,19 +484,432 @@ func (h *Handler) GetDashboard(c *gin.Context) {
 }
 
 func (h *Handler) GetStatistics(c *gin.Context) {
-	stats, err := h.service.GetStatistics()
+	stats, err := h.svc(c).GetStatistics()
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		h.errorResponse(c, statusForError(err), err.Error())
 		return
 	}
 
 	h.successResponse(c, stats, "获取统计数据成功")
 }
 
+func (h *Handler) GetTimeSeries(c *gin.Context) {
+	var req model.TimeSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	series, err := h.svc(c).GetTimeSeries(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, series, "获取时间序列统计成功")
+}
+
+func (h *Handler) GetCostReport(c *gin.Context) {
+	var req model.CostRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	report, err := h.svc(c).GetCostReport(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, report, "获取花费统计成功")
+}
+
+func (h *Handler) GetAlerts(c *gin.Context) {
+	var req model.AlertListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.svc(c).GetAlerts(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "获取告警列表成功")
+}
+
+func (h *Handler) GetAuditEvents(c *gin.Context) {
+	var req model.AuditListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.svc(c).GetAuditEvents(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "获取审计日志成功")
+}
+
+// 回收站相关处理器
+func (h *Handler) GetTrash(c *gin.Context) {
+	var req model.TrashRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	trash, err := h.svc(c).GetTrash(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, trash, "获取回收站成功")
+}
+
+func (h *Handler) RestoreRazor(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	if err := h.svc(c).RestoreRazor(id); err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, nil, "剃须刀恢复成功")
+}
+
+func (h *Handler) RestoreBlade(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	if err := h.svc(c).RestoreBlade(id); err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, nil, "刀片恢复成功")
+}
+
+func (h *Handler) RestoreUsageRecord(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	if err := h.svc(c).RestoreUsageRecord(id); err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, nil, "使用记录恢复成功")
+}
+
+// Export 流式导出数据，开始写出后出错只能记录日志，客户端收到的内容不完整
+func (h *Handler) Export(c *gin.Context) {
+	var req model.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	export, err := h.svc(c).NewExport(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.clearWriteDeadline(c)
+	c.Header("Content-Type", export.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename()+`"`)
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer); err != nil {
+		h.logger.WithError(err).Error("Export aborted")
+	}
+}
+
+// maxImportSize 导入文件的大小上限
+const maxImportSize = 32 << 20
+
+// Import 导入文件，有错误的行时返回400和导入结果，预演时总是返回导入结果
+func (h *Handler) Import(c *gin.Context) {
+	var req model.ImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "读取导入文件失败: "+err.Error())
+		return
+	}
+
+	result, err := h.svc(c).Import(&req, data)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+	if len(result.Errors) > 0 && !result.DryRun {
+		h.logger.Warnf("Import rejected with %d row errors", len(result.Errors))
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Success: false,
+			Data:    result,
+			Error:   "导入文件中有不合法的行，未写入任何数据",
+			Message: "操作失败",
+		})
+		return
+	}
+
+	message := "导入成功"
+	if result.DryRun {
+		message = "导入预演完成"
+	}
+	h.successResponse(c, result, message)
+}
+
+// Backup 下载数据库当前的一致备份，包含全部用户的数据
+func (h *Handler) Backup(c *gin.Context) {
+	if h.backups == nil {
+		h.errorResponse(c, http.StatusNotImplemented, backup.ErrUnsupported.Error())
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "razor-blade-backup-")
+	if err != nil {
+		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	h.clearWriteDeadline(c)
+	name := backup.Filename(time.Now())
+	path := filepath.Join(dir, name)
+	if err := h.backups.WriteFile(path); err != nil {
+		h.errorResponse(c, http.StatusInternalServerError, "备份失败: "+err.Error())
+		return
+	}
+	c.FileAttachment(path, name)
+}
+
+// 购买记录相关处理器
+func (h *Handler) CreatePurchase(c *gin.Context) {
+	var req model.CreatePurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	purchase, err := h.svc(c).CreatePurchase(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, purchase, "购买记录创建成功")
+}
+
+func (h *Handler) GetPurchase(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	purchase, err := h.svc(c).GetPurchaseByID(id)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, purchase, "获取购买记录成功")
+}
+
+func (h *Handler) GetPurchases(c *gin.Context) {
+	var req model.PurchaseListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.svc(c).GetPurchases(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "获取购买记录列表成功")
+}
+
+func (h *Handler) UpdatePurchase(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	var req model.UpdatePurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	purchase, err := h.svc(c).UpdatePurchase(id, &req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, purchase, "购买记录更新成功")
+}
+
+func (h *Handler) DeletePurchase(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	if err := h.svc(c).DeletePurchase(id); err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, nil, "购买记录删除成功")
+}
+
+// 用户认证相关处理器
+func (h *Handler) Register(c *gin.Context) {
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.service.Register(&req)
+	if errors.Is(err, service.ErrUsernameTaken) {
+		h.errorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "注册成功")
+}
+
+func (h *Handler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.service.Login(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "登录成功")
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	if err := h.service.Logout(middleware.BearerToken(c)); err != nil {
+		h.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.successResponse(c, nil, "已退出登录")
+}
+
+func (h *Handler) GetCurrentUser(c *gin.Context) {
+	h.successResponse(c, middleware.CurrentUser(c), "获取当前用户成功")
+}
+
+// API令牌相关处理器
+func (h *Handler) CreateAPIToken(c *gin.Context) {
+	var req model.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.svc(c).CreateAPIToken(&req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "API令牌创建成功，令牌只显示这一次")
+}
+
+func (h *Handler) GetAPITokens(c *gin.Context) {
+	tokens, err := h.svc(c).GetAPITokens()
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, tokens, "获取API令牌列表成功")
+}
+
+func (h *Handler) RevokeAPIToken(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	if err := h.svc(c).RevokeAPIToken(id); err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, nil, "API令牌已撤销")
+}
+
+func (h *Handler) GetAPITokenEvents(c *gin.Context) {
+	id, err := h.parseIDParam(c)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "无效的ID参数")
+		return
+	}
+
+	var req model.APITokenEventListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "请求参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.svc(c).GetAPITokenEvents(id, &req)
+	if err != nil {
+		h.errorResponse(c, statusForError(err), err.Error())
+		return
+	}
+
+	h.successResponse(c, result, "获取API令牌审计记录成功")
+}
+
 // 健康检查
 func (h *Handler) HealthCheck(c *gin.Context) {
 	h.successResponse(c, map[string]interface{}{
 		"status":    "healthy",
 		"timestamp": time.Now().Format("2006-01-02 15:04:05"),
+		"storage":   h.storage,
 	}, "服务正常运行")
-}
\ No newline at end of file
+}
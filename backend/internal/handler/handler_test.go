@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"razor-blade/internal/config"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"razor-blade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestHandler 使用内存存储，未经过认证中间件时服务不按用户过滤
+func newTestHandler(t *testing.T) (*Handler, *service.Service) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		Inventory: config.InventoryConfig{LowStockThreshold: 2},
+		Cost:      config.CostConfig{BaseCurrency: "CNY"},
+	}
+	svc := service.NewService(repository.NewMemoryStore(), cfg, nil)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewHandler(svc, nil, model.StorageInfo{Mode: "memory"}, logger), svc
+}
+
+func TestErrorStatus(t *testing.T) {
+	h, svc := newTestHandler(t)
+	r := gin.New()
+	r.POST("/blades/:id/recount", h.RecountBlade)
+	r.POST("/blades/:id/restore", h.RestoreBlade)
+	r.POST("/razors/:id/restore", h.RestoreRazor)
+	r.PUT("/usage-records/:id", h.UpdateUsageRecord)
+	r.DELETE("/usage-records/:id", h.DeleteUsageRecord)
+	r.POST("/usage-records/:id/restore", h.RestoreUsageRecord)
+	r.PUT("/purchases/:id", h.UpdatePurchase)
+	r.DELETE("/purchases/:id", h.DeletePurchase)
+
+	razor, err := svc.CreateRazor(&model.CreateRazorRequest{Brand: "Merkur", Model: "34C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blade, err := svc.CreateBlade(&model.CreateBladeRequest{Brand: "Astra", Model: "SP", TotalQuantity: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	record, err := svc.CreateUsageRecord(&model.CreateUsageRecordRequest{
+		UsageTime: at, RazorID: razor.ID, BladeID: blade.ID, NeedBladeChange: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	purchases, err := svc.GetPurchases(&model.PurchaseListRequest{BladeID: blade.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	purchase := purchases.Items.([]model.Purchase)[0]
+
+	usageBody := func(razorID, bladeID uint) string {
+		return `{"usage_time":"2024-03-01T07:00:00Z","razor_id":` + itoa(razorID) + `,"blade_id":` + itoa(bladeID) + `}`
+	}
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/blades/999/recount", "", http.StatusNotFound},
+		{http.MethodPost, "/blades/999/restore", "", http.StatusNotFound},
+		{http.MethodPost, "/razors/999/restore", "", http.StatusNotFound},
+		{http.MethodPost, "/usage-records/999/restore", "", http.StatusNotFound},
+		{http.MethodPut, "/usage-records/999", usageBody(razor.ID, blade.ID), http.StatusNotFound},
+		{http.MethodDelete, "/usage-records/999", "", http.StatusNotFound},
+		{http.MethodPut, "/purchases/999", `{"quantity":1}`, http.StatusNotFound},
+		{http.MethodDelete, "/purchases/999", "", http.StatusNotFound},
+		// 引用的刀片不存在属于参数错误
+		{http.MethodPut, "/usage-records/" + itoa(record.ID), usageBody(razor.ID, 999), http.StatusBadRequest},
+		// 已换过1片，数量改为1后剩余为0，再删除购买记录会使剩余为负
+		{http.MethodPut, "/purchases/" + itoa(purchase.ID), `{"quantity":1}`, http.StatusOK},
+		{http.MethodDelete, "/purchases/" + itoa(purchase.ID), "", http.StatusConflict},
+		{http.MethodPost, "/blades/" + itoa(blade.ID) + "/recount", "", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d: %s", tc.method, tc.path, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func TestDeleteWithDependents(t *testing.T) {
+	h, svc := newTestHandler(t)
+	r := gin.New()
+	r.DELETE("/razors/:id", h.DeleteRazor)
+	r.DELETE("/blades/:id", h.DeleteBlade)
+
+	mustRazor := func(name string) *model.Razor {
+		razor, err := svc.CreateRazor(&model.CreateRazorRequest{Brand: "Merkur", Model: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return razor
+	}
+	mustBlade := func(name string, quantity int, razorIDs ...uint) *model.Blade {
+		blade, err := svc.CreateBlade(&model.CreateBladeRequest{Brand: "Astra", Model: name, TotalQuantity: quantity, CompatibleRazorIDs: razorIDs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blade
+	}
+	oldRazor, newRazor := mustRazor("34C"), mustRazor("Futur")
+	oldBlade, newBlade, empty := mustBlade("SP", 5, oldRazor.ID), mustBlade("Superior", 5), mustBlade("Green", 0)
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	var recordIDs []uint
+	for i, change := range []bool{true, false} {
+		record, err := svc.CreateUsageRecord(&model.CreateUsageRecordRequest{
+			UsageTime: at.Add(time.Duration(i) * time.Hour), RazorID: oldRazor.ID, BladeID: oldBlade.ID, NeedBladeChange: change,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		recordIDs = append(recordIDs, record.ID)
+	}
+
+	del := func(path string) (int, model.DependencyReport) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, path, nil))
+		var body struct{ Data model.DependencyReport }
+		if w.Code == http.StatusConflict {
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, body.Data
+	}
+
+	code, report := del("/razors/" + itoa(oldRazor.ID))
+	if code != http.StatusConflict || fmt.Sprint(report.UsageRecordIDs) != fmt.Sprint(recordIDs) ||
+		fmt.Sprint(report.CompatibleBladeIDs) != fmt.Sprint([]uint{oldBlade.ID}) {
+		t.Errorf("delete razor with dependents = %d, %+v", code, report)
+	}
+	code, report = del("/blades/" + itoa(oldBlade.ID))
+	if code != http.StatusConflict || fmt.Sprint(report.UsageRecordIDs) != fmt.Sprint(recordIDs) {
+		t.Errorf("delete blade with dependents = %d, %+v", code, report)
+	}
+	if code, _ := del("/razors/" + itoa(oldRazor.ID) + "?reassign_to=999"); code != http.StatusBadRequest {
+		t.Errorf("reassign to missing razor = %d, want 400", code)
+	}
+	// 目标刀片没有库存，换过刀片的记录无法转移
+	if code, _ := del("/blades/" + itoa(oldBlade.ID) + "?reassign_to=" + itoa(empty.ID)); code != http.StatusConflict {
+		t.Errorf("reassign to empty blade = %d, want 409", code)
+	}
+
+	if code, _ := del("/razors/" + itoa(oldRazor.ID) + "?reassign_to=" + itoa(newRazor.ID)); code != http.StatusOK {
+		t.Fatalf("reassign razor = %d", code)
+	}
+	if code, _ := del("/blades/" + itoa(oldBlade.ID) + "?reassign_to=" + itoa(newBlade.ID)); code != http.StatusOK {
+		t.Fatalf("reassign blade = %d", code)
+	}
+	for _, id := range recordIDs {
+		record, err := svc.GetUsageRecordByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.RazorID != newRazor.ID || record.BladeID != newBlade.ID {
+			t.Errorf("record %d moved to razor %d blade %d, want %d %d", id, record.RazorID, record.BladeID, newRazor.ID, newBlade.ID)
+		}
+	}
+	if blade, _ := svc.GetBladeByID(newBlade.ID); blade.RemainingQuantity != 4 {
+		t.Errorf("target blade remaining = %d, want 4", blade.RemainingQuantity)
+	}
+	if _, err := svc.GetRazorByID(oldRazor.ID); err == nil {
+		t.Error("reassigned razor still visible")
+	}
+	if _, err := svc.GetBladeByID(oldBlade.ID); err == nil {
+		t.Error("reassigned blade still visible")
+	}
+}
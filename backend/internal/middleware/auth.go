@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"razor-blade/internal/model"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 当前用户在gin.Context中的键
+const userKey = "user"
+
+// Authenticator 根据令牌查找用户
+type Authenticator interface {
+	Authenticate(token string) (*model.User, error)
+	// IsAdmin 判断用户是否为管理员
+	IsAdmin(user *model.User) bool
+}
+
+// 认证中间件，校验 Authorization: Bearer <token> 并把当前用户存入gin.Context。
+// 已由APITokenMiddleware认证的请求直接放行
+func AuthMiddleware(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentUser(c) != nil {
+			c.Next()
+			return
+		}
+		token := BearerToken(c)
+		if token == "" {
+			abortUnauthorized(c, "缺少登录令牌")
+			return
+		}
+		user, err := auth.Authenticate(token)
+		if err != nil {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// BearerToken 返回请求头中的Bearer令牌，没有时返回空字符串
+func BearerToken(c *gin.Context) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// CurrentUser 返回认证中间件存入的当前用户，未经过认证时返回nil
+func CurrentUser(c *gin.Context) *model.User {
+	if v, ok := c.Get(userKey); ok {
+		if user, ok := v.(*model.User); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// RequireAdmin 要求当前用户为管理员，需在AuthMiddleware之后使用。
+// API令牌的权限另由RequireScope检查
+func RequireAdmin(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user := CurrentUser(c); user == nil || !auth.IsAdmin(user) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "需要管理员权限",
+				"message": "操作失败",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortUnauthorized(c *gin.Context, err string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"success": false,
+		"error":   err,
+		"message": "操作失败",
+	})
+}
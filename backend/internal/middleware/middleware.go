@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -13,8 +14,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:3002", "http://127.0.0.1:3000", "http://127.0.0.1:3001", "http://127.0.0.1:3002"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
@@ -24,13 +25,14 @@ func CORSMiddleware() gin.HandlerFunc {
 func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		logger.WithFields(logrus.Fields{
-			"status_code":  param.StatusCode,
-			"latency":      param.Latency,
-			"client_ip":    param.ClientIP,
-			"method":       param.Method,
-			"path":         param.Path,
-			"user_agent":   param.Request.UserAgent(),
-			"error":        param.ErrorMessage,
+			"status_code": param.StatusCode,
+			"latency":     param.Latency,
+			"client_ip":   param.ClientIP,
+			"method":      param.Method,
+			"path":        param.Path,
+			"user_agent":  param.Request.UserAgent(),
+			"error":       param.ErrorMessage,
+			"request_id":  param.Keys[requestIDKey],
 		}).Info("HTTP Request")
 		return ""
 	})
@@ -52,4 +54,26 @@ func ErrorHandlerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		}()
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// 只读中间件，数据库只读时拒绝GET、HEAD和OPTIONS以外的请求，exempt中的路由除外
+func ReadOnlyMiddleware(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		for _, path := range exempt {
+			if c.FullPath() == path {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "数据库为只读模式，不能修改数据",
+			"message": "操作失败",
+		})
+	}
+}
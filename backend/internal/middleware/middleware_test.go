@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api/v1", ReadOnlyMiddleware("/api/v1/auth/login"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.POST("/auth/login", ok)
+	api.GET("/razors", ok)
+	api.POST("/razors", ok)
+	api.DELETE("/razors/:id", ok)
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/auth/login", http.StatusOK},
+		{http.MethodGet, "/api/v1/razors", http.StatusOK},
+		{http.MethodPost, "/api/v1/razors", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/razors/1", http.StatusServiceUnavailable},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+	}
+}
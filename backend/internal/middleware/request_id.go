@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求ID的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// 请求ID在gin.Context中的键
+const requestIDKey = "request_id"
+
+// 请求ID中间件，沿用客户端传入的X-Request-ID，没有时生成一个，并在响应头中返回
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			buf := make([]byte, 16)
+			if _, err := rand.Read(buf); err == nil {
+				id = hex.EncodeToString(buf)
+			}
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestID 返回本次请求的ID，未经过RequestIDMiddleware时返回空字符串
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
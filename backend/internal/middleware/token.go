@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"razor-blade/internal/model"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// 当前API令牌在gin.Context中的键
+const apiTokenKey = "api_token"
+
+// APITokenAuthenticator 校验API令牌并记录令牌的使用
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(token string) (*model.User, *model.APIToken, error)
+	RecordAPITokenUse(event *model.APITokenEvent) error
+}
+
+// API令牌认证中间件，Bearer令牌以rbt_开头时按API令牌认证，其他令牌交给AuthMiddleware。
+// 请求结束后更新令牌的最近使用时间并写入审计记录
+func APITokenMiddleware(auth APITokenAuthenticator, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := BearerToken(c)
+		if !strings.HasPrefix(token, model.APITokenPrefix) {
+			c.Next()
+			return
+		}
+		user, apiToken, err := auth.AuthenticateAPIToken(token)
+		if err != nil {
+			abortUnauthorized(c, err.Error())
+			return
+		}
+		c.Set(userKey, user)
+		c.Set(apiTokenKey, apiToken)
+		c.Next()
+
+		event := &model.APITokenEvent{
+			TokenID:    apiToken.ID,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			StatusCode: c.Writer.Status(),
+			ClientIP:   c.ClientIP(),
+		}
+		if err := auth.RecordAPITokenUse(event); err != nil {
+			logger.WithError(err).WithField("token_id", apiToken.ID).Error("Failed to record api token use")
+		}
+	}
+}
+
+// CurrentAPIToken 返回本次请求使用的API令牌，通过登录令牌认证时返回nil
+func CurrentAPIToken(c *gin.Context) *model.APIToken {
+	if v, ok := c.Get(apiTokenKey); ok {
+		if token, ok := v.(*model.APIToken); ok {
+			return token
+		}
+	}
+	return nil
+}
+
+// 权限范围中间件，API令牌的查询请求需要read权限，其余请求需要write权限。
+// 通过登录令牌认证的请求拥有全部权限
+func ScopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := model.ScopeWrite
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			scope = model.ScopeRead
+		}
+		checkScope(c, scope)
+	}
+}
+
+// RequireScope 要求API令牌拥有指定权限
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkScope(c, scope)
+	}
+}
+
+func checkScope(c *gin.Context, scope string) {
+	if token := CurrentAPIToken(c); token != nil && !token.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "API令牌缺少 " + scope + " 权限",
+			"message": "操作失败",
+		})
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"razor-blade/internal/config"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"razor-blade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAPITokenScopesAndRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := repository.NewMemoryStore()
+	svc := service.NewService(store, &config.Config{Auth: config.AuthConfig{SessionTTL: time.Hour}}, nil)
+	auth, err := svc.Register(&model.RegisterRequest{Username: "alice", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := svc.ForUser(auth.User.ID)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	r := gin.New()
+	api := r.Group("/api/v1", APITokenMiddleware(svc, logger), AuthMiddleware(svc), ScopeMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/razors", ok)
+	api.POST("/razors", ok)
+	api.DELETE("/razors/:id", ok)
+	api.GET("/tokens", RequireScope(model.ScopeAdmin), ok)
+
+	create := func(scopes ...string) *model.CreateAPITokenResponse {
+		t.Helper()
+		created, err := alice.CreateAPIToken(&model.CreateAPITokenRequest{Name: strings.Join(scopes, ","), Scopes: scopes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created
+	}
+	read := create(model.ScopeRead)
+	write := create(model.ScopeRead, model.ScopeWrite)
+	admin := create(model.ScopeAdmin)
+
+	// 直接写入一个已过期的令牌，创建接口不接受过去的过期时间
+	expiredSecret := model.APITokenPrefix + "expired"
+	sum := sha256.Sum256([]byte(expiredSecret))
+	past := time.Now().Add(-time.Hour).UTC()
+	if err := store.ForUser(auth.User.ID).CreateAPIToken(&model.APIToken{
+		Name: "expired", Prefix: expiredSecret[:8], TokenHash: hex.EncodeToString(sum[:]),
+		Scopes: []string{model.ScopeAdmin}, ExpiresAt: &past,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(method, path, token string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	for _, tc := range []struct {
+		name, method, path, token string
+		want                      int
+	}{
+		{"read GET", http.MethodGet, "/api/v1/razors", read.Token, http.StatusOK},
+		{"read POST", http.MethodPost, "/api/v1/razors", read.Token, http.StatusForbidden},
+		{"read DELETE", http.MethodDelete, "/api/v1/razors/1", read.Token, http.StatusForbidden},
+		{"read tokens", http.MethodGet, "/api/v1/tokens", read.Token, http.StatusForbidden},
+		{"write POST", http.MethodPost, "/api/v1/razors", write.Token, http.StatusOK},
+		{"write tokens", http.MethodGet, "/api/v1/tokens", write.Token, http.StatusForbidden},
+		{"admin DELETE", http.MethodDelete, "/api/v1/razors/1", admin.Token, http.StatusOK},
+		{"admin tokens", http.MethodGet, "/api/v1/tokens", admin.Token, http.StatusOK},
+		{"expired", http.MethodGet, "/api/v1/razors", expiredSecret, http.StatusUnauthorized},
+		{"unknown", http.MethodGet, "/api/v1/razors", model.APITokenPrefix + "unknown", http.StatusUnauthorized},
+	} {
+		if got := request(tc.method, tc.path, tc.token); got != tc.want {
+			t.Errorf("%s = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// 被拒绝的写入也记入令牌的使用记录
+	events, err := alice.GetAPITokenEvents(read.ID, &model.APITokenEventListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forbidden int
+	for _, event := range events.Items.([]model.APITokenEvent) {
+		if event.Event == model.TokenEventUsed && event.StatusCode == http.StatusForbidden {
+			forbidden++
+		}
+	}
+	if forbidden != 3 {
+		t.Errorf("forbidden uses recorded = %d, want 3", forbidden)
+	}
+
+	if err := alice.RevokeAPIToken(admin.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := request(http.MethodGet, "/api/v1/razors", admin.Token); got != http.StatusUnauthorized {
+		t.Errorf("revoked token = %d, want 401", got)
+	}
+	if got := request(http.MethodGet, "/api/v1/razors", write.Token); got != http.StatusOK {
+		t.Errorf("other token after revoke = %d, want 200", got)
+	}
+}
+
+func TestAPITokenSecretShownOnce(t *testing.T) {
+	svc := service.NewService(repository.NewMemoryStore(), &config.Config{Auth: config.AuthConfig{SessionTTL: time.Hour}}, nil)
+	auth, err := svc.Register(&model.RegisterRequest{Username: "alice", Password: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := svc.ForUser(auth.User.ID)
+
+	created, err := alice.CreateAPIToken(&model.CreateAPITokenRequest{Name: "script", Scopes: []string{model.ScopeRead}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Token, model.APITokenPrefix) || !strings.HasPrefix(created.Token, created.Prefix) {
+		t.Fatalf("created token %q, prefix %q", created.Token, created.Prefix)
+	}
+	data, _ := json.Marshal(created)
+	if !strings.Contains(string(data), created.Token) {
+		t.Errorf("create response does not contain the secret: %s", data)
+	}
+
+	tokens, err := alice.GetAPITokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 {
+		t.Fatalf("tokens = %d, want 1", len(tokens))
+	}
+	data, _ = json.Marshal(tokens)
+	secret := strings.TrimPrefix(created.Token, created.Prefix)
+	if strings.Contains(string(data), secret) {
+		t.Errorf("token list exposes the secret: %s", data)
+	}
+	if strings.Contains(string(data), tokens[0].TokenHash) {
+		t.Errorf("token list exposes the hash: %s", data)
+	}
+}
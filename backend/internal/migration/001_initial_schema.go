@@ -0,0 +1,72 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 001 初始表结构。与早期AutoMigrate生成的结构一致，
+// 因此对已有数据库执行时只会补齐缺失部分而不会修改数据。
+
+type razorV1 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Brand        string `gorm:"not null"`
+	Model        string `gorm:"not null"`
+	PurchaseDate *time.Time
+	Price        *float64
+	Notes        string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	UsageRecords []usageRecordV1 `gorm:"foreignKey:RazorID"`
+}
+
+func (razorV1) TableName() string { return "razors" }
+
+type bladeV1 struct {
+	ID                uint   `gorm:"primaryKey"`
+	Brand             string `gorm:"not null"`
+	Model             string `gorm:"not null"`
+	CompatibleRazors  string
+	PurchaseDate      *time.Time
+	UnitPrice         *float64
+	TotalQuantity     int `gorm:"default:0"`
+	RemainingQuantity int `gorm:"default:0"`
+	Notes             string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+
+	UsageRecords []usageRecordV1 `gorm:"foreignKey:BladeID"`
+}
+
+func (bladeV1) TableName() string { return "blades" }
+
+type usageRecordV1 struct {
+	ID              uint      `gorm:"primaryKey"`
+	UsageTime       time.Time `gorm:"not null"`
+	RazorID         uint      `gorm:"not null"`
+	BladeID         uint      `gorm:"not null"`
+	BladeUsageCount int       `gorm:"default:1"`
+	Rating          *int
+	ExperienceText  string
+	NeedBladeChange bool `gorm:"default:false"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (usageRecordV1) TableName() string { return "usage_records" }
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&razorV1{}, &bladeV1{}, &usageRecordV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			// DropTable按参数逆序删除，子表usage_records放在最后以最先删除
+			return tx.Migrator().DropTable(&razorV1{}, &bladeV1{}, &usageRecordV1{})
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 002 将blades.compatible_razors中的JSON数组拆分到razor_blade_compatibility关联表。
+// 早期前端把该字段当作自由文本填写（如 "Merkur 34C"），无法解析的内容追加到刀片备注中保留
+
+type razorBladeCompatibilityV2 struct {
+	RazorID uint `gorm:"primaryKey;autoIncrement:false"`
+	BladeID uint `gorm:"primaryKey;autoIncrement:false"`
+
+	Razor razorV1 `gorm:"foreignKey:RazorID;constraint:OnDelete:CASCADE"`
+	Blade bladeV1 `gorm:"foreignKey:BladeID;constraint:OnDelete:CASCADE"`
+}
+
+func (razorBladeCompatibilityV2) TableName() string { return "razor_blade_compatibility" }
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "razor_blade_compatibility",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&razorBladeCompatibilityV2{}); err != nil {
+				return err
+			}
+
+			var blades []struct {
+				ID               uint
+				CompatibleRazors string
+				Notes            string
+			}
+			if err := tx.Table("blades").Select("id, compatible_razors, notes").Find(&blades).Error; err != nil {
+				return err
+			}
+			for _, blade := range blades {
+				raw := strings.TrimSpace(blade.CompatibleRazors)
+				if raw == "" {
+					continue
+				}
+				var razorIDs []uint
+				if err := json.Unmarshal([]byte(raw), &razorIDs); err != nil {
+					if err := tx.Table("blades").Where("id = ?", blade.ID).
+						Update("notes", freeTextCompatibilityNote(blade.Notes, raw)).Error; err != nil {
+						return err
+					}
+					continue
+				}
+				for _, razorID := range razorIDs {
+					// 跳过已不存在的剃须刀，旧数据从未校验过这些ID
+					var exists int64
+					if err := tx.Table("razors").Where("id = ?", razorID).Count(&exists).Error; err != nil {
+						return err
+					}
+					if exists == 0 {
+						continue
+					}
+					if err := tx.Exec(
+						"INSERT INTO razor_blade_compatibility (razor_id, blade_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+						razorID, blade.ID,
+					).Error; err != nil {
+						return err
+					}
+				}
+			}
+
+			// 直接使用ALTER TABLE DROP COLUMN（SQLite 3.35+与PostgreSQL均支持），
+			// 避免SQLite迁移器重建blades表时触发usage_records的外键检查
+			return tx.Exec("ALTER TABLE blades DROP COLUMN compatible_razors").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&bladeV1{}, "CompatibleRazors"); err != nil {
+				return err
+			}
+
+			var rows []razorBladeCompatibilityV2
+			if err := tx.Order("blade_id, razor_id").Find(&rows).Error; err != nil {
+				return err
+			}
+			byBlade := make(map[uint][]uint)
+			for _, row := range rows {
+				byBlade[row.BladeID] = append(byBlade[row.BladeID], row.RazorID)
+			}
+			for bladeID, razorIDs := range byBlade {
+				raw, err := json.Marshal(razorIDs)
+				if err != nil {
+					return err
+				}
+				if err := tx.Table("blades").Where("id = ?", bladeID).
+					Update("compatible_razors", string(raw)).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Migrator().DropTable(&razorBladeCompatibilityV2{})
+		},
+	})
+}
+
+// freeTextCompatibilityNote 把无法解析的兼容信息追加到原备注之后
+func freeTextCompatibilityNote(notes, raw string) string {
+	note := "兼容剃须刀：" + raw
+	if notes = strings.TrimSpace(notes); notes != "" {
+		return notes + "\n" + note
+	}
+	return note
+}
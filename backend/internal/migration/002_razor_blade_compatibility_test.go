@@ -0,0 +1,52 @@
+package migration
+
+import "testing"
+
+func TestRazorBladeCompatibilityKeepsFreeText(t *testing.T) {
+	db := openTestDB(t)
+	upTo(t, db, 1)
+
+	for _, stmt := range []string{
+		"INSERT INTO razors (id, brand, model) VALUES (1, 'Merkur', '34C')",
+		// JSON数组，99号剃须刀不存在
+		"INSERT INTO blades (id, brand, model, compatible_razors, notes) VALUES (1, 'Astra', 'SP', '[1, 99]', '')",
+		// 早期前端填写的自由文本
+		"INSERT INTO blades (id, brand, model, compatible_razors, notes) VALUES (2, 'Feather', 'Pro', 'Merkur 34C', 'daily')",
+		"INSERT INTO blades (id, brand, model, compatible_razors, notes) VALUES (3, 'Derby', 'Extra', ' Gillette Tech ', '')",
+		"INSERT INTO blades (id, brand, model, compatible_razors, notes) VALUES (4, 'Personna', 'Lab Blue', '', 'spare')",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := New(db).Up(); err != nil {
+		t.Fatalf("upgrade with free-text compatible_razors: %v", err)
+	}
+
+	var pairs []struct{ RazorID, BladeID uint }
+	if err := db.Raw("SELECT razor_id, blade_id FROM razor_blade_compatibility ORDER BY blade_id").Scan(&pairs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 1 || pairs[0].RazorID != 1 || pairs[0].BladeID != 1 {
+		t.Errorf("compatibility rows = %+v, want only razor 1 / blade 1", pairs)
+	}
+
+	for id, want := range map[int]string{
+		1: "",
+		2: "daily\n兼容剃须刀：Merkur 34C",
+		3: "兼容剃须刀：Gillette Tech",
+		4: "spare",
+	} {
+		var notes string
+		if err := db.Raw("SELECT notes FROM blades WHERE id = ?", id).Scan(&notes).Error; err != nil {
+			t.Fatal(err)
+		}
+		if notes != want {
+			t.Errorf("blade %d notes = %q, want %q", id, notes, want)
+		}
+	}
+	if db.Migrator().HasColumn("blades", "compatible_razors") {
+		t.Error("compatible_razors column still present")
+	}
+}
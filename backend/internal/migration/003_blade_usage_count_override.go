@@ -0,0 +1,57 @@
+package migration
+
+import "gorm.io/gorm"
+
+// 003 新增blade_usage_count_override列，并按换刀记录重新计算已有记录的blade_usage_count
+
+type usageRecordV3 struct {
+	BladeUsageCountOverride *int
+}
+
+func (usageRecordV3) TableName() string { return "usage_records" }
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "blade_usage_count_override",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&usageRecordV3{}, "BladeUsageCountOverride"); err != nil {
+				return err
+			}
+
+			// 旧数据的使用次数由前端填写，不可信，按换刀标记重新编号
+			var records []struct {
+				ID              uint
+				RazorID         uint
+				BladeID         uint
+				BladeUsageCount int
+				NeedBladeChange bool
+			}
+			if err := tx.Table("usage_records").
+				Select("id, razor_id, blade_id, blade_usage_count, need_blade_change").
+				Order("razor_id, usage_time, id").
+				Find(&records).Error; err != nil {
+				return err
+			}
+			count := 0
+			for i, record := range records {
+				if i == 0 || records[i-1].RazorID != record.RazorID ||
+					records[i-1].BladeID != record.BladeID || records[i-1].NeedBladeChange {
+					count = 0
+				}
+				count++
+				if record.BladeUsageCount == count {
+					continue
+				}
+				if err := tx.Exec("UPDATE usage_records SET blade_usage_count = ? WHERE id = ?",
+					count, record.ID).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("ALTER TABLE usage_records DROP COLUMN blade_usage_count_override").Error
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 004 刀片单独的库存告警阈值和告警历史表
+
+type bladeV4 struct {
+	LowStockThreshold *int
+}
+
+func (bladeV4) TableName() string { return "blades" }
+
+type alertV4 struct {
+	ID                uint   `gorm:"primaryKey"`
+	BladeID           uint   `gorm:"not null;index"`
+	Level             string `gorm:"not null"`
+	RemainingQuantity int
+	Threshold         int
+	Message           string
+	CreatedAt         time.Time
+	ResolvedAt        *time.Time
+
+	Blade bladeV1 `gorm:"foreignKey:BladeID;constraint:OnDelete:CASCADE"`
+}
+
+func (alertV4) TableName() string { return "alerts" }
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "low_stock_alerts",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&bladeV4{}, "LowStockThreshold"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&alertV4{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&alertV4{}); err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE blades DROP COLUMN low_stock_threshold").Error
+		},
+	})
+}
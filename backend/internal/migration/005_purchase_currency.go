@@ -0,0 +1,36 @@
+package migration
+
+import "gorm.io/gorm"
+
+// 005 剃须刀和刀片记录购买时使用的币种
+
+type razorV5 struct {
+	Currency string `gorm:"not null;default:''"`
+}
+
+func (razorV5) TableName() string { return "razors" }
+
+type bladeV5 struct {
+	Currency string `gorm:"not null;default:''"`
+}
+
+func (bladeV5) TableName() string { return "blades" }
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "purchase_currency",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&razorV5{}, "Currency"); err != nil {
+				return err
+			}
+			return tx.Migrator().AddColumn(&bladeV5{}, "Currency")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE blades DROP COLUMN currency").Error; err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE razors DROP COLUMN currency").Error
+		},
+	})
+}
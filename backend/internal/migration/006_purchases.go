@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 006 购买记录表。已有刀片和剃须刀的购买信息转为各自的首条购买记录，
+// 未填写购买日期的以录入时间代替
+
+type purchaseV6 struct {
+	ID           uint      `gorm:"primaryKey"`
+	RazorID      *uint     `gorm:"index"`
+	BladeID      *uint     `gorm:"index"`
+	PurchaseDate time.Time `gorm:"not null"`
+	Quantity     int       `gorm:"not null"`
+	UnitPrice    *float64
+	Currency     string `gorm:"not null;default:''"`
+	Vendor       string
+	Notes        string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Razor razorV1 `gorm:"foreignKey:RazorID;constraint:OnDelete:CASCADE"`
+	Blade bladeV1 `gorm:"foreignKey:BladeID;constraint:OnDelete:CASCADE"`
+}
+
+func (purchaseV6) TableName() string { return "purchases" }
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "purchases",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&purchaseV6{}); err != nil {
+				return err
+			}
+			// 没有购买数量的刀片视为尚未购买
+			if err := tx.Exec(`INSERT INTO purchases
+				(blade_id, purchase_date, quantity, unit_price, currency, vendor, notes, created_at, updated_at)
+				SELECT id, COALESCE(purchase_date, created_at), total_quantity, unit_price, currency, '', '', created_at, updated_at
+				FROM blades WHERE total_quantity > 0 ORDER BY id`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`INSERT INTO purchases
+				(razor_id, purchase_date, quantity, unit_price, currency, vendor, notes, created_at, updated_at)
+				SELECT id, COALESCE(purchase_date, created_at), 1, price, currency, '', '', created_at, updated_at
+				FROM razors WHERE price IS NOT NULL OR purchase_date IS NOT NULL ORDER BY id`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&purchaseV6{})
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 007 用户和登录会话，各业务表增加所属用户。
+// 已有数据的user_id为0，由第一个注册的用户接管
+
+type userV7 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Username     string `gorm:"not null;uniqueIndex"`
+	PasswordHash string `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (userV7) TableName() string { return "users" }
+
+type sessionV7 struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+
+	User userV7 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (sessionV7) TableName() string { return "sessions" }
+
+// ownedTablesV7 增加user_id列的业务表
+var ownedTablesV7 = []string{"razors", "blades", "usage_records", "purchases", "alerts"}
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "users",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&userV7{}, &sessionV7{}); err != nil {
+				return err
+			}
+			for _, table := range ownedTablesV7 {
+				if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN user_id integer NOT NULL DEFAULT 0", table)).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec(fmt.Sprintf("CREATE INDEX idx_%s_user_id ON %s (user_id)", table, table)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range ownedTablesV7 {
+				// SQLite不能删除带索引的列，先删索引
+				if err := tx.Exec(fmt.Sprintf("DROP INDEX idx_%s_user_id", table)).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN user_id", table)).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&sessionV7{}, &userV7{})
+		},
+	})
+}
@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 008 个人API令牌及其审计记录
+
+type apiTokenV8 struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"not null"`
+	Prefix     string `gorm:"not null"`
+	TokenHash  string `gorm:"not null;uniqueIndex"`
+	Scopes     string `gorm:"not null"` // JSON数组
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	User userV7 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (apiTokenV8) TableName() string { return "api_tokens" }
+
+type apiTokenEventV8 struct {
+	ID         uint   `gorm:"primaryKey"`
+	TokenID    uint   `gorm:"not null;index"`
+	Event      string `gorm:"not null"`
+	Method     string
+	Path       string
+	StatusCode int
+	ClientIP   string
+	CreatedAt  time.Time
+
+	Token apiTokenV8 `gorm:"foreignKey:TokenID;constraint:OnDelete:CASCADE"`
+}
+
+func (apiTokenEventV8) TableName() string { return "api_token_events" }
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "api_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&apiTokenV8{}, &apiTokenEventV8{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiTokenEventV8{}, &apiTokenV8{})
+		},
+	})
+}
@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 009 数据修改的审计日志，只追加不修改
+
+type auditEventV9 struct {
+	ID           uint `gorm:"primaryKey"`
+	UserID       uint `gorm:"not null;default:0;index"`
+	ActorUserID  uint
+	ActorTokenID *uint
+	RequestID    string
+	EntityType   string `gorm:"not null;index:idx_audit_events_entity"`
+	EntityID     uint   `gorm:"not null;index:idx_audit_events_entity"`
+	Action       string `gorm:"not null"`
+	Before       string // JSON
+	After        string // JSON
+	CreatedAt    time.Time
+}
+
+func (auditEventV9) TableName() string { return "audit_events" }
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "audit_events",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&auditEventV9{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&auditEventV9{})
+		},
+	})
+}
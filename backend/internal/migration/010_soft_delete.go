@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// 010 剃须刀、刀片和使用记录改为软删除，deleted_at非空表示在回收站中
+
+type razorV10 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (razorV10) TableName() string { return "razors" }
+
+type bladeV10 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (bladeV10) TableName() string { return "blades" }
+
+type usageRecordV10 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (usageRecordV10) TableName() string { return "usage_records" }
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "soft_delete",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range []interface{}{&razorV10{}, &bladeV10{}, &usageRecordV10{}} {
+				if err := tx.Migrator().AddColumn(table, "DeletedAt"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(table, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			// 回收站中的数据无法在旧结构中表示，先彻底删除，子表在前
+			for _, table := range []string{"usage_records", "blades", "razors"} {
+				if err := tx.Exec("DELETE FROM " + table + " WHERE deleted_at IS NOT NULL").Error; err != nil {
+					return err
+				}
+			}
+			for _, table := range []string{"usage_records", "blades", "razors"} {
+				// SQLite不能删除带索引的列，先删索引
+				if err := tx.Exec("DROP INDEX idx_" + table + "_deleted_at").Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("ALTER TABLE " + table + " DROP COLUMN deleted_at").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
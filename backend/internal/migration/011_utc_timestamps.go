@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 011 把SQLite中已存储的时间统一改写为UTC
+// SQLite驱动把时间连同时区偏移按文本存储，范围过滤、排序和游标都按文本比较，
+// 早期按本地时区（如+08:00）写入的行与新写入的UTC行混在一起会比较出错。
+// PostgreSQL的timestamptz按时刻比较，无需处理
+
+// sqliteTimeFormats 与mattn/go-sqlite3的解析格式一致，第一个为写入格式
+var sqliteTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseSQLiteTime 按驱动的规则解析，不带偏移的值视为UTC
+func parseSQLiteTime(s string) (time.Time, bool) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "Z")
+	for _, layout := range sqliteTimeFormats {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// normalizeSQLiteTimes 改写表中所有datetime列，无法解析的值保持原样
+func normalizeSQLiteTimes(tx *gorm.DB) error {
+	tables, err := tx.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		columns, err := tx.Migrator().ColumnTypes(table)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			if !strings.EqualFold(column.DatabaseTypeName(), "datetime") {
+				continue
+			}
+			if err := normalizeSQLiteColumn(tx, table, column.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeSQLiteColumn(tx *gorm.DB, table, column string) error {
+	var rows []struct {
+		RowID int64
+		Value string
+	}
+	query := "SELECT rowid AS row_id, CAST(" + column + " AS TEXT) AS value FROM " + table + " WHERE " + column + " IS NOT NULL"
+	if err := tx.Raw(query).Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		t, ok := parseSQLiteTime(row.Value)
+		if !ok {
+			continue
+		}
+		value := t.UTC().Format(sqliteTimeFormats[0])
+		if value == row.Value {
+			continue
+		}
+		if err := tx.Exec("UPDATE "+table+" SET "+column+" = ? WHERE rowid = ?", value, row.RowID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "utc_timestamps",
+		Up: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "sqlite" {
+				return nil
+			}
+			return normalizeSQLiteTimes(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			// 改写后表示的时刻不变，旧版本按时刻读取，无需还原
+			return nil
+		},
+	})
+}
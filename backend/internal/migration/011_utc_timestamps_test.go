@@ -0,0 +1,80 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUTCTimestampsNormalizesMixedOffsets(t *testing.T) {
+	db := openTestDB(t)
+	upTo(t, db, 10)
+
+	for _, stmt := range []string{
+		"INSERT INTO users (id, username, password_hash) VALUES (1, 'alice', 'x')",
+		"INSERT INTO razors (id, user_id, brand, model) VALUES (1, 1, 'Merkur', '34C')",
+		"INSERT INTO blades (id, user_id, brand, model) VALUES (1, 1, 'Astra', 'SP')",
+		// 早期按+08:00写入，按文本排在后面两行之后，实际时刻最早（00:00Z）
+		"INSERT INTO usage_records (id, user_id, razor_id, blade_id, usage_time) VALUES (1, 1, 1, 1, '2024-03-01 08:00:00+08:00')",
+		// 07:00Z
+		"INSERT INTO usage_records (id, user_id, razor_id, blade_id, usage_time) VALUES (2, 1, 1, 1, '2024-03-01 15:00:00+08:00')",
+		"INSERT INTO usage_records (id, user_id, razor_id, blade_id, usage_time) VALUES (3, 1, 1, 1, '2024-03-01 07:30:00+00:00')",
+		"INSERT INTO usage_records (id, user_id, razor_id, blade_id, usage_time) VALUES (4, 1, 1, 1, '2024-03-01 06:45:00Z')",
+		"INSERT INTO purchases (id, user_id, blade_id, purchase_date, quantity) VALUES (1, 1, 1, '2024-03-01 02:00:00+08:00', 5)",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := New(db).Up(); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+
+	var times []string
+	if err := db.Raw("SELECT CAST(usage_time AS TEXT) FROM usage_records ORDER BY id").Scan(&times).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"2024-03-01 00:00:00+00:00",
+		"2024-03-01 07:00:00+00:00",
+		"2024-03-01 07:30:00+00:00",
+		"2024-03-01 06:45:00+00:00",
+	}
+	if !reflect.DeepEqual(times, want) {
+		t.Errorf("usage_time = %v, want %v", times, want)
+	}
+
+	// 排序和范围过滤按文本比较，改写后与时刻顺序一致
+	var ordered []uint
+	if err := db.Raw("SELECT id FROM usage_records ORDER BY usage_time").Scan(&ordered).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ordered, []uint{1, 4, 2, 3}) {
+		t.Errorf("ORDER BY usage_time = %v, want [1 4 2 3]", ordered)
+	}
+	var inRange []uint
+	if err := db.Raw("SELECT id FROM usage_records WHERE usage_time >= ? AND usage_time < ? ORDER BY id",
+		"2024-03-01 06:00:00+00:00", "2024-03-01 07:15:00+00:00").Scan(&inRange).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(inRange, []uint{2, 4}) {
+		t.Errorf("records in [06:00Z, 07:15Z) = %v, want [2 4]", inRange)
+	}
+
+	var purchaseDate string
+	if err := db.Raw("SELECT CAST(purchase_date AS TEXT) FROM purchases WHERE id = 1").Scan(&purchaseDate).Error; err != nil {
+		t.Fatal(err)
+	}
+	if purchaseDate != "2024-02-29 18:00:00+00:00" {
+		t.Errorf("purchase_date = %q, want 2024-02-29 18:00:00+00:00", purchaseDate)
+	}
+
+	// 空值保持为空
+	var trashed int64
+	if err := db.Raw("SELECT COUNT(*) FROM usage_records WHERE deleted_at IS NOT NULL").Scan(&trashed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if trashed != 0 {
+		t.Errorf("%d records got a deleted_at", trashed)
+	}
+}
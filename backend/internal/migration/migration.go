@@ -0,0 +1,221 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSchemaTooNew 数据库结构版本高于当前程序已知的最新版本
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// Migration 一个带版本号的数据库迁移，Up和Down都在事务内执行
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// schemaMigration schema_migrations表中的一行
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// registry 所有已知迁移，由各迁移文件在init中注册
+var registry []Migration
+
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 使用已注册的迁移创建执行器
+func New(db *gorm.DB) *Migrator {
+	migrations := make([]Migration, len(registry))
+	copy(migrations, registry)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// LatestVersion 当前程序已知的最新迁移版本
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion 数据库中已应用的最高版本，未执行过迁移时为0
+func (m *Migrator) CurrentVersion() (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+	var version int
+	err := m.db.Model(&schemaMigration{}).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
+
+// CheckVersion 拒绝在比程序更新的数据库结构上运行
+func (m *Migrator) CheckVersion() error {
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if current > m.LatestVersion() {
+		return fmt.Errorf("%w: database at version %d, binary knows up to %d",
+			ErrSchemaTooNew, current, m.LatestVersion())
+	}
+	return nil
+}
+
+// Up 依次执行所有未应用的迁移，返回本次应用的迁移
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.CheckVersion(); err != nil {
+		return nil, err
+	}
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:   mig.Version,
+				Name:      mig.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d (%s) failed: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down 回滚最近应用的一个迁移，没有可回滚的迁移时返回nil
+func (m *Migrator) Down() (*Migration, error) {
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, nil
+	}
+
+	var target *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == current {
+			target = &m.migrations[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: no migration %d known", ErrSchemaTooNew, current)
+	}
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := target.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, target.Version).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rollback %d (%s) failed: %w", target.Version, target.Name, err)
+	}
+	return target, nil
+}
+
+// Status 返回每个已知迁移以及数据库中未知迁移的应用状态
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	known := make(map[int]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			st.Applied = true
+			appliedAt := row.AppliedAt
+			st.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, st)
+	}
+	for version, row := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, Status{
+			Version:   version,
+			Name:      row.Name + " (unknown to this binary)",
+			Applied:   true,
+			AppliedAt: &appliedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+func (m *Migrator) appliedVersions() (map[int]schemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	if m.db.Migrator().HasTable(&schemaMigration{}) {
+		return nil
+	}
+	return m.db.Migrator().CreateTable(&schemaMigration{})
+}
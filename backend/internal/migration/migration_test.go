@@ -0,0 +1,141 @@
+package migration
+
+import (
+	"errors"
+	"path/filepath"
+	"razor-blade/internal/config"
+	"razor-blade/pkg/database"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB 在临时目录中打开一个空的SQLite数据库，连接参数与服务相同
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.InitDB(&config.DatabaseConfig{
+		Driver: "sqlite",
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.Logger = db.Logger.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// upTo 只执行版本不超过version的迁移，用于在旧结构上准备数据
+func upTo(t *testing.T, db *gorm.DB, version int) {
+	t.Helper()
+	m := New(db)
+	var migrations []Migration
+	for _, mig := range m.migrations {
+		if mig.Version <= version {
+			migrations = append(migrations, mig)
+		}
+	}
+	m.migrations = migrations
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("migrate to %d: %v", version, err)
+	}
+}
+
+func TestUpDownUp(t *testing.T) {
+	db := openTestDB(t)
+	m := New(db)
+
+	applied, err := m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(m.migrations) {
+		t.Errorf("applied %d migrations, want %d", len(applied), len(m.migrations))
+	}
+	latest := m.LatestVersion()
+	if current, err := m.CurrentVersion(); err != nil || current != latest {
+		t.Fatalf("version after up = %d, %v, want %d", current, err, latest)
+	}
+	if applied, err := m.Up(); err != nil || len(applied) != 0 {
+		t.Errorf("second up applied %d migrations, %v", len(applied), err)
+	}
+
+	rolledBack, err := m.Down()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack == nil || rolledBack.Version != latest {
+		t.Fatalf("rolled back %+v, want version %d", rolledBack, latest)
+	}
+	if current, _ := m.CurrentVersion(); current != latest-1 {
+		t.Errorf("version after down = %d, want %d", current, latest-1)
+	}
+
+	applied, err = m.Up()
+	if err != nil {
+		t.Fatalf("up after down: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != latest {
+		t.Errorf("re-applied %+v, want only version %d", applied, latest)
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range statuses {
+		if !st.Applied {
+			t.Errorf("migration %d (%s) not applied", st.Version, st.Name)
+		}
+	}
+}
+
+func TestDownEveryVersion(t *testing.T) {
+	db := openTestDB(t)
+	m := New(db)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	// 逐个回滚到空库再全部重新执行，每个Down都能与对应的Up配合
+	for version := m.LatestVersion(); version > 0; version-- {
+		rolledBack, err := m.Down()
+		if err != nil {
+			t.Fatalf("down from %d: %v", version, err)
+		}
+		if rolledBack == nil || rolledBack.Version != version {
+			t.Fatalf("rolled back %+v, want version %d", rolledBack, version)
+		}
+	}
+	if rolledBack, err := m.Down(); rolledBack != nil || err != nil {
+		t.Errorf("down on empty schema = %+v, %v", rolledBack, err)
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("up after full rollback: %v", err)
+	}
+}
+
+func TestCheckVersionRejectsNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+	m := New(db)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	future := schemaMigration{Version: m.LatestVersion() + 1, Name: "from_a_newer_binary"}
+	if err := db.Create(&future).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.CheckVersion(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("CheckVersion = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Up(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Up = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Down(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Down = %v, want ErrSchemaTooNew", err)
+	}
+}
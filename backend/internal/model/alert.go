@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// 库存告警级别
+const (
+	AlertLevelLowStock   = "low_stock"
+	AlertLevelOutOfStock = "out_of_stock"
+)
+
+// Alert 库存告警。同一刀片同时最多只有一条未解除的告警，
+// 库存恢复到阈值以上或级别变化时解除
+type Alert struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"-" gorm:"not null;default:0;index"` // 所属用户，与刀片一致
+	BladeID           uint       `json:"blade_id" gorm:"not null;index"`
+	Level             string     `json:"level" gorm:"not null"`
+	RemainingQuantity int        `json:"remaining_quantity"`
+	Threshold         int        `json:"threshold"`
+	Message           string     `json:"message"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ResolvedAt        *time.Time `json:"resolved_at"`
+}
+
+// AlertListRequest 告警历史查询参数
+type AlertListRequest struct {
+	PaginationRequest
+	BladeID uint  `form:"blade_id"`
+	Open    *bool `form:"open"` // true只看未解除的，false只看已解除的
+}
+
+// AlertFilter 告警查询条件
+type AlertFilter struct {
+	BladeID uint
+	Open    *bool
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// 审计事件涉及的实体类型
+const (
+	AuditEntityRazor       = "razor"
+	AuditEntityBlade       = "blade"
+	AuditEntityUsageRecord = "usage_record"
+	AuditEntityPurchase    = "purchase"
+)
+
+// 审计事件的操作类型
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore" // 从回收站恢复
+)
+
+// AuditActor 发起修改的用户、使用的API令牌和请求ID，随存储一起传递到写入审计事件的事务中
+type AuditActor struct {
+	UserID    uint
+	TokenID   *uint
+	RequestID string
+}
+
+// AuditEvent 审计事件，只追加不修改，与对应的修改在同一事务内写入。
+// 新增和恢复时Before为空、After为完整内容；删除时Before为完整内容、After为空；
+// 更新时两者只包含有变化的字段
+type AuditEvent struct {
+	ID           uint                   `json:"id" gorm:"primaryKey"`
+	UserID       uint                   `json:"-" gorm:"not null;default:0;index"` // 所属用户，与实体一致
+	ActorUserID  uint                   `json:"actor_user_id"`                     // 为0表示系统操作
+	ActorTokenID *uint                  `json:"actor_token_id"`                    // 通过API令牌修改时非空
+	RequestID    string                 `json:"request_id"`
+	EntityType   string                 `json:"entity_type" gorm:"not null;index:idx_audit_events_entity"`
+	EntityID     uint                   `json:"entity_id" gorm:"not null;index:idx_audit_events_entity"`
+	Action       string                 `json:"action" gorm:"not null"`
+	Before       map[string]interface{} `json:"before" gorm:"serializer:json"`
+	After        map[string]interface{} `json:"after" gorm:"serializer:json"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// AuditListRequest 审计日志查询参数
+type AuditListRequest struct {
+	PaginationRequest
+	Entity string `form:"entity" binding:"omitempty,oneof=razor blade usage_record purchase"`
+	ID     uint   `form:"id"`
+	Action string `form:"action" binding:"omitempty,oneof=create update delete restore"`
+	From   string `form:"from"` // RFC3339或YYYY-MM-DD，包含
+	To     string `form:"to"`   // RFC3339或YYYY-MM-DD，不包含
+}
+
+// AuditFilter 审计事件查询条件
+type AuditFilter struct {
+	EntityType string
+	EntityID   uint
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
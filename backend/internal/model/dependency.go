@@ -0,0 +1,26 @@
+package model
+
+// DeleteRequest 删除剃须刀或刀片的参数，仍有使用记录等依赖时必须指定处理方式
+type DeleteRequest struct {
+	Cascade    bool `form:"cascade"`     // 使用记录一起移入回收站
+	ReassignTo uint `form:"reassign_to"` // 使用记录转移到该剃须刀或刀片后再删除
+}
+
+// DeleteOptions 删除时依赖的处理方式，都为空时仍有依赖则拒绝删除
+type DeleteOptions struct {
+	Cascade    bool
+	ReassignTo uint
+}
+
+// DependencyReport 仍引用待删除剃须刀或刀片的数据
+type DependencyReport struct {
+	Entity             string `json:"entity"` // razor, blade
+	ID                 uint   `json:"id"`
+	UsageRecordIDs     []uint `json:"usage_record_ids"`
+	CompatibleBladeIDs []uint `json:"compatible_blade_ids,omitempty"` // 声明兼容该剃须刀的刀片，只用于剃须刀
+}
+
+// HasDependents 是否存在依赖
+func (r *DependencyReport) HasDependents() bool {
+	return len(r.UsageRecordIDs) > 0 || len(r.CompatibleBladeIDs) > 0
+}
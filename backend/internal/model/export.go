@@ -0,0 +1,10 @@
+package model
+
+// ExportRequest 导出参数
+type ExportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json ndjson"` // 默认json
+	// 逗号分隔的 razors、blades、usage_records，为空时导出全部
+	Entities string `form:"entities"`
+	From     string `form:"from"` // RFC3339或YYYY-MM-DD，包含，只过滤使用记录
+	To       string `form:"to"`   // RFC3339或YYYY-MM-DD，不包含，只过滤使用记录
+}
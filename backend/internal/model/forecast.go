@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// BladeForecastRequest 补货预测查询参数，为空时使用配置中的默认值
+type BladeForecastRequest struct {
+	WindowDays   *int `form:"window_days" binding:"omitempty,min=7,max=730"`
+	LeadTimeDays *int `form:"lead_time_days" binding:"omitempty,min=0,max=365"`
+}
+
+// BladeForecast 单个刀片型号的消耗预测
+type BladeForecast struct {
+	BladeID           uint    `json:"blade_id"`
+	Brand             string  `json:"brand"`
+	Model             string  `json:"model"`
+	RemainingQuantity int     `json:"remaining_quantity"`
+	ChangesInWindow   int     `json:"changes_in_window"`
+	DailyRate         float64 `json:"daily_rate"` // 平均每天消耗的刀片数
+	// 以下时间在近期没有换刀记录时为null
+	RunOutDate     *time.Time `json:"run_out_date"`
+	RunOutEarliest *time.Time `json:"run_out_earliest"` // 置信区间下界
+	RunOutLatest   *time.Time `json:"run_out_latest"`   // 置信区间上界，消耗速度可能为0时为null
+	ReorderDate    *time.Time `json:"reorder_date"`     // 按最早耗尽时间减去到货周期
+	ReorderNow     bool       `json:"reorder_now"`
+}
+
+// BladeForecastReport 补货预测结果，按预计耗尽时间升序排列
+type BladeForecastReport struct {
+	GeneratedAt     time.Time       `json:"generated_at"`
+	WindowDays      int             `json:"window_days"`
+	LeadTimeDays    int             `json:"lead_time_days"`
+	ConfidenceLevel float64         `json:"confidence_level"`
+	Items           []BladeForecast `json:"items"`
+}
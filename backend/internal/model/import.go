@@ -0,0 +1,33 @@
+package model
+
+// ImportRequest 导入参数，文件内容为请求体，格式与导出相同
+type ImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json ndjson"` // 默认json，csv可以是单个文件或导出的zip
+	// 导入单个CSV文件时指定其中的实体
+	Entity string `form:"entity" binding:"omitempty,oneof=razors blades usage_records"`
+	DryRun bool   `form:"dry_run"` // 只校验并报告结果，不写入
+}
+
+// ImportRowError 导入文件中某一行的错误
+type ImportRowError struct {
+	Entity string `json:"entity"`
+	Line   int    `json:"line"` // CSV和NDJSON为行号，JSON为数组中的序号
+	Error  string `json:"error"`
+}
+
+// ImportEntityResult 一种实体的导入结果
+type ImportEntityResult struct {
+	Created int `json:"created"`
+	Matched int `json:"matched"` // 按品牌和型号匹配到已有数据，不新建
+	// 文件中的ID到导入后ID的映射，文件中没有ID的行不出现
+	IDMap map[uint]uint `json:"id_map"`
+}
+
+// ImportResult 导入结果，Errors非空时没有写入任何数据
+type ImportResult struct {
+	DryRun       bool               `json:"dry_run"`
+	Razors       ImportEntityResult `json:"razors"`
+	Blades       ImportEntityResult `json:"blades"`
+	UsageRecords ImportEntityResult `json:"usage_records"`
+	Errors       []ImportRowError   `json:"errors"`
+}
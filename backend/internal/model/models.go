@@ -2,121 +2,178 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Razor 剃须刀模型
 type Razor struct {
-	ID           uint       `json:"id" gorm:"primaryKey"`
-	Brand        string     `json:"brand" gorm:"not null"`
-	Model        string     `json:"model" gorm:"not null"`
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"-" gorm:"not null;default:0;index"` // 所属用户
+	Brand  string `json:"brand" gorm:"not null"`
+	Model  string `json:"model" gorm:"not null"`
+	// 购买日期、价格和币种取自最近一次购买记录，由购买记录维护
 	PurchaseDate *time.Time `json:"purchase_date"`
 	Price        *float64   `json:"price"`
+	Currency     string     `json:"currency" gorm:"not null;default:''"` // 为空表示基础货币
 	Notes        string     `json:"notes"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	// 非空表示已移入回收站，正常查询和统计都不包含
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	// 关联关系
 	UsageRecords []UsageRecord `json:"usage_records,omitempty" gorm:"foreignKey:RazorID"`
+	// 创建时随剃须刀一起写入的购买记录
+	Purchases []Purchase `json:"purchases,omitempty" gorm:"foreignKey:RazorID"`
 }
 
 // Blade 刀片模型
 type Blade struct {
-	ID                uint       `json:"id" gorm:"primaryKey"`
-	Brand             string     `json:"brand" gorm:"not null"`
-	Model             string     `json:"model" gorm:"not null"`
-	CompatibleRazors  string     `json:"compatible_razors"` // JSON格式存储兼容的剃须刀ID列表
-	PurchaseDate      *time.Time `json:"purchase_date"`
-	UnitPrice         *float64   `json:"unit_price"`
-	TotalQuantity     int        `json:"total_quantity" gorm:"default:0"`
-	RemainingQuantity int        `json:"remaining_quantity" gorm:"default:0"`
-	Notes             string     `json:"notes"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"-" gorm:"not null;default:0;index"` // 所属用户
+	Brand  string `json:"brand" gorm:"not null"`
+	Model  string `json:"model" gorm:"not null"`
+	// 购买日期、单价和币种取自最近一次购买记录，由购买记录维护
+	PurchaseDate *time.Time `json:"purchase_date"`
+	UnitPrice    *float64   `json:"unit_price"`
+	Currency     string     `json:"currency" gorm:"not null;default:''"` // 为空表示基础货币
+	// 总数量为全部购买记录的数量之和，剩余数量 = 总数量 - 换刀消耗
+	TotalQuantity     int       `json:"total_quantity" gorm:"default:0"`
+	RemainingQuantity int       `json:"remaining_quantity" gorm:"default:0"`
+	LowStockThreshold *int      `json:"low_stock_threshold"` // 为空时使用全局配置
+	Notes             string    `json:"notes"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	// 移入回收站的时间，与Razor.DeletedAt相同
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// 兼容的剃须刀ID列表，存储在razor_blade_compatibility关联表中
+	CompatibleRazorIDs []uint `json:"compatible_razor_ids" gorm:"-"`
 
 	// 关联关系
 	UsageRecords []UsageRecord `json:"usage_records,omitempty" gorm:"foreignKey:BladeID"`
+	// 创建时随刀片一起写入的购买记录
+	Purchases []Purchase `json:"purchases,omitempty" gorm:"foreignKey:BladeID"`
+}
+
+// EffectiveLowStockThreshold 返回刀片生效的库存告警阈值，未单独设置时使用fallback
+func (b *Blade) EffectiveLowStockThreshold(fallback int) int {
+	if b.LowStockThreshold != nil {
+		return *b.LowStockThreshold
+	}
+	return fallback
+}
+
+// RazorBladeCompatibility 剃须刀与刀片的兼容关系
+type RazorBladeCompatibility struct {
+	RazorID uint `json:"razor_id" gorm:"primaryKey;autoIncrement:false"`
+	BladeID uint `json:"blade_id" gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (RazorBladeCompatibility) TableName() string {
+	return "razor_blade_compatibility"
 }
 
 // UsageRecord 使用记录模型
 type UsageRecord struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
+	UserID          uint      `json:"-" gorm:"not null;default:0;index"` // 所属用户
 	UsageTime       time.Time `json:"usage_time" gorm:"not null"`
 	RazorID         uint      `json:"razor_id" gorm:"not null"`
 	BladeID         uint      `json:"blade_id" gorm:"not null"`
-	BladeUsageCount int       `json:"blade_usage_count" gorm:"default:1"`
-	Rating          *int      `json:"rating"` // 1-5评分
-	ExperienceText  string    `json:"experience_text"`
-	NeedBladeChange bool      `json:"need_blade_change" gorm:"default:false"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	BladeUsageCount int       `json:"blade_usage_count" gorm:"default:1"` // 当前刀片的第几次使用，由服务端计算
+	// 手动指定的使用次数，非空时覆盖自动计算，之后的记录从该值继续累加
+	BladeUsageCountOverride *int      `json:"blade_usage_count_override"`
+	Rating                  *int      `json:"rating"` // 1-5评分
+	ExperienceText          string    `json:"experience_text"`
+	NeedBladeChange         bool      `json:"need_blade_change" gorm:"default:false"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+	// 随剃须刀或刀片一起删除时与其删除时间相同，恢复时据此一并恢复
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	// 关联关系
 	Razor Razor `json:"razor" gorm:"foreignKey:RazorID"`
 	Blade Blade `json:"blade" gorm:"foreignKey:BladeID"`
+
+	// 创建或更新时产生的提示信息，例如剃须刀与刀片未声明兼容
+	Warnings []string `json:"warnings,omitempty" gorm:"-"`
+}
+
+// MountedBlade 剃须刀当前装着的刀片，由使用记录推算
+type MountedBlade struct {
+	RazorID uint   `json:"razor_id"`
+	Blade   *Blade `json:"blade"` // 没有使用记录时为null
+	// 当前刀片已使用的次数，上一条记录标记换刀时为0（已换上同型号新刀片）
+	ShaveCount     int        `json:"shave_count"`
+	NextShaveIndex int        `json:"next_shave_index"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
 }
 
-// CreateRazorRequest 创建剃须刀请求
+// CreateRazorRequest 创建剃须刀请求，填写了购买日期或价格时同时创建一条购买记录
 type CreateRazorRequest struct {
 	Brand        string     `json:"brand" binding:"required"`
 	Model        string     `json:"model" binding:"required"`
 	PurchaseDate *time.Time `json:"purchase_date"`
-	Price        *float64   `json:"price"`
+	Price        *float64   `json:"price" binding:"omitempty,min=0"`
+	Currency     string     `json:"currency" binding:"omitempty,len=3,alpha"`
 	Notes        string     `json:"notes"`
 }
 
-// UpdateRazorRequest 更新剃须刀请求
+// UpdateRazorRequest 更新剃须刀请求，购买信息通过购买记录修改
 type UpdateRazorRequest struct {
-	Brand        string     `json:"brand"`
-	Model        string     `json:"model"`
-	PurchaseDate *time.Time `json:"purchase_date"`
-	Price        *float64   `json:"price"`
-	Notes        string     `json:"notes"`
+	Brand string `json:"brand"`
+	Model string `json:"model"`
+	Notes string `json:"notes"`
 }
 
 // CreateBladeRequest 创建刀片请求
 type CreateBladeRequest struct {
-	Brand             string     `json:"brand" binding:"required"`
-	Model             string     `json:"model" binding:"required"`
-	CompatibleRazors  string     `json:"compatible_razors"`
+	Brand              string `json:"brand" binding:"required"`
+	Model              string `json:"model" binding:"required"`
+	CompatibleRazorIDs []uint `json:"compatible_razor_ids"`
+	// 数量大于0时同时创建一条购买记录
 	PurchaseDate      *time.Time `json:"purchase_date"`
-	UnitPrice         *float64   `json:"unit_price"`
-	TotalQuantity     int        `json:"total_quantity"`
-	RemainingQuantity int        `json:"remaining_quantity"`
+	UnitPrice         *float64   `json:"unit_price" binding:"omitempty,min=0"`
+	Currency          string     `json:"currency" binding:"omitempty,len=3,alpha"`
+	TotalQuantity     int        `json:"total_quantity" binding:"omitempty,min=0"`
+	LowStockThreshold *int       `json:"low_stock_threshold" binding:"omitempty,min=0"`
 	Notes             string     `json:"notes"`
 }
 
 // UpdateBladeRequest 更新刀片请求
 type UpdateBladeRequest struct {
-	Brand             string     `json:"brand"`
-	Model             string     `json:"model"`
-	CompatibleRazors  string     `json:"compatible_razors"`
-	PurchaseDate      *time.Time `json:"purchase_date"`
-	UnitPrice         *float64   `json:"unit_price"`
-	TotalQuantity     int        `json:"total_quantity"`
-	RemainingQuantity int        `json:"remaining_quantity"`
-	Notes             string     `json:"notes"`
+	Brand                  string `json:"brand"`
+	Model                  string `json:"model"`
+	CompatibleRazorIDs     []uint `json:"compatible_razor_ids"`                          // 省略时保持不变，传空数组清空
+	LowStockThreshold      *int   `json:"low_stock_threshold" binding:"omitempty,min=0"` // 省略时保持不变
+	ClearLowStockThreshold bool   `json:"clear_low_stock_threshold"`                     // 清除单独设置的阈值，改用全局配置
+	Notes                  string `json:"notes"`
 }
 
 // CreateUsageRecordRequest 创建使用记录请求
 type CreateUsageRecordRequest struct {
-	UsageTime       time.Time `json:"usage_time" binding:"required"`
-	RazorID         uint      `json:"razor_id" binding:"required"`
-	BladeID         uint      `json:"blade_id" binding:"required"`
-	BladeUsageCount int       `json:"blade_usage_count"`
-	Rating          *int      `json:"rating"`
-	ExperienceText  string    `json:"experience_text"`
-	NeedBladeChange bool      `json:"need_blade_change"`
+	UsageTime time.Time `json:"usage_time" binding:"required"`
+	RazorID   uint      `json:"razor_id" binding:"required"`
+	BladeID   uint      `json:"blade_id" binding:"required"`
+	// 为空时根据该剃须刀上一次换刀后的使用记录自动计算
+	BladeUsageCountOverride *int   `json:"blade_usage_count_override" binding:"omitempty,min=1"`
+	Rating                  *int   `json:"rating"`
+	ExperienceText          string `json:"experience_text"`
+	NeedBladeChange         bool   `json:"need_blade_change"`
 }
 
-// UpdateUsageRecordRequest 更新使用记录请求
+// UpdateUsageRecordRequest 更新使用记录请求，整体替换原记录，必填字段与创建时相同
 type UpdateUsageRecordRequest struct {
-	UsageTime       time.Time `json:"usage_time"`
-	RazorID         uint      `json:"razor_id"`
-	BladeID         uint      `json:"blade_id"`
-	BladeUsageCount int       `json:"blade_usage_count"`
-	Rating          *int      `json:"rating"`
-	ExperienceText  string    `json:"experience_text"`
-	NeedBladeChange bool      `json:"need_blade_change"`
+	UsageTime time.Time `json:"usage_time" binding:"required"`
+	RazorID   uint      `json:"razor_id" binding:"required"`
+	BladeID   uint      `json:"blade_id" binding:"required"`
+	// 为空时根据该剃须刀上一次换刀后的使用记录自动计算
+	BladeUsageCountOverride *int   `json:"blade_usage_count_override" binding:"omitempty,min=1"`
+	Rating                  *int   `json:"rating"`
+	ExperienceText          string `json:"experience_text"`
+	NeedBladeChange         bool   `json:"need_blade_change"`
 }
 
 // APIResponse API响应格式
@@ -129,8 +186,8 @@ type APIResponse struct {
 
 // PaginationRequest 分页请求
 type PaginationRequest struct {
-	Page     int `form:"page" binding:"min=1"`
-	PageSize int `form:"page_size" binding:"min=1,max=100"`
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
 // PaginationResponse 分页响应
@@ -140,4 +197,7 @@ type PaginationResponse struct {
 	PageSize   int         `json:"page_size"`
 	Total      int64       `json:"total"`
 	TotalPages int         `json:"total_pages"`
-}
\ No newline at end of file
+	// 游标分页模式下返回，页码与总数不计算
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// 购买对象类型
+const (
+	PurchaseItemRazor = "razor"
+	PurchaseItemBlade = "blade"
+)
+
+// Purchase 一次购买记录，RazorID和BladeID有且只有一个非空。
+// 刀片的总数量由全部购买记录的数量累加得到
+type Purchase struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"-" gorm:"not null;default:0;index"` // 所属用户
+	RazorID      *uint     `json:"razor_id" gorm:"index"`
+	BladeID      *uint     `json:"blade_id" gorm:"index"`
+	PurchaseDate time.Time `json:"purchase_date" gorm:"not null"`
+	Quantity     int       `json:"quantity" gorm:"not null"`
+	UnitPrice    *float64  `json:"unit_price"`
+	Currency     string    `json:"currency" gorm:"not null;default:''"` // 为空表示基础货币
+	Vendor       string    `json:"vendor"`
+	Notes        string    `json:"notes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ItemType 返回购买对象类型
+func (p *Purchase) ItemType() string {
+	if p.RazorID != nil {
+		return PurchaseItemRazor
+	}
+	return PurchaseItemBlade
+}
+
+// CreatePurchaseRequest 创建购买记录请求，razor_id和blade_id必须且只能填写一个
+type CreatePurchaseRequest struct {
+	RazorID      *uint      `json:"razor_id"`
+	BladeID      *uint      `json:"blade_id"`
+	PurchaseDate *time.Time `json:"purchase_date"` // 为空时使用当前时间
+	Quantity     int        `json:"quantity" binding:"required,min=1"`
+	UnitPrice    *float64   `json:"unit_price" binding:"omitempty,min=0"`
+	Currency     string     `json:"currency" binding:"omitempty,len=3,alpha"`
+	Vendor       string     `json:"vendor"`
+	Notes        string     `json:"notes"`
+}
+
+// UpdatePurchaseRequest 更新购买记录请求，购买对象不能修改
+type UpdatePurchaseRequest struct {
+	PurchaseDate *time.Time `json:"purchase_date"`
+	Quantity     int        `json:"quantity" binding:"omitempty,min=1"`
+	UnitPrice    *float64   `json:"unit_price" binding:"omitempty,min=0"`
+	Currency     string     `json:"currency" binding:"omitempty,len=3,alpha"`
+	Vendor       string     `json:"vendor"`
+	Notes        string     `json:"notes"`
+}
+
+// PurchaseListRequest 购买记录列表查询参数
+type PurchaseListRequest struct {
+	PaginationRequest
+	RazorID  uint   `form:"razor_id"`
+	BladeID  uint   `form:"blade_id"`
+	ItemType string `form:"item_type" binding:"omitempty,oneof=razor blade"`
+	Vendor   string `form:"vendor"`
+	From     string `form:"from"` // RFC3339或YYYY-MM-DD，包含
+	To       string `form:"to"`   // RFC3339或YYYY-MM-DD，不包含
+	Sort     string `form:"sort"`
+}
+
+// PurchaseFilter 购买记录查询条件
+type PurchaseFilter struct {
+	RazorID  uint
+	BladeID  uint
+	ItemType string
+	Vendor   string
+	From     *time.Time
+	To       *time.Time
+	Sort     []SortField
+}
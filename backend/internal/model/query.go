@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// RazorListRequest 剃须刀列表查询参数
+type RazorListRequest struct {
+	PaginationRequest
+	Brand string `form:"brand"`
+	Model string `form:"model"`
+	Q     string `form:"q"`    // 搜索备注
+	Sort  string `form:"sort"` // 例如 brand:asc,created_at:desc
+}
+
+// BladeListRequest 刀片列表查询参数
+type BladeListRequest struct {
+	PaginationRequest
+	Brand    string `form:"brand"`
+	Model    string `form:"model"`
+	Q        string `form:"q"` // 搜索备注
+	LowStock bool   `form:"low_stock"`
+	Sort     string `form:"sort"`
+}
+
+// UsageRecordListRequest 使用记录列表查询参数
+type UsageRecordListRequest struct {
+	PaginationRequest
+	RazorID   uint   `form:"razor_id"`
+	BladeID   uint   `form:"blade_id"`
+	Q         string `form:"q"` // 搜索使用感受
+	RatingMin *int   `form:"rating_min" binding:"omitempty,min=1,max=5"`
+	RatingMax *int   `form:"rating_max" binding:"omitempty,min=1,max=5"`
+	From      string `form:"from"` // RFC3339或YYYY-MM-DD，包含
+	To        string `form:"to"`   // RFC3339或YYYY-MM-DD，不包含
+	Sort      string `form:"sort"`
+	// 游标分页：传入cursor或limit时启用，首页cursor留空
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// SortField 排序字段，Field为已通过白名单校验的列名
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// RazorFilter 剃须刀查询条件，字段为空表示不过滤
+type RazorFilter struct {
+	Brand string
+	Model string
+	Query string
+	Sort  []SortField
+}
+
+// BladeFilter 刀片查询条件
+type BladeFilter struct {
+	Brand string
+	Model string
+	Query string
+	// 非空时只返回剩余数量不超过阈值的刀片，刀片单独设置的阈值优先于该值
+	LowStockThreshold *int
+	Sort              []SortField
+}
+
+// UsageRecordFilter 使用记录查询条件
+type UsageRecordFilter struct {
+	RazorID   uint
+	BladeID   uint
+	Query     string
+	RatingMin *int
+	RatingMax *int
+	From      *time.Time
+	To        *time.Time
+	Sort      []SortField
+}
+
+// UsageRecordCursor 使用记录游标分页位置，按 usage_time, id 排序
+type UsageRecordCursor struct {
+	UsageTime time.Time
+	ID        uint // 为0表示从头开始
+	Desc      bool // 排序方向，默认新记录在前
+	Backward  bool // 为true时返回位置之前的记录（上一页）
+}
@@ -0,0 +1,126 @@
+package model
+
+import "time"
+
+// 时间序列统计的分桶粒度
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// TimeSeriesRequest 时间序列统计查询参数
+type TimeSeriesRequest struct {
+	From    string `form:"from"` // RFC3339或YYYY-MM-DD，包含
+	To      string `form:"to"`   // RFC3339或YYYY-MM-DD，不包含
+	Bucket  string `form:"bucket" binding:"omitempty,oneof=day week month"`
+	RazorID uint   `form:"razor_id"`
+	BladeID uint   `form:"blade_id"`
+	TZ      string `form:"tz"` // IANA时区名，例如 Asia/Shanghai，默认服务器本地时区
+}
+
+// TimeSeriesPoint 单个时间桶的统计
+type TimeSeriesPoint struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	ShaveCount    int64     `json:"shave_count"`
+	AverageRating *float64  `json:"average_rating"` // 桶内没有评分时为null
+	BladeChanges  int64     `json:"blade_changes"`
+	Spend         float64   `json:"spend"` // 换下刀片的单价之和，按基础货币折算
+	// 按刀片统计的换刀次数，服务层据此按单价折算花费
+	ChangesByBlade map[uint]int64 `json:"-"`
+}
+
+// TimeSeries 时间序列统计结果
+type TimeSeries struct {
+	Bucket       string            `json:"bucket"`
+	Timezone     string            `json:"timezone"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	BaseCurrency string            `json:"base_currency"`
+	Points       []TimeSeriesPoint `json:"points"`
+	// 未配置汇率的币种，这些购买记录没有计入刀片单价
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
+
+// BladeLifetimeRequest 刀片寿命分析查询参数
+type BladeLifetimeRequest struct {
+	// 平均评分低于该值的首个剃须次数视为质量下降点，默认3
+	Threshold *float64 `form:"threshold" binding:"omitempty,min=1,max=5"`
+}
+
+// BladeLife 一片刀片在某把剃须刀上从装上到换下的一段使用
+type BladeLife struct {
+	RazorID       uint      `json:"razor_id"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Shaves        int       `json:"shaves"`
+	AverageRating *float64  `json:"average_rating"`
+	Completed     bool      `json:"completed"` // false表示刀片仍在使用中
+}
+
+// ShaveRating 第N次剃须的平均评分
+type ShaveRating struct {
+	ShaveIndex    int      `json:"shave_index"`
+	AverageRating *float64 `json:"average_rating"`
+	Samples       int      `json:"samples"` // 有评分的记录数
+}
+
+// BladeLifetime 刀片寿命分析结果
+type BladeLifetime struct {
+	BladeID         uint          `json:"blade_id"`
+	CompletedLives  int           `json:"completed_lives"`
+	AverageShaves   *float64      `json:"average_shaves"` // 仅统计已换下的刀片
+	MedianShaves    *float64      `json:"median_shaves"`
+	RatingThreshold float64       `json:"rating_threshold"`
+	DropShave       *int          `json:"drop_shave"` // 平均评分首次低于阈值的剃须次数，未下降为null
+	RatingByShave   []ShaveRating `json:"rating_by_shave"`
+	Lives           []BladeLife   `json:"lives"`
+}
+
+// CostRequest 花费统计查询参数
+type CostRequest struct {
+	TZ string `form:"tz"` // 按月汇总使用的IANA时区名，默认服务器本地时区
+}
+
+// MonthlySpend 某个月的购买花费，按购买记录的日期归属
+type MonthlySpend struct {
+	Month  string  `json:"month"` // YYYY-MM
+	Razors float64 `json:"razors"`
+	Blades float64 `json:"blades"`
+	Total  float64 `json:"total"`
+}
+
+// RazorCost 单把剃须刀的每次剃须成本
+type RazorCost struct {
+	RazorID uint     `json:"razor_id"`
+	Brand   string   `json:"brand"`
+	Model   string   `json:"model"`
+	Price   *float64 `json:"price"` // 全部购买记录折算后的合计，没有价格时为null
+	Shaves  int      `json:"shaves"`
+	// 在该剃须刀上用过的刀片数，仍装着的刀片也计入
+	BladesUsed int     `json:"blades_used"`
+	BladeSpend float64 `json:"blade_spend"` // 用过的刀片按平均单价计算的金额
+	// 剃须刀价格按已有的剃须次数分摊
+	RazorCostPerShave *float64 `json:"razor_cost_per_shave"`
+	// 刀片单价除以每片实际用到的次数
+	BladeCostPerShave *float64 `json:"blade_cost_per_shave"`
+	CostPerShave      *float64 `json:"cost_per_shave"` // 没有剃须记录时为null
+}
+
+// CostReport 花费统计结果，金额均为基础货币
+type CostReport struct {
+	BaseCurrency string  `json:"base_currency"`
+	TotalSpend   float64 `json:"total_spend"`
+	RazorSpend   float64 `json:"razor_spend"`
+	BladeSpend   float64 `json:"blade_spend"`
+	TotalShaves  int     `json:"total_shaves"`
+	// 全部剃须刀合计的每次剃须成本
+	RazorCostPerShave *float64       `json:"razor_cost_per_shave"`
+	BladeCostPerShave *float64       `json:"blade_cost_per_shave"`
+	CostPerShave      *float64       `json:"cost_per_shave"`
+	MonthlySpend      []MonthlySpend `json:"monthly_spend"`
+	Razors            []RazorCost    `json:"razors"` // 按每次剃须成本升序，无法计算的排在最后
+	// 未配置汇率的币种，这些购买记录没有计入金额
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// StorageInfo 当前使用的存储，在健康检查中返回
+type StorageInfo struct {
+	Mode       string `json:"mode"`             // file, memory, demo
+	Driver     string `json:"driver,omitempty"` // file模式下的数据库驱动
+	Persistent bool   `json:"persistent"`       // 重启后数据是否保留
+	ReadOnly   bool   `json:"read_only"`        // 是否拒绝修改数据
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// API令牌的权限范围。read对应查询请求，write对应新增、修改和删除，
+// admin可以管理API令牌，并包含read和write
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// APITokenPrefix API令牌的固定前缀，用于和登录令牌区分
+const APITokenPrefix = "rbt_"
+
+// APIToken 供脚本和第三方集成使用的个人API令牌，只保存令牌的SHA-256摘要。
+// 撤销后保留记录以便查看使用历史
+type APIToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"-" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"` // 令牌开头几位，便于用户辨认
+	TokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	Scopes     []string   `json:"scopes" gorm:"not null;serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"` // 为空表示不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope 判断令牌是否拥有指定权限，admin包含全部权限
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Active 判断令牌在at时是否可用
+func (t *APIToken) Active(at time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || t.ExpiresAt.After(at))
+}
+
+// API令牌审计事件类型
+const (
+	TokenEventCreated = "created"
+	TokenEventUsed    = "used"
+	TokenEventRevoked = "revoked"
+)
+
+// APITokenEvent API令牌的审计记录，令牌的创建、撤销以及每次使用各记录一条
+type APITokenEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	TokenID    uint      `json:"token_id" gorm:"not null;index"`
+	Event      string    `json:"event" gorm:"not null"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	ClientIP   string    `json:"client_ip"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateAPITokenRequest 创建API令牌请求
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=64"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=read write admin"`
+	ExpiresAt *time.Time `json:"expires_at"` // 为空表示不过期
+}
+
+// CreateAPITokenResponse 创建成功后返回明文令牌，之后无法再次查看
+type CreateAPITokenResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// APITokenEventListRequest 令牌审计记录查询参数
+type APITokenEventListRequest struct {
+	PaginationRequest
+}
@@ -0,0 +1,27 @@
+package model
+
+// 回收站中的数据类型
+const (
+	TrashEntityRazors       = "razors"
+	TrashEntityBlades       = "blades"
+	TrashEntityUsageRecords = "usage_records"
+)
+
+// Trash 回收站中的数据，按删除时间倒序
+type Trash struct {
+	Razors       []Razor       `json:"razors"`
+	Blades       []Blade       `json:"blades"`
+	UsageRecords []UsageRecord `json:"usage_records"`
+}
+
+// TrashRequest 回收站查询参数
+type TrashRequest struct {
+	Entity string `form:"entity" binding:"omitempty,oneof=razors blades usage_records"` // 为空时返回全部类型
+}
+
+// PurgeResult 一次清理彻底删除的数量
+type PurgeResult struct {
+	Razors       int64 `json:"razors"`
+	Blades       int64 `json:"blades"`
+	UsageRecords int64 `json:"usage_records"`
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"encoding/json"
+	"razor-blade/internal/model"
+	"reflect"
+	"time"
+)
+
+// auditKey 一个被审计的实体
+type auditKey struct {
+	entityType string
+	id         uint
+}
+
+// auditSnapshot 实体在某一时刻的JSON内容，实体不存在时data为nil
+type auditSnapshot struct {
+	data   map[string]interface{}
+	userID uint
+}
+
+// auditBatch 收集一次修改涉及的实体在修改前的快照，修改完成后与修改后的快照对比生成审计事件。
+// 两种实现共用，snapshot需在修改所在的事务或锁内读取
+type auditBatch struct {
+	actor    model.AuditActor
+	snapshot func(entityType string, id uint) (auditSnapshot, error)
+	keys     []auditKey
+	before   map[auditKey]auditSnapshot
+	restored map[auditKey]bool
+}
+
+func newAuditBatch(actor model.AuditActor, snapshot func(entityType string, id uint) (auditSnapshot, error)) *auditBatch {
+	return &auditBatch{
+		actor:    actor,
+		snapshot: snapshot,
+		before:   make(map[auditKey]auditSnapshot),
+		restored: make(map[auditKey]bool),
+	}
+}
+
+// watch 在修改前记录实体的快照，重复调用时保留第一次的快照
+func (b *auditBatch) watch(entityType string, ids ...uint) error {
+	for _, id := range ids {
+		key := auditKey{entityType: entityType, id: id}
+		if _, ok := b.before[key]; ok {
+			continue
+		}
+		snap, err := b.snapshot(entityType, id)
+		if err != nil {
+			return err
+		}
+		b.keys = append(b.keys, key)
+		b.before[key] = snap
+	}
+	return nil
+}
+
+// watchRestore 记录将从回收站恢复的实体，修改后重新可见的实体生成恢复事件而不是新增事件
+func (b *auditBatch) watchRestore(entityType string, ids ...uint) error {
+	for _, id := range ids {
+		b.restored[auditKey{entityType: entityType, id: id}] = true
+	}
+	return b.watch(entityType, ids...)
+}
+
+// created 记录新建的实体，修改前视为不存在
+func (b *auditBatch) created(entityType string, id uint) {
+	key := auditKey{entityType: entityType, id: id}
+	if _, ok := b.before[key]; ok {
+		return
+	}
+	b.keys = append(b.keys, key)
+	b.before[key] = auditSnapshot{}
+}
+
+// events 读取修改后的快照，按记录顺序生成审计事件，内容没有变化的实体不生成事件
+func (b *auditBatch) events(at time.Time) ([]model.AuditEvent, error) {
+	events := make([]model.AuditEvent, 0, len(b.keys))
+	for _, key := range b.keys {
+		before := b.before[key]
+		after, err := b.snapshot(key.entityType, key.id)
+		if err != nil {
+			return nil, err
+		}
+
+		event := model.AuditEvent{
+			ActorUserID:  b.actor.UserID,
+			ActorTokenID: b.actor.TokenID,
+			RequestID:    b.actor.RequestID,
+			EntityType:   key.entityType,
+			EntityID:     key.id,
+			CreatedAt:    at,
+		}
+		switch {
+		case before.data == nil && after.data == nil:
+			continue
+		case before.data == nil && b.restored[key]:
+			event.Action, event.UserID, event.After = model.AuditActionRestore, after.userID, after.data
+		case before.data == nil:
+			event.Action, event.UserID, event.After = model.AuditActionCreate, after.userID, after.data
+		case after.data == nil:
+			event.Action, event.UserID, event.Before = model.AuditActionDelete, before.userID, before.data
+		default:
+			event.Action, event.UserID = model.AuditActionUpdate, after.userID
+			event.Before, event.After = auditDiff(before.data, after.data)
+			if len(event.Before) == 0 {
+				continue
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// auditData 将实体转为JSON对象，用于保存快照
+func auditData(entity interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// usageRecordAuditData 使用记录的快照，不包含预加载的剃须刀和刀片
+func usageRecordAuditData(record *model.UsageRecord) (map[string]interface{}, error) {
+	data, err := auditData(record)
+	if err != nil {
+		return nil, err
+	}
+	delete(data, "razor")
+	delete(data, "blade")
+	return data, nil
+}
+
+// auditDiff 返回前后两个快照中有变化的字段，updated_at随每次修改变化，不计入
+func auditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for field, value := range after {
+		if field == "updated_at" {
+			continue
+		}
+		if old, ok := before[field]; !ok || !reflect.DeepEqual(old, value) {
+			changedBefore[field] = before[field]
+			changedAfter[field] = value
+		}
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok && field != "updated_at" {
+			changedBefore[field] = value
+			changedAfter[field] = nil
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// stockChangedBlades 返回修改使用记录时需要归还或扣减库存的刀片，record为nil表示删除
+func stockChangedBlades(old, record *model.UsageRecord) []uint {
+	var ids []uint
+	if old.NeedBladeChange {
+		ids = append(ids, old.BladeID)
+	}
+	if record != nil && record.NeedBladeChange {
+		ids = append(ids, record.BladeID)
+	}
+	return ids
+}
+
+// watchPurchaseItem 记录购买对象修改前的快照，购买记录的变化会刷新其购买信息和库存
+func watchPurchaseItem(audit *auditBatch, purchase *model.Purchase) error {
+	if purchase.RazorID != nil {
+		return audit.watch(model.AuditEntityRazor, *purchase.RazorID)
+	}
+	return audit.watch(model.AuditEntityBlade, *purchase.BladeID)
+}
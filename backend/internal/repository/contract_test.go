@@ -0,0 +1,557 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreContract(t *testing.T) {
+	storeContract(t, func() Store { return NewMemoryStore() })
+}
+
+func TestGormStoreContract(t *testing.T) {
+	storeContract(t, func() Store { return newSQLiteStore(t) })
+}
+
+// storeContract 对Store的实现运行相同的场景，两种实现的行为必须一致
+func storeContract(t *testing.T, newStore func() Store) {
+	t.Run("RazorCRUD", func(t *testing.T) { contractRazorCRUD(t, newStore()) })
+	t.Run("BladeCompatibility", func(t *testing.T) { contractBladeCompatibility(t, newStore()) })
+	t.Run("StockDecrementAndRestore", func(t *testing.T) { contractStock(t, newStore()) })
+	t.Run("UsageOrderingAndRenumbering", func(t *testing.T) { contractUsageOrdering(t, newStore()) })
+	t.Run("UsageCursor", func(t *testing.T) { contractUsageCursor(t, newStore()) })
+	t.Run("Audit", func(t *testing.T) { contractAudit(t, newStore()) })
+	t.Run("ForUserIsolation", func(t *testing.T) { contractForUser(t, newStore()) })
+	t.Run("TrashAndRestore", func(t *testing.T) { contractTrash(t, newStore()) })
+	t.Run("TimeSeries", func(t *testing.T) { contractTimeSeries(t, newStore()) })
+}
+
+func contractRazorCRUD(t *testing.T, store Store) {
+	razor := mustCreateRazor(t, store, "Merkur", "34C")
+	mustCreateRazor(t, store, "Edwin Jagger", "DE89")
+	if razor.ID == 0 {
+		t.Fatal("created razor has no ID")
+	}
+
+	got, err := store.GetRazorByID(razor.ID)
+	if err != nil || got.Brand != "Merkur" || got.Model != "34C" {
+		t.Fatalf("get razor: %+v, %v", got, err)
+	}
+
+	got.Notes = "daily"
+	if err := store.UpdateRazor(got); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = store.GetRazorByID(razor.ID); got.Notes != "daily" {
+		t.Errorf("notes after update = %q", got.Notes)
+	}
+
+	razors, total, err := store.GetRazors(model.RazorFilter{Brand: "MERKUR", Query: "DAI"}, 0, 10)
+	if err != nil || total != 1 || len(razors) != 1 || razors[0].ID != razor.ID {
+		t.Errorf("search razors: %d %v, %v", total, razors, err)
+	}
+	razors, total, err = store.GetRazors(model.RazorFilter{}, 1, 10)
+	if err != nil || total != 2 || len(razors) != 1 {
+		t.Errorf("second page: total=%d len=%d, %v", total, len(razors), err)
+	}
+
+	if err := store.DeleteRazor(razor.ID, model.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetRazorByID(razor.ID); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("get deleted razor: err = %v, want ErrRazorNotFound", err)
+	}
+	if err := store.UpdateRazor(got); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("update deleted razor: err = %v, want ErrRazorNotFound", err)
+	}
+	if _, total, _ := store.GetRazors(model.RazorFilter{}, 0, 10); total != 1 {
+		t.Errorf("razors after delete = %d, want 1", total)
+	}
+}
+
+func contractBladeCompatibility(t *testing.T, store Store) {
+	first := mustCreateRazor(t, store, "Merkur", "34C")
+	second := mustCreateRazor(t, store, "Gillette", "Tech")
+	blade := mustCreateBlade(t, store, "Astra", "SP", 5, second.ID, first.ID, first.ID)
+
+	if got := mustGetBlade(t, store, blade.ID); !equalIDs(got.CompatibleRazorIDs, first.ID, second.ID) {
+		t.Errorf("compatible razors = %v, want [%d %d]", got.CompatibleRazorIDs, first.ID, second.ID)
+	}
+	if got := mustGetBlade(t, store, blade.ID); got.TotalQuantity != 5 || got.RemainingQuantity != 5 {
+		t.Errorf("quantity = %d/%d, want 5/5", got.RemainingQuantity, got.TotalQuantity)
+	}
+
+	// 更新时整体替换兼容列表
+	got := mustGetBlade(t, store, blade.ID)
+	got.CompatibleRazorIDs = []uint{second.ID}
+	if err := store.UpdateBlade(got); err != nil {
+		t.Fatal(err)
+	}
+	assertCompatible(t, store, first.ID, blade.ID, false)
+	assertCompatible(t, store, second.ID, blade.ID, true)
+	blades, err := store.GetCompatibleBlades(second.ID)
+	if err != nil || len(blades) != 1 || blades[0].ID != blade.ID {
+		t.Errorf("compatible blades of %d: %v, %v", second.ID, blades, err)
+	}
+	if _, err := store.GetCompatibleBlades(999); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("compatible blades of missing razor: err = %v", err)
+	}
+
+	// 引用不存在的剃须刀
+	bad := &model.Blade{Brand: "Feather", Model: "Pro", CompatibleRazorIDs: []uint{999}}
+	if err := store.CreateBlade(bad); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("create with missing razor: err = %v, want ErrRazorNotFound", err)
+	}
+	// 兼容关系检查不涉及不存在或已删除的刀片
+	assertCompatible(t, store, second.ID, 999, false)
+	if err := store.DeleteBlade(blade.ID, model.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assertCompatible(t, store, second.ID, blade.ID, false)
+}
+
+func contractStock(t *testing.T, store Store) {
+	razor := mustCreateRazor(t, store, "Rockwell", "6S")
+	blade := mustCreateBlade(t, store, "Feather", "Hi-Stainless", 1, razor.ID)
+
+	changed := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, true)
+	assertRemaining(t, store, blade.ID, 0)
+
+	record := &model.UsageRecord{UsageTime: baseTime.Add(time.Hour), RazorID: razor.ID, BladeID: blade.ID, NeedBladeChange: true}
+	if err := store.CreateUsageRecord(record); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("create without stock: err = %v, want ErrInsufficientStock", err)
+	}
+	if _, total, _ := store.GetUsageRecords(model.UsageRecordFilter{}, 0, 10); total != 1 {
+		t.Errorf("usage records after rejected create = %d, want 1", total)
+	}
+
+	// 不存在的剃须刀或刀片不扣减库存
+	if err := store.CreateUsageRecord(&model.UsageRecord{UsageTime: baseTime, RazorID: 999, BladeID: blade.ID}); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("create with missing razor: err = %v", err)
+	}
+	if err := store.CreateUsageRecord(&model.UsageRecord{UsageTime: baseTime, RazorID: razor.ID, BladeID: 999, NeedBladeChange: true}); !errors.Is(err, ErrBladeNotFound) {
+		t.Errorf("create with missing blade: err = %v", err)
+	}
+
+	if err := store.DeleteUsageRecord(changed.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertRemaining(t, store, blade.ID, 1)
+	if err := store.RestoreUsageRecord(changed.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertRemaining(t, store, blade.ID, 0)
+	if err := store.RestoreUsageRecord(changed.ID); !errors.Is(err, ErrUsageRecordNotFound) {
+		t.Errorf("restore twice: err = %v, want ErrUsageRecordNotFound", err)
+	}
+}
+
+func contractUsageOrdering(t *testing.T, store Store) {
+	razor := mustCreateRazor(t, store, "Yaqi", "Mellow")
+	blade := mustCreateBlade(t, store, "Voskhod", "Teflon", 5, razor.ID)
+	day := 24 * time.Hour
+	middle := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(day), false)
+	latest := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(2*day), false)
+	earliest := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, false)
+
+	records, total, err := store.GetUsageRecords(model.UsageRecordFilter{}, 0, 10)
+	if err != nil || total != 3 {
+		t.Fatalf("list usage records: %d, %v", total, err)
+	}
+	want := []uint{latest.ID, middle.ID, earliest.ID}
+	for i, record := range records {
+		if record.ID != want[i] {
+			t.Fatalf("default order = %v, want usage_time desc %v", usageIDs(records), want)
+		}
+		if record.Razor.ID != razor.ID || record.Blade.ID != blade.ID {
+			t.Errorf("record %d associations not loaded", record.ID)
+		}
+	}
+	assertUsageCounts(t, store, razor.ID, 1, 2, 3)
+
+	page, err := store.GetUsageRecordsByCursor(model.UsageRecordFilter{}, model.UsageRecordCursor{}, 2)
+	if err != nil || len(page) != 2 || page[0].ID != earliest.ID || page[1].ID != middle.ID {
+		t.Fatalf("first cursor page: %v, %v", usageIDs(page), err)
+	}
+	page, err = store.GetUsageRecordsByCursor(model.UsageRecordFilter{},
+		model.UsageRecordCursor{UsageTime: page[1].UsageTime, ID: page[1].ID}, 2)
+	if err != nil || len(page) != 1 || page[0].ID != latest.ID {
+		t.Errorf("second cursor page: %v, %v", usageIDs(page), err)
+	}
+
+	from := baseTime.Add(day)
+	records, total, err = store.GetUsageRecords(model.UsageRecordFilter{From: &from}, 0, 10)
+	if err != nil || total != 2 || len(records) != 2 {
+		t.Errorf("filter from: %d, %v", total, err)
+	}
+}
+
+func contractUsageCursor(t *testing.T, store Store) {
+	razor := mustCreateRazor(t, store, "Yaqi", "Mellow")
+	blade := mustCreateBlade(t, store, "Voskhod", "Teflon", 5, razor.ID)
+	// 三条记录的使用时间相同，只能靠id区分先后
+	before := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(-time.Hour), false)
+	a := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, false)
+	b := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, false)
+	c := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, false)
+	after := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(time.Hour), false)
+
+	// walk 从头按2条一页翻到底
+	walk := func(desc bool) []uint {
+		t.Helper()
+		var ids []uint
+		cursor := model.UsageRecordCursor{Desc: desc}
+		for i := 0; i < 5; i++ {
+			page, err := store.GetUsageRecordsByCursor(model.UsageRecordFilter{}, cursor, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, usageIDs(page)...)
+			if len(page) < 2 {
+				break
+			}
+			last := page[len(page)-1]
+			cursor = model.UsageRecordCursor{UsageTime: last.UsageTime, ID: last.ID, Desc: desc}
+		}
+		return ids
+	}
+	if got := walk(true); !equalIDs(got, after.ID, c.ID, b.ID, a.ID, before.ID) {
+		t.Errorf("desc pages = %v, want [%d %d %d %d %d]", got, after.ID, c.ID, b.ID, a.ID, before.ID)
+	}
+	if got := walk(false); !equalIDs(got, before.ID, a.ID, b.ID, c.ID, after.ID) {
+		t.Errorf("asc pages = %v, want [%d %d %d %d %d]", got, before.ID, a.ID, b.ID, c.ID, after.ID)
+	}
+
+	// 上一页：位置之前紧邻的记录，仍按显示顺序返回
+	page, err := store.GetUsageRecordsByCursor(model.UsageRecordFilter{},
+		model.UsageRecordCursor{UsageTime: baseTime, ID: a.ID, Desc: true, Backward: true}, 2)
+	if err != nil || !equalIDs(usageIDs(page), c.ID, b.ID) {
+		t.Errorf("desc backward from %d = %v, %v, want [%d %d]", a.ID, usageIDs(page), err, c.ID, b.ID)
+	}
+	page, err = store.GetUsageRecordsByCursor(model.UsageRecordFilter{},
+		model.UsageRecordCursor{UsageTime: baseTime, ID: c.ID, Backward: true}, 10)
+	if err != nil || !equalIDs(usageIDs(page), before.ID, a.ID, b.ID) {
+		t.Errorf("asc backward from %d = %v, %v, want [%d %d %d]", c.ID, usageIDs(page), err, before.ID, a.ID, b.ID)
+	}
+
+	// 同一时刻换成其他时区表示，结果不变
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	page, err = store.GetUsageRecordsByCursor(model.UsageRecordFilter{},
+		model.UsageRecordCursor{UsageTime: baseTime.In(shanghai), ID: b.ID, Desc: true}, 10)
+	if err != nil || !equalIDs(usageIDs(page), a.ID, before.ID) {
+		t.Errorf("cursor in +08:00 = %v, %v, want [%d %d]", usageIDs(page), err, a.ID, before.ID)
+	}
+	// 被篡改的游标指向不存在的记录，只按位置比较，不报错也不重复返回
+	page, err = store.GetUsageRecordsByCursor(model.UsageRecordFilter{},
+		model.UsageRecordCursor{UsageTime: baseTime.Add(30 * time.Minute), ID: 9999, Desc: true}, 10)
+	if err != nil || !equalIDs(usageIDs(page), c.ID, b.ID, a.ID, before.ID) {
+		t.Errorf("cursor to missing record = %v, %v", usageIDs(page), err)
+	}
+}
+
+func contractAudit(t *testing.T, base Store) {
+	store := base.WithActor(model.AuditActor{RequestID: "req-1"})
+	events := func(entityType string, id uint) []model.AuditEvent {
+		t.Helper()
+		list, _, err := store.GetAuditEvents(model.AuditFilter{EntityType: entityType, EntityID: id}, 0, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list
+	}
+
+	razor := mustCreateRazor(t, store, "Merkur", "34C")
+	blade := mustCreateBlade(t, store, "Astra", "SP", 1, razor.ID)
+	created := events(model.AuditEntityRazor, razor.ID)
+	if len(created) != 1 || created[0].Action != model.AuditActionCreate || created[0].Before != nil ||
+		created[0].After["brand"] != "Merkur" || created[0].RequestID != "req-1" {
+		t.Fatalf("create events = %+v", created)
+	}
+
+	// 更新只记录变化的字段，不含updated_at
+	razor.Notes = "daily"
+	if err := store.UpdateRazor(razor); err != nil {
+		t.Fatal(err)
+	}
+	list := events(model.AuditEntityRazor, razor.ID)
+	if len(list) != 2 {
+		t.Fatalf("razor events = %d, want 2", len(list))
+	}
+	update := list[0]
+	if list[1].Action == model.AuditActionUpdate {
+		update = list[1]
+	}
+	if update.Action != model.AuditActionUpdate || len(update.Before) != 1 || len(update.After) != 1 ||
+		update.Before["notes"] != "" || update.After["notes"] != "daily" {
+		t.Errorf("update diff = %v -> %v, want notes \"\" -> \"daily\"", update.Before, update.After)
+	}
+	// 内容没有变化时不记录
+	if err := store.UpdateRazor(razor); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(events(model.AuditEntityRazor, razor.ID)); n != 2 {
+		t.Errorf("razor events after no-op update = %d, want 2", n)
+	}
+
+	// 换刀使刀片库存变化，刀片也记录一条更新
+	mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, true)
+	bladeEvents := events(model.AuditEntityBlade, blade.ID)
+	var stock *model.AuditEvent
+	for i := range bladeEvents {
+		if bladeEvents[i].Action == model.AuditActionUpdate {
+			stock = &bladeEvents[i]
+		}
+	}
+	if stock == nil || stock.Before["remaining_quantity"] != float64(1) || stock.After["remaining_quantity"] != float64(0) {
+		t.Errorf("blade stock event = %+v", stock)
+	}
+
+	// 修改失败时修改和审计事件一起回滚
+	second := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(time.Hour), false)
+	before := len(events("", 0))
+	second.NeedBladeChange = true
+	if err := store.UpdateUsageRecord(second); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("update without stock: err = %v, want ErrInsufficientStock", err)
+	}
+	if n := len(events("", 0)); n != before {
+		t.Errorf("events after failed update = %d, want %d", n, before)
+	}
+
+	errRollback := errors.New("rollback")
+	err := store.Transaction(func(tx Store) error {
+		razor.Notes = "rolled back"
+		if err := tx.UpdateRazor(razor); err != nil {
+			return err
+		}
+		mustCreateRazor(t, tx, "Gillette", "Tech")
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("transaction: err = %v", err)
+	}
+	if n := len(events("", 0)); n != before {
+		t.Errorf("events after rolled back transaction = %d, want %d", n, before)
+	}
+	if got, _ := store.GetRazorByID(razor.ID); got.Notes != "daily" {
+		t.Errorf("notes after rollback = %q, want daily", got.Notes)
+	}
+}
+
+func contractForUser(t *testing.T, store Store) {
+	alice := &model.User{Username: "alice", PasswordHash: "x"}
+	bob := &model.User{Username: "bob", PasswordHash: "x"}
+	for _, user := range []*model.User{alice, bob} {
+		if err := store.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.CreateUser(&model.User{Username: "alice", PasswordHash: "x"}); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("duplicate username: err = %v, want ErrUsernameTaken", err)
+	}
+	as, bs := store.ForUser(alice.ID), store.ForUser(bob.ID)
+
+	razor := mustCreateRazor(t, as, "Merkur", "34C")
+	blade := mustCreateBlade(t, as, "Astra", "SP", 3, razor.ID)
+	mustCreateUsage(t, as, razor.ID, blade.ID, baseTime, true)
+	bobRazor := mustCreateRazor(t, bs, "Gillette", "Tech")
+
+	if _, err := bs.GetRazorByID(razor.ID); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("bob reads alice's razor: err = %v", err)
+	}
+	if _, err := bs.GetBladeByID(blade.ID); !errors.Is(err, ErrBladeNotFound) {
+		t.Errorf("bob reads alice's blade: err = %v", err)
+	}
+	if _, total, _ := bs.GetRazors(model.RazorFilter{}, 0, 10); total != 1 {
+		t.Errorf("bob's razors = %d, want 1", total)
+	}
+	if _, total, _ := bs.GetUsageRecords(model.UsageRecordFilter{}, 0, 10); total != 0 {
+		t.Errorf("bob's usage records = %d, want 0", total)
+	}
+	if _, total, _ := store.GetRazors(model.RazorFilter{}, 0, 10); total != 2 {
+		t.Errorf("unscoped razors = %d, want 2", total)
+	}
+
+	// 不能引用或修改其他用户的数据
+	if err := bs.CreateUsageRecord(&model.UsageRecord{UsageTime: baseTime, RazorID: bobRazor.ID, BladeID: blade.ID}); !errors.Is(err, ErrBladeNotFound) {
+		t.Errorf("bob uses alice's blade: err = %v", err)
+	}
+	if err := as.CreateBlade(&model.Blade{Brand: "Feather", Model: "Pro", CompatibleRazorIDs: []uint{bobRazor.ID}}); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("alice declares bob's razor compatible: err = %v", err)
+	}
+	if err := bs.DeleteRazor(razor.ID, model.DeleteOptions{Cascade: true}); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("bob deletes alice's razor: err = %v", err)
+	}
+	assertCompatible(t, as, razor.ID, blade.ID, true)
+	assertCompatible(t, bs, razor.ID, blade.ID, false)
+	if err := as.DeleteRazor(bobRazor.ID, model.DeleteOptions{}); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("alice deletes bob's razor: err = %v", err)
+	}
+	if _, err := bs.GetRazorByID(bobRazor.ID); err != nil {
+		t.Errorf("bob's razor after alice's delete attempt: %v", err)
+	}
+}
+
+func contractTrash(t *testing.T, store Store) {
+	razor := mustCreateRazor(t, store, "Karve", "CB")
+	other := mustCreateRazor(t, store, "Timeless", "95")
+	blade := mustCreateBlade(t, store, "Nacet", "Stainless", 2, razor.ID)
+	first := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, true)
+	mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(time.Hour), false)
+	assertRemaining(t, store, blade.ID, 1)
+
+	// 有依赖时默认拒绝并报告
+	err := store.DeleteRazor(razor.ID, model.DeleteOptions{})
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) || !errors.Is(err, ErrRazorInUse) {
+		t.Fatalf("delete razor in use: err = %v, want DependencyError", err)
+	}
+	if len(depErr.Report.UsageRecordIDs) != 2 || !equalIDs(depErr.Report.CompatibleBladeIDs, blade.ID) {
+		t.Errorf("dependency report = %+v", depErr.Report)
+	}
+
+	// 级联删除：使用记录一起进入回收站并归还库存
+	if err := store.DeleteRazor(razor.ID, model.DeleteOptions{Cascade: true}); err != nil {
+		t.Fatal(err)
+	}
+	trash, err := store.GetTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash.Razors) != 1 || trash.Razors[0].ID != razor.ID || len(trash.UsageRecords) != 2 || len(trash.Blades) != 0 {
+		t.Errorf("trash = %d razors, %d blades, %d usage records", len(trash.Razors), len(trash.Blades), len(trash.UsageRecords))
+	}
+	assertRemaining(t, store, blade.ID, 2)
+	assertCompatible(t, store, razor.ID, blade.ID, false)
+	if got := mustGetBlade(t, store, blade.ID); len(got.CompatibleRazorIDs) != 0 {
+		t.Errorf("compatible razors with razor in trash = %v", got.CompatibleRazorIDs)
+	}
+	if err := store.RestoreUsageRecord(first.ID); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("restore record of trashed razor: err = %v, want ErrRazorNotFound", err)
+	}
+
+	// 恢复剃须刀时一并恢复使用记录和兼容关系，重新扣减库存
+	if err := store.RestoreRazor(razor.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, _ := store.GetUsageRecords(model.UsageRecordFilter{RazorID: razor.ID}, 0, 10); total != 2 {
+		t.Errorf("usage records after restore = %d, want 2", total)
+	}
+	assertRemaining(t, store, blade.ID, 1)
+	assertCompatible(t, store, razor.ID, blade.ID, true)
+	if err := store.RestoreRazor(razor.ID); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("restore live razor: err = %v, want ErrRazorNotFound", err)
+	}
+
+	// 转移到另一剃须刀后删除
+	if err := store.DeleteRazor(razor.ID, model.DeleteOptions{ReassignTo: other.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, _ := store.GetUsageRecords(model.UsageRecordFilter{RazorID: other.ID}, 0, 10); total != 2 {
+		t.Errorf("usage records on reassign target = %d, want 2", total)
+	}
+	assertUsageCounts(t, store, other.ID, 1, 1)
+
+	// 彻底删除回收站中的剃须刀
+	result, err := store.PurgeTrash(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Razors != 1 || result.UsageRecords != 0 {
+		t.Errorf("purge result = %+v", result)
+	}
+	if err := store.RestoreRazor(razor.ID); !errors.Is(err, ErrRazorNotFound) {
+		t.Errorf("restore purged razor: err = %v, want ErrRazorNotFound", err)
+	}
+	if trash, _ := store.GetTrash(); len(trash.Razors) != 0 {
+		t.Errorf("trash after purge has %d razors", len(trash.Razors))
+	}
+}
+
+func contractTimeSeries(t *testing.T, store Store) {
+	razor := mustCreateRazor(t, store, "Parker", "Variant")
+	blade := mustCreateBlade(t, store, "Derby", "Extra", 5, razor.ID)
+	other := mustCreateBlade(t, store, "Astra", "SP", 5, razor.ID)
+	day := 24 * time.Hour
+	rating := func(v int) *int { return &v }
+	for _, r := range []*model.UsageRecord{
+		{UsageTime: baseTime.Add(time.Hour), BladeID: blade.ID, Rating: rating(4), NeedBladeChange: true},
+		{UsageTime: baseTime.Add(2 * time.Hour), BladeID: blade.ID, Rating: rating(2)},
+		{UsageTime: baseTime.Add(2*day + time.Hour), BladeID: blade.ID, NeedBladeChange: true},
+		{UsageTime: baseTime.Add(2*day + 2*time.Hour), BladeID: other.ID, NeedBladeChange: true},
+		{UsageTime: baseTime.Add(2*day + 3*time.Hour), BladeID: other.ID, NeedBladeChange: true},
+		{UsageTime: baseTime.Add(5 * day), BladeID: blade.ID, NeedBladeChange: true}, // 超出范围
+	} {
+		r.RazorID = razor.ID
+		if err := store.CreateUsageRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	boundaries := []time.Time{baseTime, baseTime.Add(day), baseTime.Add(2 * day), baseTime.Add(3 * day)}
+	points, err := store.GetUsageTimeSeries(model.UsageRecordFilter{}, boundaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("points = %d, want 3", len(points))
+	}
+	wantShaves := []int64{2, 0, 3}
+	for i, p := range points {
+		if !p.Start.Equal(boundaries[i]) || !p.End.Equal(boundaries[i+1]) {
+			t.Errorf("point %d spans %v-%v", i, p.Start, p.End)
+		}
+		if p.ShaveCount != wantShaves[i] {
+			t.Errorf("point %d shave count = %d, want %d", i, p.ShaveCount, wantShaves[i])
+		}
+	}
+	if points[0].AverageRating == nil || *points[0].AverageRating != 3 {
+		t.Errorf("average rating = %v, want 3", points[0].AverageRating)
+	}
+	if points[1].AverageRating != nil || points[2].AverageRating != nil {
+		t.Error("buckets without ratings should have a null average")
+	}
+	if points[0].BladeChanges != 1 || points[2].BladeChanges != 3 {
+		t.Errorf("blade changes = %d, %d", points[0].BladeChanges, points[2].BladeChanges)
+	}
+	// 换刀次数按刀片分组，供服务层折算花费
+	wantChanges := []map[uint]int64{{blade.ID: 1}, nil, {blade.ID: 1, other.ID: 2}}
+	for i, p := range points {
+		if fmt.Sprint(p.ChangesByBlade) != fmt.Sprint(wantChanges[i]) {
+			t.Errorf("point %d changes by blade = %v, want %v", i, p.ChangesByBlade, wantChanges[i])
+		}
+	}
+}
+
+func assertCompatible(t *testing.T, store Store, razorID, bladeID uint, want bool) {
+	t.Helper()
+	got, err := store.IsBladeCompatible(razorID, bladeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("IsBladeCompatible(%d, %d) = %v, want %v", razorID, bladeID, got, want)
+	}
+}
+
+func equalIDs(got []uint, want ...uint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func usageIDs(records []model.UsageRecord) []uint {
+	ids := make([]uint, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	return ids
+}
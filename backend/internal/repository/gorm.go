@@ -0,0 +1,995 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"razor-blade/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormStore 基于gorm的Store实现，支持SQLite和PostgreSQL
+type GormStore struct {
+	db     *gorm.DB
+	userID uint             // 为0时不按用户过滤
+	actor  model.AuditActor // 写入审计事件的操作者
+}
+
+// NewGormStore 使用已连接的数据库创建存储
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (g *GormStore) ForUser(userID uint) Store {
+	return &GormStore{db: g.db, userID: userID, actor: g.actor}
+}
+
+// Transaction 事务内各方法自己的事务以保存点实现
+func (g *GormStore) Transaction(fn func(Store) error) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx, userID: g.userID, actor: g.actor})
+	})
+}
+
+// scoped 限定查询只涉及当前用户的数据
+func (g *GormStore) scoped(tx *gorm.DB) *gorm.DB {
+	if g.userID == 0 {
+		return tx
+	}
+	return tx.Where(clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: "user_id"},
+		Value:  g.userID,
+	})
+}
+
+// owner 返回新建数据的所属用户，不按用户过滤时保留调用方设置的值
+func (g *GormStore) owner(userID uint) uint {
+	if g.userID == 0 {
+		return userID
+	}
+	return g.userID
+}
+
+// Razor相关方法
+func (g *GormStore) CreateRazor(razor *model.Razor) error {
+	razor.UserID = g.owner(razor.UserID)
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		if err := tx.Omit(clause.Associations).Create(razor).Error; err != nil {
+			return err
+		}
+		audit.created(model.AuditEntityRazor, razor.ID)
+		if len(razor.Purchases) == 0 {
+			return nil
+		}
+		for i := range razor.Purchases {
+			razor.Purchases[i].RazorID, razor.Purchases[i].BladeID = &razor.ID, nil
+			razor.Purchases[i].UserID = razor.UserID
+		}
+		if err := tx.Create(&razor.Purchases).Error; err != nil {
+			return err
+		}
+		for _, purchase := range razor.Purchases {
+			audit.created(model.AuditEntityPurchase, purchase.ID)
+		}
+		if err := syncRazorPurchases(tx, razor.ID); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).First(razor, razor.ID).Error
+	})
+}
+
+func (g *GormStore) GetRazorByID(id uint) (*model.Razor, error) {
+	var razor model.Razor
+	err := g.scoped(g.db).First(&razor, id).Error
+	if err != nil {
+		return nil, notFound(err, ErrRazorNotFound)
+	}
+	return &razor, nil
+}
+
+func (g *GormStore) GetRazors(filter model.RazorFilter, offset, limit int) ([]model.Razor, int64, error) {
+	var razors []model.Razor
+	var total int64
+
+	if err := g.razorQuery(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := orderBy(g.razorQuery(filter), filter.Sort, defaultRazorSort).
+		Offset(offset).Limit(limit).
+		Find(&razors).Error
+	return razors, total, err
+}
+
+func (g *GormStore) GetAllRazors(filter model.RazorFilter) ([]model.Razor, error) {
+	var razors []model.Razor
+	err := orderBy(g.razorQuery(filter), filter.Sort, defaultRazorSort).Find(&razors).Error
+	return razors, err
+}
+
+func (g *GormStore) UpdateRazor(razor *model.Razor) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var old model.Razor
+		if err := g.scoped(tx).Select("id", "user_id").First(&old, razor.ID).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		razor.UserID = old.UserID
+		if err := audit.watch(model.AuditEntityRazor, razor.ID); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(razor).Error
+	})
+}
+
+func (g *GormStore) DeleteRazor(id uint, opts model.DeleteOptions) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var razor model.Razor
+		if err := g.scoped(tx).Select("id", "user_id").First(&razor, id).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		if err := g.watchRazorCascade(tx, audit, id); err != nil {
+			return err
+		}
+		switch {
+		case opts.ReassignTo != 0:
+			if err := reassignRazorUsage(tx, audit, &razor, opts.ReassignTo); err != nil {
+				return err
+			}
+		case !opts.Cascade:
+			report := &model.DependencyReport{Entity: model.AuditEntityRazor, ID: id}
+			if err := tx.Model(&model.UsageRecord{}).Where("razor_id = ?", id).
+				Order("id").Pluck("id", &report.UsageRecordIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.RazorBladeCompatibility{}).Where("razor_id = ?", id).
+				Where("blade_id IN (?)", tx.Session(&gorm.Session{NewDB: true}).Model(&model.Blade{}).Select("id")).
+				Order("blade_id").Pluck("blade_id", &report.CompatibleBladeIDs).Error; err != nil {
+				return err
+			}
+			if report.HasDependents() {
+				return &DependencyError{Err: ErrRazorInUse, Report: report}
+			}
+		}
+		at := trashTime()
+		if err := trashUsageRecords(tx, audit, "razor_id", id, at); err != nil {
+			return err
+		}
+		return tx.Model(&model.Razor{}).Where("id = ?", id).UpdateColumn("deleted_at", at).Error
+	})
+}
+
+func (g *GormStore) RestoreRazor(id uint) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var razor model.Razor
+		if err := g.scoped(tx.Unscoped()).Where("deleted_at IS NOT NULL").
+			Select("id", "deleted_at").First(&razor, id).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		if err := g.watchRazorCascade(tx, audit, id); err != nil {
+			return err
+		}
+		if err := audit.watchRestore(model.AuditEntityRazor, id); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Razor{}).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return restoreUsageRecords(tx, audit, "razor_id", id, razor.DeletedAt.Time)
+	})
+}
+
+// Blade相关方法
+func (g *GormStore) CreateBlade(blade *model.Blade) error {
+	blade.UserID = g.owner(blade.UserID)
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		if err := checkRazorsExist(g.scoped(tx), blade.CompatibleRazorIDs); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Create(blade).Error; err != nil {
+			return err
+		}
+		audit.created(model.AuditEntityBlade, blade.ID)
+		if err := replaceCompatibility(tx, blade); err != nil {
+			return err
+		}
+		if len(blade.Purchases) == 0 {
+			return nil
+		}
+		for i := range blade.Purchases {
+			blade.Purchases[i].RazorID, blade.Purchases[i].BladeID = nil, &blade.ID
+			blade.Purchases[i].UserID = blade.UserID
+		}
+		if err := tx.Create(&blade.Purchases).Error; err != nil {
+			return err
+		}
+		for _, purchase := range blade.Purchases {
+			audit.created(model.AuditEntityPurchase, purchase.ID)
+		}
+		if err := syncBladePurchases(tx, blade.ID); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).First(blade, blade.ID).Error
+	})
+}
+
+func (g *GormStore) GetBladeByID(id uint) (*model.Blade, error) {
+	var blade model.Blade
+	err := g.scoped(g.db).First(&blade, id).Error
+	if err != nil {
+		return nil, notFound(err, ErrBladeNotFound)
+	}
+	if err := g.fillCompatibility([]*model.Blade{&blade}); err != nil {
+		return nil, err
+	}
+	return &blade, nil
+}
+
+func (g *GormStore) GetBlades(filter model.BladeFilter, offset, limit int) ([]model.Blade, int64, error) {
+	var blades []model.Blade
+	var total int64
+
+	if err := g.bladeQuery(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := orderBy(g.bladeQuery(filter), filter.Sort, defaultBladeSort).
+		Offset(offset).Limit(limit).
+		Find(&blades).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	ptrs := make([]*model.Blade, len(blades))
+	for i := range blades {
+		ptrs[i] = &blades[i]
+	}
+	return blades, total, g.fillCompatibility(ptrs)
+}
+
+func (g *GormStore) GetAllBlades(filter model.BladeFilter) ([]model.Blade, error) {
+	var blades []model.Blade
+	if err := orderBy(g.bladeQuery(filter), filter.Sort, defaultBladeSort).Find(&blades).Error; err != nil {
+		return nil, err
+	}
+	ptrs := make([]*model.Blade, len(blades))
+	for i := range blades {
+		ptrs[i] = &blades[i]
+	}
+	return blades, g.fillCompatibility(ptrs)
+}
+
+func (g *GormStore) UpdateBlade(blade *model.Blade) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var old model.Blade
+		if err := g.scoped(tx).Select("id", "user_id").First(&old, blade.ID).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		blade.UserID = old.UserID
+		if err := audit.watch(model.AuditEntityBlade, blade.ID); err != nil {
+			return err
+		}
+		if err := checkRazorsExist(g.scoped(tx), blade.CompatibleRazorIDs); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(blade).Error; err != nil {
+			return err
+		}
+		return replaceCompatibility(tx, blade)
+	})
+}
+
+func (g *GormStore) GetCompatibleBlades(razorID uint) ([]model.Blade, error) {
+	if err := g.scoped(g.db).Select("id").First(&model.Razor{}, razorID).Error; err != nil {
+		return nil, notFound(err, ErrRazorNotFound)
+	}
+
+	var blades []model.Blade
+	err := g.scoped(g.db.Model(&model.Blade{})).
+		Joins("JOIN razor_blade_compatibility c ON c.blade_id = blades.id").
+		Where("c.razor_id = ?", razorID).
+		Order("blades.id").
+		Find(&blades).Error
+	if err != nil {
+		return nil, err
+	}
+	ptrs := make([]*model.Blade, len(blades))
+	for i := range blades {
+		ptrs[i] = &blades[i]
+	}
+	return blades, g.fillCompatibility(ptrs)
+}
+
+func (g *GormStore) IsBladeCompatible(razorID, bladeID uint) (bool, error) {
+	var count int64
+	err := g.db.Model(&model.RazorBladeCompatibility{}).
+		Where("razor_id = ? AND blade_id = ?", razorID, bladeID).
+		Where("razor_id IN (?)", g.scoped(g.db.Model(&model.Razor{})).Select("id")).
+		Where("blade_id IN (?)", g.scoped(g.db.Model(&model.Blade{})).Select("id")).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// fillCompatibility 批量填充刀片的兼容剃须刀ID
+func (g *GormStore) fillCompatibility(blades []*model.Blade) error {
+	if len(blades) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(blades))
+	byID := make(map[uint]*model.Blade, len(blades))
+	for i, blade := range blades {
+		ids[i] = blade.ID
+		byID[blade.ID] = blade
+		blade.CompatibleRazorIDs = []uint{}
+	}
+
+	// 回收站中的剃须刀不出现在兼容列表中，恢复后重新出现
+	var rows []model.RazorBladeCompatibility
+	if err := g.db.Where("blade_id IN ?", ids).
+		Where("razor_id IN (?)", g.db.Model(&model.Razor{}).Select("id")).
+		Order("razor_id").Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		blade := byID[row.BladeID]
+		blade.CompatibleRazorIDs = append(blade.CompatibleRazorIDs, row.RazorID)
+	}
+	return nil
+}
+
+// checkRazorsExist 校验引用的剃须刀都存在
+func checkRazorsExist(tx *gorm.DB, razorIDs []uint) error {
+	ids := uniqueIDs(razorIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+	var found []uint
+	if err := tx.Model(&model.Razor{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return err
+	}
+	if len(found) == len(ids) {
+		return nil
+	}
+	exists := make(map[uint]bool, len(found))
+	for _, id := range found {
+		exists[id] = true
+	}
+	for _, id := range ids {
+		if !exists[id] {
+			return fmt.Errorf("%w: %d", ErrRazorNotFound, id)
+		}
+	}
+	return nil
+}
+
+// replaceCompatibility 用blade.CompatibleRazorIDs替换该刀片的兼容关系，与回收站中剃须刀的兼容关系保留
+func replaceCompatibility(tx *gorm.DB, blade *model.Blade) error {
+	if err := tx.Where("blade_id = ?", blade.ID).
+		Where("razor_id IN (?)", tx.Session(&gorm.Session{NewDB: true}).Model(&model.Razor{}).Select("id")).
+		Delete(&model.RazorBladeCompatibility{}).Error; err != nil {
+		return err
+	}
+	blade.CompatibleRazorIDs = uniqueIDs(blade.CompatibleRazorIDs)
+	if len(blade.CompatibleRazorIDs) == 0 {
+		return nil
+	}
+	rows := make([]model.RazorBladeCompatibility, len(blade.CompatibleRazorIDs))
+	for i, razorID := range blade.CompatibleRazorIDs {
+		rows[i] = model.RazorBladeCompatibility{RazorID: razorID, BladeID: blade.ID}
+	}
+	return tx.Create(&rows).Error
+}
+
+func (g *GormStore) DeleteBlade(id uint, opts model.DeleteOptions) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var blade model.Blade
+		if err := g.scoped(tx).Select("id", "user_id").First(&blade, id).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		if err := audit.watch(model.AuditEntityBlade, id); err != nil {
+			return err
+		}
+		switch {
+		case opts.ReassignTo != 0:
+			if err := reassignBladeUsage(tx, audit, &blade, opts.ReassignTo); err != nil {
+				return err
+			}
+		case !opts.Cascade:
+			report := &model.DependencyReport{Entity: model.AuditEntityBlade, ID: id}
+			if err := tx.Model(&model.UsageRecord{}).Where("blade_id = ?", id).
+				Order("id").Pluck("id", &report.UsageRecordIDs).Error; err != nil {
+				return err
+			}
+			if report.HasDependents() {
+				return &DependencyError{Err: ErrBladeInUse, Report: report}
+			}
+		}
+		// 先删除使用记录，归还的库存随刀片一起保留在回收站中
+		at := trashTime()
+		if err := trashUsageRecords(tx, audit, "blade_id", id, at); err != nil {
+			return err
+		}
+		return tx.Model(&model.Blade{}).Where("id = ?", id).UpdateColumn("deleted_at", at).Error
+	})
+}
+
+func (g *GormStore) RestoreBlade(id uint) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var blade model.Blade
+		if err := g.scoped(tx.Unscoped()).Where("deleted_at IS NOT NULL").
+			Select("id", "deleted_at").First(&blade, id).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		if err := audit.watchRestore(model.AuditEntityBlade, id); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&model.Blade{}).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return restoreUsageRecords(tx, audit, "blade_id", id, blade.DeletedAt.Time)
+	})
+}
+
+// reassignRazorUsage 把剃须刀的使用记录转移到同一用户的另一剃须刀，并重新计算目标剃须刀的刀片使用次数
+func reassignRazorUsage(tx *gorm.DB, audit *auditBatch, razor *model.Razor, targetID uint) error {
+	if targetID == razor.ID {
+		return ErrReassignTargetNotFound
+	}
+	var target model.Razor
+	err := tx.Select("id", "user_id").Where("user_id = ?", razor.UserID).First(&target, targetID).Error
+	if err != nil {
+		return notFound(err, ErrReassignTargetNotFound)
+	}
+	var ids []uint
+	if err := tx.Model(&model.UsageRecord{}).Where("razor_id = ?", razor.ID).Order("id").Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return err
+	}
+	if err := audit.watch(model.AuditEntityUsageRecord, ids...); err != nil {
+		return err
+	}
+	if err := tx.Model(&model.UsageRecord{}).Where("id IN ?", ids).Update("razor_id", target.ID).Error; err != nil {
+		return err
+	}
+	return renumberBladeUsage(tx, target.ID, nil)
+}
+
+// reassignBladeUsage 把刀片的使用记录转移到同一用户的另一刀片，换过刀片的记录改为扣减目标刀片的库存
+func reassignBladeUsage(tx *gorm.DB, audit *auditBatch, blade *model.Blade, targetID uint) error {
+	if targetID == blade.ID {
+		return ErrReassignTargetNotFound
+	}
+	var target model.Blade
+	err := tx.Select("id", "user_id").Where("user_id = ?", blade.UserID).First(&target, targetID).Error
+	if err != nil {
+		return notFound(err, ErrReassignTargetNotFound)
+	}
+	var records []model.UsageRecord
+	if err := tx.Select("id, razor_id, need_blade_change").Where("blade_id = ?", blade.ID).
+		Order("id").Find(&records).Error; err != nil || len(records) == 0 {
+		return err
+	}
+	if err := audit.watch(model.AuditEntityBlade, target.ID); err != nil {
+		return err
+	}
+
+	ids := make([]uint, len(records))
+	razorIDs := make([]uint, len(records))
+	var changes int64
+	for i := range records {
+		ids[i], razorIDs[i] = records[i].ID, records[i].RazorID
+		if records[i].NeedBladeChange {
+			changes++
+		}
+		if err := audit.watch(model.AuditEntityUsageRecord, records[i].ID); err != nil {
+			return err
+		}
+	}
+	if changes > 0 {
+		result := tx.Model(&model.Blade{}).
+			Where("id = ? AND remaining_quantity >= ?", target.ID, changes).
+			Updates(map[string]interface{}{
+				"remaining_quantity": gorm.Expr("remaining_quantity - ?", changes),
+				"updated_at":         time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+		if err := tx.Model(&model.Blade{}).Where("id = ?", blade.ID).Updates(map[string]interface{}{
+			"remaining_quantity": gorm.Expr("remaining_quantity + ?", changes),
+			"updated_at":         time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&model.UsageRecord{}).Where("id IN ?", ids).Update("blade_id", target.ID).Error; err != nil {
+		return err
+	}
+	for _, razorID := range uniqueIDs(razorIDs) {
+		if err := renumberBladeUsage(tx, razorID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trashUsageRecords 把剃须刀或刀片的使用记录以相同的删除时间移入回收站，归还换刀扣减的库存
+func trashUsageRecords(tx *gorm.DB, audit *auditBatch, column string, id uint, at time.Time) error {
+	var records []model.UsageRecord
+	if err := tx.Select("id, razor_id, blade_id, need_blade_change").
+		Where(column+" = ?", id).Order("id").
+		Find(&records).Error; err != nil || len(records) == 0 {
+		return err
+	}
+
+	ids := make([]uint, len(records))
+	razorIDs := make([]uint, 0, len(records))
+	for i := range records {
+		ids[i] = records[i].ID
+		razorIDs = append(razorIDs, records[i].RazorID)
+		if err := audit.watch(model.AuditEntityUsageRecord, records[i].ID); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&records[i], nil)...); err != nil {
+			return err
+		}
+	}
+	for i := range records {
+		if records[i].NeedBladeChange {
+			if err := incrementBladeStock(tx, records[i].BladeID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Model(&model.UsageRecord{}).Where("id IN ?", ids).UpdateColumn("deleted_at", at).Error; err != nil {
+		return err
+	}
+	for _, razorID := range uniqueIDs(razorIDs) {
+		if err := renumberBladeUsage(tx, razorID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreUsageRecords 恢复与剃须刀或刀片一起删除的使用记录，另一端仍在回收站中的不恢复。
+// 按使用时间重新扣减库存，库存不足时返回ErrInsufficientStock
+func restoreUsageRecords(tx *gorm.DB, audit *auditBatch, column string, id uint, at time.Time) error {
+	var records []model.UsageRecord
+	if err := tx.Unscoped().Select("id, razor_id, blade_id, need_blade_change").
+		Where(column+" = ? AND deleted_at = ?", id, at).
+		Where("razor_id IN (?)", tx.Session(&gorm.Session{NewDB: true}).Model(&model.Razor{}).Select("id")).
+		Where("blade_id IN (?)", tx.Session(&gorm.Session{NewDB: true}).Model(&model.Blade{}).Select("id")).
+		Order("usage_time, id").
+		Find(&records).Error; err != nil || len(records) == 0 {
+		return err
+	}
+
+	ids := make([]uint, len(records))
+	razorIDs := make([]uint, 0, len(records))
+	for i := range records {
+		ids[i] = records[i].ID
+		razorIDs = append(razorIDs, records[i].RazorID)
+		if err := audit.watchRestore(model.AuditEntityUsageRecord, records[i].ID); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&records[i], nil)...); err != nil {
+			return err
+		}
+	}
+	for i := range records {
+		if records[i].NeedBladeChange {
+			if err := decrementBladeStock(tx, records[i].BladeID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Unscoped().Model(&model.UsageRecord{}).Where("id IN ?", ids).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	for _, razorID := range uniqueIDs(razorIDs) {
+		if err := renumberBladeUsage(tx, razorID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UsageRecord相关方法
+func (g *GormStore) CreateUsageRecord(record *model.UsageRecord) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var razor model.Razor
+		if err := g.scoped(tx).Select("id", "user_id").First(&razor, record.RazorID).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		if err := g.scoped(tx).Select("id").First(&model.Blade{}, record.BladeID).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		record.UserID = razor.UserID
+
+		if record.NeedBladeChange {
+			if err := audit.watch(model.AuditEntityBlade, record.BladeID); err != nil {
+				return err
+			}
+			if err := decrementBladeStock(tx, record.BladeID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		audit.created(model.AuditEntityUsageRecord, record.ID)
+		return renumberBladeUsage(tx, record.RazorID, record)
+	})
+}
+
+func (g *GormStore) GetUsageRecordByID(id uint) (*model.UsageRecord, error) {
+	var record model.UsageRecord
+	err := g.scoped(g.db).Preload("Razor").Preload("Blade").First(&record, id).Error
+	if err != nil {
+		return nil, notFound(err, ErrUsageRecordNotFound)
+	}
+	return &record, nil
+}
+
+func (g *GormStore) GetUsageRecords(filter model.UsageRecordFilter, offset, limit int) ([]model.UsageRecord, int64, error) {
+	var records []model.UsageRecord
+	var total int64
+
+	if err := g.usageRecordQuery(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := orderBy(g.usageRecordQuery(filter), filter.Sort, defaultUsageRecordSort).
+		Preload("Razor").Preload("Blade").
+		Offset(offset).Limit(limit).
+		Find(&records).Error
+	return records, total, err
+}
+
+func (g *GormStore) GetAllUsageRecords(filter model.UsageRecordFilter) ([]model.UsageRecord, error) {
+	var records []model.UsageRecord
+	err := orderBy(g.usageRecordQuery(filter), filter.Sort, defaultUsageRecordSort).Find(&records).Error
+	return records, err
+}
+
+func (g *GormStore) GetUsageRecordsByCursor(filter model.UsageRecordFilter, cursor model.UsageRecordCursor, limit int) ([]model.UsageRecord, error) {
+	// 翻上一页时反向扫描，取到后再倒回显示顺序
+	scanDesc := cursor.Desc != cursor.Backward
+	op, direction := ">", "ASC"
+	if scanDesc {
+		op, direction = "<", "DESC"
+	}
+
+	query := g.usageRecordQuery(filter)
+	if cursor.ID != 0 {
+		t := cursor.UsageTime.UTC()
+		query = query.Where(fmt.Sprintf("(usage_time %s ? OR (usage_time = ? AND id %s ?))", op, op), t, t, cursor.ID)
+	}
+
+	var records []model.UsageRecord
+	err := query.Order("usage_time " + direction).Order("id " + direction).
+		Preload("Razor").Preload("Blade").
+		Limit(limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Backward {
+		reverse(records)
+	}
+	return records, nil
+}
+
+func (g *GormStore) UpdateUsageRecord(record *model.UsageRecord) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var old model.UsageRecord
+		if err := g.scoped(tx).First(&old, record.ID).Error; err != nil {
+			return notFound(err, ErrUsageRecordNotFound)
+		}
+		if err := g.scoped(tx).Select("id").First(&model.Razor{}, record.RazorID).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		if err := g.scoped(tx).Select("id").First(&model.Blade{}, record.BladeID).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		record.UserID = old.UserID
+		if err := audit.watch(model.AuditEntityUsageRecord, record.ID); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&old, record)...); err != nil {
+			return err
+		}
+
+		if old.NeedBladeChange {
+			if err := incrementBladeStock(tx, old.BladeID); err != nil {
+				return err
+			}
+		}
+		if record.NeedBladeChange {
+			if err := decrementBladeStock(tx, record.BladeID); err != nil {
+				return err
+			}
+		}
+
+		// 忽略预加载的关联对象，否则其ID会覆盖新的RazorID/BladeID
+		if err := tx.Omit(clause.Associations).Save(record).Error; err != nil {
+			return err
+		}
+		if old.RazorID != record.RazorID {
+			if err := renumberBladeUsage(tx, old.RazorID, nil); err != nil {
+				return err
+			}
+		}
+		return renumberBladeUsage(tx, record.RazorID, record)
+	})
+}
+
+func (g *GormStore) DeleteUsageRecord(id uint) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var old model.UsageRecord
+		if err := g.scoped(tx).First(&old, id).Error; err != nil {
+			return notFound(err, ErrUsageRecordNotFound)
+		}
+		if err := audit.watch(model.AuditEntityUsageRecord, id); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&old, nil)...); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.UsageRecord{}).Where("id = ?", id).UpdateColumn("deleted_at", trashTime()).Error; err != nil {
+			return err
+		}
+
+		if old.NeedBladeChange {
+			if err := incrementBladeStock(tx, old.BladeID); err != nil {
+				return err
+			}
+		}
+		return renumberBladeUsage(tx, old.RazorID, nil)
+	})
+}
+
+func (g *GormStore) RestoreUsageRecord(id uint) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var record model.UsageRecord
+		if err := g.scoped(tx.Unscoped()).Where("deleted_at IS NOT NULL").First(&record, id).Error; err != nil {
+			return notFound(err, ErrUsageRecordNotFound)
+		}
+		if err := tx.Select("id").First(&model.Razor{}, record.RazorID).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		if err := tx.Select("id").First(&model.Blade{}, record.BladeID).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		if err := audit.watchRestore(model.AuditEntityUsageRecord, id); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&record, nil)...); err != nil {
+			return err
+		}
+
+		if record.NeedBladeChange {
+			if err := decrementBladeStock(tx, record.BladeID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Model(&model.UsageRecord{}).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return renumberBladeUsage(tx, record.RazorID, nil)
+	})
+}
+
+// renumberBladeUsage 重新计算剃须刀全部使用记录的刀片使用次数，只更新有变化的记录。
+// record非空时同步写回其计算结果
+func renumberBladeUsage(tx *gorm.DB, razorID uint, record *model.UsageRecord) error {
+	var records []model.UsageRecord
+	if err := tx.Select("id, blade_id, blade_usage_count, blade_usage_count_override, need_blade_change").
+		Where("razor_id = ?", razorID).
+		Order("usage_time, id").
+		Find(&records).Error; err != nil {
+		return err
+	}
+
+	counts := bladeUsageCounts(records)
+	for _, r := range records {
+		if counts[r.ID] == r.BladeUsageCount {
+			continue
+		}
+		// UpdateColumn不修改updated_at，重新编号不算用户编辑
+		if err := tx.Model(&model.UsageRecord{}).Where("id = ?", r.ID).
+			UpdateColumn("blade_usage_count", counts[r.ID]).Error; err != nil {
+			return err
+		}
+	}
+	if record != nil {
+		record.BladeUsageCount = counts[record.ID]
+	}
+	return nil
+}
+
+func (g *GormStore) RecountBladeInventory(id uint) (*model.Blade, error) {
+	var blade model.Blade
+	err := g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		if err := g.scoped(tx).First(&blade, id).Error; err != nil {
+			return notFound(err, ErrBladeNotFound)
+		}
+		if err := audit.watch(model.AuditEntityBlade, id); err != nil {
+			return err
+		}
+
+		var changes int64
+		if err := tx.Model(&model.UsageRecord{}).
+			Where("blade_id = ? AND need_blade_change = ?", id, true).
+			Count(&changes).Error; err != nil {
+			return err
+		}
+
+		blade.RemainingQuantity = remainingAfterChanges(blade.TotalQuantity, changes)
+		return tx.Model(&blade).Update("remaining_quantity", blade.RemainingQuantity).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &blade, nil
+}
+
+// 统计相关方法
+func (g *GormStore) GetUsageStatistics() (map[string]interface{}, error) {
+	stats := make(map[string]interface{})
+
+	// 总使用次数
+	var totalUsage int64
+	if err := g.scoped(g.db.Model(&model.UsageRecord{})).Count(&totalUsage).Error; err != nil {
+		return nil, err
+	}
+	stats["total_usage"] = totalUsage
+
+	// 剃须刀数量
+	var razorCount int64
+	if err := g.scoped(g.db.Model(&model.Razor{})).Count(&razorCount).Error; err != nil {
+		return nil, err
+	}
+	stats["razor_count"] = razorCount
+
+	// 刀片数量
+	var bladeCount int64
+	if err := g.scoped(g.db.Model(&model.Blade{})).Count(&bladeCount).Error; err != nil {
+		return nil, err
+	}
+	stats["blade_count"] = bladeCount
+
+	// 平均评分
+	var avgRating float64
+	if err := g.scoped(g.db.Model(&model.UsageRecord{})).
+		Where("rating IS NOT NULL").
+		Select("COALESCE(AVG(rating), 0)").
+		Scan(&avgRating).Error; err != nil {
+		return nil, err
+	}
+	stats["average_rating"] = avgRating
+
+	return stats, nil
+}
+
+func (g *GormStore) GetUsageTimeSeries(filter model.UsageRecordFilter, boundaries []time.Time) ([]model.TimeSeriesPoint, error) {
+	points := emptyTimeSeries(boundaries)
+	if len(points) == 0 {
+		return points, nil
+	}
+
+	// 用CASE把记录映射到桶序号，桶边界在Go中按时区计算，SQLite与PostgreSQL通用
+	var bucketExpr strings.Builder
+	args := make([]interface{}, 0, len(points))
+	bucketExpr.WriteString("CASE")
+	for i := range points {
+		bucketExpr.WriteString(fmt.Sprintf(" WHEN usage_time < ? THEN %d", i))
+		args = append(args, points[i].End.UTC())
+	}
+	bucketExpr.WriteString(" END AS bucket")
+
+	sub := g.usageRecordQuery(filter).
+		Where("usage_time >= ? AND usage_time < ?", boundaries[0].UTC(), boundaries[len(boundaries)-1].UTC()).
+		Select(bucketExpr.String()+", blade_id, rating, need_blade_change", args...)
+
+	var rows []struct {
+		Bucket        int
+		ShaveCount    int64
+		AverageRating *float64
+		BladeChanges  int64
+	}
+	err := g.db.Table("(?) AS t", sub).
+		Select("bucket, COUNT(*) AS shave_count, AVG(rating) AS average_rating, " +
+			"SUM(CASE WHEN need_blade_change THEN 1 ELSE 0 END) AS blade_changes").
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if row.Bucket < 0 || row.Bucket >= len(points) {
+			continue
+		}
+		p := &points[row.Bucket]
+		p.ShaveCount = row.ShaveCount
+		p.AverageRating = row.AverageRating
+		p.BladeChanges = row.BladeChanges
+	}
+
+	// 各桶内按刀片统计换刀次数，用于折算花费
+	var changes []struct {
+		Bucket  int
+		BladeID uint
+		Changes int64
+	}
+	err = g.db.Table("(?) AS t", sub).
+		Select("bucket, blade_id, COUNT(*) AS changes").
+		Where("need_blade_change").
+		Group("bucket, blade_id").
+		Scan(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range changes {
+		if row.Bucket < 0 || row.Bucket >= len(points) {
+			continue
+		}
+		p := &points[row.Bucket]
+		if p.ChangesByBlade == nil {
+			p.ChangesByBlade = make(map[uint]int64)
+		}
+		p.ChangesByBlade[row.BladeID] = row.Changes
+	}
+	return points, nil
+}
+
+func (g *GormStore) GetRecentUsageRecords(limit int) ([]model.UsageRecord, error) {
+	var records []model.UsageRecord
+	err := g.scoped(g.db).Preload("Razor").Preload("Blade").
+		Order("usage_time DESC").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// decrementBladeStock 条件扣减一片库存，库存为0时返回ErrInsufficientStock
+func decrementBladeStock(tx *gorm.DB, bladeID uint) error {
+	result := tx.Model(&model.Blade{}).
+		Where("id = ? AND remaining_quantity > 0", bladeID).
+		Updates(map[string]interface{}{
+			"remaining_quantity": gorm.Expr("remaining_quantity - 1"),
+			"updated_at":         time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// incrementBladeStock 归还一片库存，刀片已不存在时忽略
+func incrementBladeStock(tx *gorm.DB, bladeID uint) error {
+	return tx.Model(&model.Blade{}).
+		Where("id = ?", bladeID).
+		Updates(map[string]interface{}{
+			"remaining_quantity": gorm.Expr("remaining_quantity + 1"),
+			"updated_at":         time.Now().UTC(),
+		}).Error
+}
+
+// notFound 将gorm的记录不存在错误转换为仓储层的哨兵错误
+func notFound(err error, sentinel error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return sentinel
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (g *GormStore) CreateAlert(alert *model.Alert) error {
+	alert.UserID = g.owner(alert.UserID)
+	return g.db.Create(alert).Error
+}
+
+func (g *GormStore) GetOpenAlert(bladeID uint) (*model.Alert, error) {
+	var alert model.Alert
+	err := g.scoped(g.db).Where("blade_id = ? AND resolved_at IS NULL", bladeID).
+		Order("id DESC").
+		First(&alert).Error
+	if err != nil {
+		return nil, notFound(err, ErrAlertNotFound)
+	}
+	return &alert, nil
+}
+
+func (g *GormStore) ResolveAlert(id uint, at time.Time) error {
+	result := g.scoped(g.db.Model(&model.Alert{})).
+		Where("id = ? AND resolved_at IS NULL", id).
+		Update("resolved_at", at.UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlertNotFound
+	}
+	return nil
+}
+
+func (g *GormStore) GetAlerts(filter model.AlertFilter, offset, limit int) ([]model.Alert, int64, error) {
+	var alerts []model.Alert
+	var total int64
+
+	if err := g.alertQuery(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := g.alertQuery(filter).
+		Order("created_at DESC").Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&alerts).Error
+	return alerts, total, err
+}
+
+func (g *GormStore) alertQuery(filter model.AlertFilter) *gorm.DB {
+	query := g.scoped(g.db.Model(&model.Alert{}))
+	if filter.BladeID != 0 {
+		query = query.Where("blade_id = ?", filter.BladeID)
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			query = query.Where("resolved_at IS NULL")
+		} else {
+			query = query.Where("resolved_at IS NOT NULL")
+		}
+	}
+	return query
+}
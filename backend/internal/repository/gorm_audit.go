@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"fmt"
+	"razor-blade/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (g *GormStore) WithActor(actor model.AuditActor) Store {
+	return &GormStore{db: g.db, userID: g.userID, actor: actor}
+}
+
+// audited 在事务内执行修改，提交前写入修改涉及实体的审计事件
+func (g *GormStore) audited(fn func(tx *gorm.DB, audit *auditBatch) error) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		audit := newAuditBatch(g.actor, func(entityType string, id uint) (auditSnapshot, error) {
+			return gormAuditSnapshot(tx, entityType, id)
+		})
+		if err := fn(tx, audit); err != nil {
+			return err
+		}
+		events, err := audit.events(time.Now().UTC())
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		return tx.Create(&events).Error
+	})
+}
+
+// gormAuditSnapshot 在事务内读取实体当前的内容，不按用户过滤
+func gormAuditSnapshot(tx *gorm.DB, entityType string, id uint) (auditSnapshot, error) {
+	var (
+		entity interface{}
+		userID *uint
+	)
+	switch entityType {
+	case model.AuditEntityRazor:
+		razor := &model.Razor{}
+		entity, userID = razor, &razor.UserID
+	case model.AuditEntityBlade:
+		blade := &model.Blade{}
+		entity, userID = blade, &blade.UserID
+	case model.AuditEntityUsageRecord:
+		record := &model.UsageRecord{}
+		entity, userID = record, &record.UserID
+	case model.AuditEntityPurchase:
+		purchase := &model.Purchase{}
+		entity, userID = purchase, &purchase.UserID
+	default:
+		return auditSnapshot{}, fmt.Errorf("unknown audit entity type %q", entityType)
+	}
+
+	// 用Find而不是First，实体已删除时不记录为错误
+	result := tx.Limit(1).Find(entity, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return auditSnapshot{}, result.Error
+	}
+
+	var (
+		data map[string]interface{}
+		err  error
+	)
+	switch e := entity.(type) {
+	case *model.Blade:
+		e.CompatibleRazorIDs = []uint{}
+		if err := tx.Model(&model.RazorBladeCompatibility{}).
+			Where("blade_id = ?", id).
+			Where("razor_id IN (?)", tx.Session(&gorm.Session{NewDB: true}).Model(&model.Razor{}).Select("id")).
+			Order("razor_id").
+			Pluck("razor_id", &e.CompatibleRazorIDs).Error; err != nil {
+			return auditSnapshot{}, err
+		}
+		data, err = auditData(e)
+	case *model.UsageRecord:
+		data, err = usageRecordAuditData(e)
+	default:
+		data, err = auditData(e)
+	}
+	if err != nil {
+		return auditSnapshot{}, err
+	}
+	return auditSnapshot{data: data, userID: *userID}, nil
+}
+
+// watchRazorCascade 删除或恢复剃须刀前记录剃须刀和兼容列表随之变化的刀片
+func (g *GormStore) watchRazorCascade(tx *gorm.DB, audit *auditBatch, razorID uint) error {
+	if err := audit.watch(model.AuditEntityRazor, razorID); err != nil {
+		return err
+	}
+	var bladeIDs []uint
+	if err := tx.Model(&model.RazorBladeCompatibility{}).Where("razor_id = ?", razorID).Order("blade_id").Pluck("blade_id", &bladeIDs).Error; err != nil {
+		return err
+	}
+	return audit.watch(model.AuditEntityBlade, bladeIDs...)
+}
+
+func (g *GormStore) GetAuditEvents(filter model.AuditFilter, offset, limit int) ([]model.AuditEvent, int64, error) {
+	var events []model.AuditEvent
+	var total int64
+
+	if err := g.auditQuery(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := g.auditQuery(filter).
+		Order("created_at DESC").Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&events).Error
+	return events, total, err
+}
+
+func (g *GormStore) auditQuery(filter model.AuditFilter) *gorm.DB {
+	query := g.scoped(g.db.Model(&model.AuditEvent{}))
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	return query
+}
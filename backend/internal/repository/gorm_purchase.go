@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// livePurchase 购买对象不在回收站中的购买记录
+const livePurchase = "(razor_id IS NULL OR razor_id IN (SELECT id FROM razors WHERE deleted_at IS NULL)) AND " +
+	"(blade_id IS NULL OR blade_id IN (SELECT id FROM blades WHERE deleted_at IS NULL))"
+
+func (g *GormStore) CreatePurchase(purchase *model.Purchase) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		if err := checkPurchaseItem(g.scoped(tx), purchase); err != nil {
+			return err
+		}
+		if err := watchPurchaseItem(audit, purchase); err != nil {
+			return err
+		}
+		if err := tx.Create(purchase).Error; err != nil {
+			return err
+		}
+		audit.created(model.AuditEntityPurchase, purchase.ID)
+		return syncPurchaseItem(tx, purchase)
+	})
+}
+
+func (g *GormStore) GetPurchaseByID(id uint) (*model.Purchase, error) {
+	var purchase model.Purchase
+	if err := g.scoped(g.db).Where(livePurchase).First(&purchase, id).Error; err != nil {
+		return nil, notFound(err, ErrPurchaseNotFound)
+	}
+	return &purchase, nil
+}
+
+func (g *GormStore) GetPurchases(filter model.PurchaseFilter, offset, limit int) ([]model.Purchase, int64, error) {
+	var purchases []model.Purchase
+	var total int64
+
+	if err := g.purchaseQuery(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := orderBy(g.purchaseQuery(filter), filter.Sort, defaultPurchaseSort).
+		Offset(offset).Limit(limit).
+		Find(&purchases).Error
+	return purchases, total, err
+}
+
+func (g *GormStore) GetAllPurchases(filter model.PurchaseFilter) ([]model.Purchase, error) {
+	var purchases []model.Purchase
+	err := orderBy(g.purchaseQuery(filter), filter.Sort, defaultPurchaseSort).Find(&purchases).Error
+	return purchases, err
+}
+
+func (g *GormStore) UpdatePurchase(purchase *model.Purchase) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var old model.Purchase
+		if err := g.scoped(tx).Where(livePurchase).First(&old, purchase.ID).Error; err != nil {
+			return notFound(err, ErrPurchaseNotFound)
+		}
+		purchase.RazorID, purchase.BladeID = old.RazorID, old.BladeID
+		purchase.UserID = old.UserID
+		purchase.CreatedAt = old.CreatedAt
+		if err := audit.watch(model.AuditEntityPurchase, purchase.ID); err != nil {
+			return err
+		}
+		if err := watchPurchaseItem(audit, purchase); err != nil {
+			return err
+		}
+		if err := tx.Save(purchase).Error; err != nil {
+			return err
+		}
+		return syncPurchaseItem(tx, purchase)
+	})
+}
+
+func (g *GormStore) DeletePurchase(id uint) error {
+	return g.audited(func(tx *gorm.DB, audit *auditBatch) error {
+		var purchase model.Purchase
+		if err := g.scoped(tx).Where(livePurchase).First(&purchase, id).Error; err != nil {
+			return notFound(err, ErrPurchaseNotFound)
+		}
+		if err := audit.watch(model.AuditEntityPurchase, id); err != nil {
+			return err
+		}
+		if err := watchPurchaseItem(audit, &purchase); err != nil {
+			return err
+		}
+		if err := tx.Delete(&purchase).Error; err != nil {
+			return err
+		}
+		return syncPurchaseItem(tx, &purchase)
+	})
+}
+
+func (g *GormStore) purchaseQuery(filter model.PurchaseFilter) *gorm.DB {
+	query := g.scoped(g.db.Model(&model.Purchase{})).Where(livePurchase)
+	if filter.RazorID != 0 {
+		query = query.Where("razor_id = ?", filter.RazorID)
+	}
+	if filter.BladeID != 0 {
+		query = query.Where("blade_id = ?", filter.BladeID)
+	}
+	switch filter.ItemType {
+	case model.PurchaseItemRazor:
+		query = query.Where("razor_id IS NOT NULL")
+	case model.PurchaseItemBlade:
+		query = query.Where("blade_id IS NOT NULL")
+	}
+	if filter.Vendor != "" {
+		query = query.Where("LOWER(vendor) = ?", strings.ToLower(filter.Vendor))
+	}
+	if filter.From != nil {
+		query = query.Where("purchase_date >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("purchase_date < ?", filter.To.UTC())
+	}
+	return query
+}
+
+// checkPurchaseItem 校验购买对象存在，购买记录与购买对象归属同一用户
+func checkPurchaseItem(tx *gorm.DB, purchase *model.Purchase) error {
+	if purchase.RazorID != nil {
+		var razor model.Razor
+		if err := tx.Select("id", "user_id").First(&razor, *purchase.RazorID).Error; err != nil {
+			return notFound(err, ErrRazorNotFound)
+		}
+		purchase.UserID = razor.UserID
+		return nil
+	}
+	var blade model.Blade
+	if err := tx.Select("id", "user_id").First(&blade, *purchase.BladeID).Error; err != nil {
+		return notFound(err, ErrBladeNotFound)
+	}
+	purchase.UserID = blade.UserID
+	return nil
+}
+
+// syncPurchaseItem 刷新购买记录所属剃须刀或刀片的购买信息
+func syncPurchaseItem(tx *gorm.DB, purchase *model.Purchase) error {
+	if purchase.RazorID != nil {
+		return syncRazorPurchases(tx, *purchase.RazorID)
+	}
+	return syncBladePurchases(tx, *purchase.BladeID)
+}
+
+// syncRazorPurchases 用最近一次购买记录刷新剃须刀的购买日期、价格和币种
+func syncRazorPurchases(tx *gorm.DB, razorID uint) error {
+	latest, err := latestPurchase(tx, "razor_id", razorID)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"purchase_date": nil,
+		"price":         nil,
+		"currency":      "",
+		"updated_at":    time.Now().UTC(),
+	}
+	if latest != nil {
+		updates["purchase_date"] = latest.PurchaseDate
+		updates["price"] = latest.UnitPrice
+		updates["currency"] = latest.Currency
+	}
+	return tx.Model(&model.Razor{}).Where("id = ?", razorID).Updates(updates).Error
+}
+
+// syncBladePurchases 重新累加刀片的总数量，剩余数量按总数量的变化调整，
+// 并用最近一次购买记录刷新购买日期、单价和币种
+func syncBladePurchases(tx *gorm.DB, bladeID uint) error {
+	var blade model.Blade
+	if err := tx.Select("id", "total_quantity", "remaining_quantity").First(&blade, bladeID).Error; err != nil {
+		return notFound(err, ErrBladeNotFound)
+	}
+
+	var total int
+	if err := tx.Model(&model.Purchase{}).
+		Where("blade_id = ?", bladeID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error; err != nil {
+		return err
+	}
+	remaining := blade.RemainingQuantity + total - blade.TotalQuantity
+	if remaining < 0 {
+		return ErrInsufficientStock
+	}
+
+	latest, err := latestPurchase(tx, "blade_id", bladeID)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"total_quantity":     total,
+		"remaining_quantity": remaining,
+		"purchase_date":      nil,
+		"unit_price":         nil,
+		"currency":           "",
+		"updated_at":         time.Now().UTC(),
+	}
+	if latest != nil {
+		updates["purchase_date"] = latest.PurchaseDate
+		updates["unit_price"] = latest.UnitPrice
+		updates["currency"] = latest.Currency
+	}
+	return tx.Model(&model.Blade{}).Where("id = ?", bladeID).Updates(updates).Error
+}
+
+// latestPurchase 返回购买日期最晚的一条记录，没有购买记录时返回nil
+func latestPurchase(tx *gorm.DB, column string, id uint) (*model.Purchase, error) {
+	var purchases []model.Purchase
+	err := tx.Where(column+" = ?", id).
+		Order("purchase_date DESC").Order("id DESC").
+		Limit(1).
+		Find(&purchases).Error
+	if err != nil || len(purchases) == 0 {
+		return nil, err
+	}
+	return &purchases[0], nil
+}
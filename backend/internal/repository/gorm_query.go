@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+	"razor-blade/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func (g *GormStore) razorQuery(filter model.RazorFilter) *gorm.DB {
+	query := g.scoped(g.db.Model(&model.Razor{}))
+	if filter.Brand != "" {
+		query = query.Where("LOWER(brand) = ?", strings.ToLower(filter.Brand))
+	}
+	if filter.Model != "" {
+		query = query.Where("LOWER(model) = ?", strings.ToLower(filter.Model))
+	}
+	if filter.Query != "" {
+		query = query.Where(`LOWER(notes) LIKE ? ESCAPE '\'`, likePattern(filter.Query))
+	}
+	return query
+}
+
+func (g *GormStore) bladeQuery(filter model.BladeFilter) *gorm.DB {
+	query := g.scoped(g.db.Model(&model.Blade{}))
+	if filter.Brand != "" {
+		query = query.Where("LOWER(brand) = ?", strings.ToLower(filter.Brand))
+	}
+	if filter.Model != "" {
+		query = query.Where("LOWER(model) = ?", strings.ToLower(filter.Model))
+	}
+	if filter.Query != "" {
+		query = query.Where(`LOWER(notes) LIKE ? ESCAPE '\'`, likePattern(filter.Query))
+	}
+	if filter.LowStockThreshold != nil {
+		query = query.Where("remaining_quantity <= COALESCE(low_stock_threshold, ?)", *filter.LowStockThreshold)
+	}
+	return query
+}
+
+func (g *GormStore) usageRecordQuery(filter model.UsageRecordFilter) *gorm.DB {
+	query := g.scoped(g.db.Model(&model.UsageRecord{}))
+	if filter.RazorID != 0 {
+		query = query.Where("razor_id = ?", filter.RazorID)
+	}
+	if filter.BladeID != 0 {
+		query = query.Where("blade_id = ?", filter.BladeID)
+	}
+	if filter.Query != "" {
+		query = query.Where(`LOWER(experience_text) LIKE ? ESCAPE '\'`, likePattern(filter.Query))
+	}
+	if filter.RatingMin != nil {
+		query = query.Where("rating >= ?", *filter.RatingMin)
+	}
+	if filter.RatingMax != nil {
+		query = query.Where("rating <= ?", *filter.RatingMax)
+	}
+	if filter.From != nil {
+		query = query.Where("usage_time >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("usage_time < ?", filter.To.UTC())
+	}
+	return query
+}
+
+// orderBy 追加排序子句，空值始终排在最后，最后以id兜底保证顺序稳定。
+// 字段名已在Service层通过白名单校验。
+func orderBy(query *gorm.DB, sorts []model.SortField, fallback []model.SortField) *gorm.DB {
+	if len(sorts) == 0 {
+		sorts = fallback
+	}
+	hasID := false
+	for _, s := range sorts {
+		direction := "ASC"
+		if s.Desc {
+			direction = "DESC"
+		}
+		query = query.Order(fmt.Sprintf("%s %s NULLS LAST", s.Field, direction))
+		hasID = hasID || s.Field == "id"
+	}
+	if !hasID {
+		query = query.Order("id ASC")
+	}
+	return query
+}
+
+// likePattern 转义LIKE通配符并转为小写的包含匹配
+func likePattern(q string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(strings.ToLower(q)) + "%"
+}
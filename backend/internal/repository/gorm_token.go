@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (g *GormStore) CreateAPIToken(token *model.APIToken) error {
+	token.UserID = g.owner(token.UserID)
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.APITokenEvent{
+			TokenID:   token.ID,
+			Event:     model.TokenEventCreated,
+			CreatedAt: token.CreatedAt,
+		}).Error
+	})
+}
+
+func (g *GormStore) GetAPITokenByID(id uint) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := g.scoped(g.db).First(&token, id).Error; err != nil {
+		return nil, notFound(err, ErrAPITokenNotFound)
+	}
+	return &token, nil
+}
+
+func (g *GormStore) GetAPITokens() ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	err := g.scoped(g.db).Order("created_at DESC").Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (g *GormStore) RevokeAPIToken(id uint, at time.Time) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		var token model.APIToken
+		if err := g.scoped(tx).First(&token, id).Error; err != nil {
+			return notFound(err, ErrAPITokenNotFound)
+		}
+		if token.RevokedAt != nil {
+			return nil
+		}
+		if err := tx.Model(&token).Update("revoked_at", at.UTC()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.APITokenEvent{
+			TokenID:   token.ID,
+			Event:     model.TokenEventRevoked,
+			CreatedAt: at.UTC(),
+		}).Error
+	})
+}
+
+func (g *GormStore) GetAPITokenByHash(tokenHash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := g.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, notFound(err, ErrAPITokenNotFound)
+	}
+	return &token, nil
+}
+
+func (g *GormStore) RecordAPITokenUse(event *model.APITokenEvent) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.APIToken{}).Where("id = ?", event.TokenID).
+			Update("last_used_at", event.CreatedAt.UTC())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAPITokenNotFound
+		}
+		return tx.Create(event).Error
+	})
+}
+
+func (g *GormStore) GetAPITokenEvents(tokenID uint, offset, limit int) ([]model.APITokenEvent, int64, error) {
+	if err := g.scoped(g.db).Select("id").First(&model.APIToken{}, tokenID).Error; err != nil {
+		return nil, 0, notFound(err, ErrAPITokenNotFound)
+	}
+
+	var events []model.APITokenEvent
+	var total int64
+	if err := g.db.Model(&model.APITokenEvent{}).Where("token_id = ?", tokenID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := g.db.Where("token_id = ?", tokenID).
+		Order("created_at DESC").Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (g *GormStore) GetTrash() (*model.Trash, error) {
+	trash := &model.Trash{
+		Razors:       []model.Razor{},
+		Blades:       []model.Blade{},
+		UsageRecords: []model.UsageRecord{},
+	}
+	if err := g.trashQuery().Find(&trash.Razors).Error; err != nil {
+		return nil, err
+	}
+	if err := g.trashQuery().Find(&trash.Blades).Error; err != nil {
+		return nil, err
+	}
+	ptrs := make([]*model.Blade, len(trash.Blades))
+	for i := range trash.Blades {
+		ptrs[i] = &trash.Blades[i]
+	}
+	if err := g.fillCompatibility(ptrs); err != nil {
+		return nil, err
+	}
+
+	// 使用记录的剃须刀或刀片可能也在回收站中
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	err := g.trashQuery().Preload("Razor", unscoped).Preload("Blade", unscoped).
+		Find(&trash.UsageRecords).Error
+	if err != nil {
+		return nil, err
+	}
+	return trash, nil
+}
+
+// trashQuery 回收站中的数据，按删除时间倒序
+func (g *GormStore) trashQuery() *gorm.DB {
+	return g.scoped(g.db.Unscoped()).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Order("id DESC")
+}
+
+// PurgeTrash 移入回收站时已记录删除事件，彻底删除不再写审计事件。
+// 购买记录、兼容关系和库存告警由外键级联删除
+func (g *GormStore) PurgeTrash(before time.Time) (*model.PurgeResult, error) {
+	result := &model.PurgeResult{}
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		expired := func(m interface{}) *gorm.DB {
+			return g.scoped(tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(m)).
+				Where("deleted_at < ?", before.UTC())
+		}
+
+		// 使用记录引用剃须刀和刀片，先删除
+		res := g.scoped(tx.Unscoped()).
+			Where("deleted_at < ? OR razor_id IN (?) OR blade_id IN (?)", before.UTC(),
+				expired(&model.Razor{}).Select("id"), expired(&model.Blade{}).Select("id")).
+			Delete(&model.UsageRecord{})
+		if res.Error != nil {
+			return res.Error
+		}
+		result.UsageRecords = res.RowsAffected
+
+		res = expired(&model.Blade{}).Delete(&model.Blade{})
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Blades = res.RowsAffected
+
+		res = expired(&model.Razor{}).Delete(&model.Razor{})
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Razors = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (g *GormStore) CreateUser(user *model.User) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&model.User{}).Where("username = ?", user.Username).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrUsernameTaken
+		}
+		var users int64
+		if err := tx.Model(&model.User{}).Count(&users).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if users > 0 {
+			return nil
+		}
+		return adoptUnowned(tx, user.ID)
+	})
+}
+
+// adoptUnowned 把没有所属用户的数据交给指定用户
+func adoptUnowned(tx *gorm.DB, userID uint) error {
+	for _, m := range []interface{}{&model.Razor{}, &model.Blade{}, &model.UsageRecord{}, &model.Purchase{}, &model.Alert{}} {
+		// UpdateColumn不修改updated_at，接管数据不算用户编辑；回收站中的数据一并接管
+		if err := tx.Unscoped().Model(m).Where("user_id = ?", 0).UpdateColumn("user_id", userID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GormStore) GetUserByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := g.db.First(&user, id).Error; err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return &user, nil
+}
+
+func (g *GormStore) GetUserByUsername(username string) (*model.User, error) {
+	var user model.User
+	if err := g.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return &user, nil
+}
+
+func (g *GormStore) CreateSession(session *model.Session) error {
+	return g.db.Create(session).Error
+}
+
+func (g *GormStore) GetSessionUser(tokenHash string, now time.Time) (*model.User, error) {
+	var user model.User
+	err := g.db.Joins("JOIN sessions ON sessions.user_id = users.id").
+		Where("sessions.token_hash = ? AND sessions.expires_at > ?", tokenHash, now.UTC()).
+		First(&user).Error
+	if err != nil {
+		return nil, notFound(err, ErrSessionNotFound)
+	}
+	return &user, nil
+}
+
+func (g *GormStore) DeleteSession(tokenHash string) error {
+	result := g.db.Where("token_hash = ?", tokenHash).Delete(&model.Session{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,1302 @@
+package repository
+
+import (
+	"fmt"
+	"razor-blade/internal/model"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore 基于内存的Store实现，数据随进程退出而丢失。
+// ForUser返回的各个视图共享同一份数据
+type MemoryStore struct {
+	*memoryData
+	userID uint             // 为0时不按用户过滤
+	actor  model.AuditActor // 写入审计事件的操作者
+}
+
+type memoryData struct {
+	memoryTables
+	mu sync.RWMutex
+}
+
+// memoryTables 内存存储的全部数据，Transaction通过整体替换提交修改
+type memoryTables struct {
+	razors            []model.Razor
+	blades            []model.Blade
+	usageRecords      []model.UsageRecord
+	alerts            []model.Alert
+	purchases         []model.Purchase
+	users             []model.User
+	sessions          []model.Session
+	apiTokens         []model.APIToken
+	apiTokenEvents    []model.APITokenEvent
+	auditEvents       []model.AuditEvent
+	nextRazorID       uint
+	nextBladeID       uint
+	nextUsageRecordID uint
+	nextAlertID       uint
+	nextPurchaseID    uint
+	nextUserID        uint
+	nextSessionID     uint
+	nextAPITokenID    uint
+	nextTokenEventID  uint
+	nextAuditEventID  uint
+}
+
+// NewMemoryStore 创建空的内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{memoryData: &memoryData{memoryTables: memoryTables{
+		razors:            make([]model.Razor, 0),
+		blades:            make([]model.Blade, 0),
+		usageRecords:      make([]model.UsageRecord, 0),
+		alerts:            make([]model.Alert, 0),
+		purchases:         make([]model.Purchase, 0),
+		users:             make([]model.User, 0),
+		sessions:          make([]model.Session, 0),
+		apiTokens:         make([]model.APIToken, 0),
+		apiTokenEvents:    make([]model.APITokenEvent, 0),
+		auditEvents:       make([]model.AuditEvent, 0),
+		nextRazorID:       1,
+		nextBladeID:       1,
+		nextUsageRecordID: 1,
+		nextAlertID:       1,
+		nextPurchaseID:    1,
+		nextUserID:        1,
+		nextSessionID:     1,
+		nextAPITokenID:    1,
+		nextTokenEventID:  1,
+		nextAuditEventID:  1,
+	}}}
+}
+
+func (m *MemoryStore) ForUser(userID uint) Store {
+	return &MemoryStore{memoryData: m.memoryData, userID: userID, actor: m.actor}
+}
+
+// Transaction 在数据副本上执行fn，成功后整体替换。执行期间持有写锁，其他读写都会等待
+func (m *MemoryStore) Transaction(fn func(Store) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &memoryData{memoryTables: m.memoryTables.clone()}
+	if err := fn(&MemoryStore{memoryData: tx, userID: m.userID, actor: m.actor}); err != nil {
+		return err
+	}
+	m.memoryTables = tx.memoryTables
+	return nil
+}
+
+// clone 复制各表，修改副本中的数据不影响原表
+func (t memoryTables) clone() memoryTables {
+	t.razors = slices.Clone(t.razors)
+	t.blades = slices.Clone(t.blades)
+	for i := range t.blades {
+		t.blades[i].CompatibleRazorIDs = slices.Clone(t.blades[i].CompatibleRazorIDs)
+	}
+	t.usageRecords = slices.Clone(t.usageRecords)
+	t.alerts = slices.Clone(t.alerts)
+	t.purchases = slices.Clone(t.purchases)
+	t.users = slices.Clone(t.users)
+	t.sessions = slices.Clone(t.sessions)
+	t.apiTokens = slices.Clone(t.apiTokens)
+	t.apiTokenEvents = slices.Clone(t.apiTokenEvents)
+	t.auditEvents = slices.Clone(t.auditEvents)
+	return t
+}
+
+// owns 判断数据是否对当前用户可见
+func (m *MemoryStore) owns(userID uint) bool {
+	return m.userID == 0 || userID == m.userID
+}
+
+// visible 判断数据对当前用户可见且不在回收站中
+func (m *MemoryStore) visible(userID uint, deletedAt gorm.DeletedAt) bool {
+	return m.owns(userID) && !deletedAt.Valid
+}
+
+// owner 返回新建数据的所属用户，不按用户过滤时保留调用方设置的值
+func (m *MemoryStore) owner(userID uint) uint {
+	if m.userID == 0 {
+		return userID
+	}
+	return m.userID
+}
+
+// NewDemoStore 创建预置演示数据的内存存储
+func NewDemoStore() *MemoryStore {
+	m := NewMemoryStore()
+	m.initDemoData()
+	return m
+}
+
+// 初始化演示数据
+func (m *MemoryStore) initDemoData() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	// 添加示例剃须刀
+	m.razors = []model.Razor{
+		{
+			ID:           1,
+			Brand:        "Gillette",
+			Model:        "Fusion 5",
+			PurchaseDate: &now,
+			Price:        func() *float64 { p := 89.9; return &p }(),
+			Notes:        "经典五刀头剃须刀",
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		},
+		{
+			ID:           2,
+			Brand:        "Philips",
+			Model:        "OneBlade Pro",
+			PurchaseDate: &now,
+			Price:        func() *float64 { p := 299.0; return &p }(),
+			Notes:        "电动剃须刀，干湿两用",
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		},
+	}
+
+	// 添加示例刀片
+	m.blades = []model.Blade{
+		{
+			ID:                 1,
+			Brand:              "Gillette",
+			Model:              "Fusion 5 替换刀头",
+			CompatibleRazorIDs: []uint{1},
+			PurchaseDate:       &now,
+			UnitPrice:          func() *float64 { p := 15.9; return &p }(),
+			TotalQuantity:      10, // 总共10个刀头
+			RemainingQuantity:  8,  // 剩余8个刀头
+			Notes:              "原装替换刀头",
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		},
+		{
+			ID:                 2,
+			Brand:              "Philips",
+			Model:              "OneBlade 替换刀头",
+			CompatibleRazorIDs: []uint{2},
+			PurchaseDate:       &now,
+			UnitPrice:          func() *float64 { p := 25.0; return &p }(),
+			TotalQuantity:      5, // 总共5个刀头
+			RemainingQuantity:  4, // 剩余4个刀头
+			Notes:              "OneBlade专用刀头",
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		},
+	}
+
+	// 添加示例使用记录
+	yesterday := now.Add(-24 * time.Hour)
+	m.usageRecords = []model.UsageRecord{
+		{
+			ID:              1,
+			RazorID:         1,
+			BladeID:         1,
+			UsageTime:       yesterday,
+			BladeUsageCount: 5,
+			// 示例刀片在开始记录前已用过4次
+			BladeUsageCountOverride: func() *int { n := 5; return &n }(),
+			Rating:                  func() *int { r := 4; return &r }(),
+			ExperienceText:          "剃得很干净，使用感受不错",
+			CreatedAt:               now,
+			UpdatedAt:               now,
+		},
+	}
+
+	// 与上面剃须刀和刀片的购买信息对应的购买记录
+	id := func(v uint) *uint { return &v }
+	m.purchases = []model.Purchase{
+		{ID: 1, RazorID: id(1), PurchaseDate: now, Quantity: 1, UnitPrice: m.razors[0].Price, CreatedAt: now, UpdatedAt: now},
+		{ID: 2, RazorID: id(2), PurchaseDate: now, Quantity: 1, UnitPrice: m.razors[1].Price, CreatedAt: now, UpdatedAt: now},
+		{ID: 3, BladeID: id(1), PurchaseDate: now, Quantity: 10, UnitPrice: m.blades[0].UnitPrice, CreatedAt: now, UpdatedAt: now},
+		{ID: 4, BladeID: id(2), PurchaseDate: now, Quantity: 5, UnitPrice: m.blades[1].UnitPrice, CreatedAt: now, UpdatedAt: now},
+	}
+
+	m.nextRazorID = 3
+	m.nextBladeID = 3
+	m.nextUsageRecordID = 2
+	m.nextPurchaseID = 5
+}
+
+// Razor相关方法
+func (m *MemoryStore) CreateRazor(razor *model.Razor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	razor.ID = m.nextRazorID
+	m.nextRazorID++
+	razor.UserID = m.owner(razor.UserID)
+	razor.CreatedAt = time.Now()
+	razor.UpdatedAt = time.Now()
+
+	audit := m.newAudit()
+	purchases := razor.Purchases
+	razor.Purchases = nil
+	m.razors = append(m.razors, *razor)
+	audit.created(model.AuditEntityRazor, razor.ID)
+	if len(purchases) > 0 {
+		for i := range purchases {
+			purchases[i].RazorID, purchases[i].BladeID = &razor.ID, nil
+			purchases[i].UserID = razor.UserID
+			m.insertPurchase(&purchases[i])
+			audit.created(model.AuditEntityPurchase, purchases[i].ID)
+		}
+		m.syncRazorPurchases(razor.ID)
+		*razor = m.razors[len(m.razors)-1]
+		razor.Purchases = purchases
+	}
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) GetRazorByID(id uint) (*model.Razor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if idx := m.findRazor(id); idx >= 0 {
+		razor := m.razors[idx]
+		return &razor, nil
+	}
+	return nil, ErrRazorNotFound
+}
+
+func (m *MemoryStore) GetRazors(filter model.RazorFilter, offset, limit int) ([]model.Razor, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := m.matchedRazors(filter)
+	return paginate(matched, offset, limit), int64(len(matched)), nil
+}
+
+func (m *MemoryStore) GetAllRazors(filter model.RazorFilter) ([]model.Razor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.matchedRazors(filter), nil
+}
+
+// matchedRazors 返回满足条件并排好序的剃须刀副本，调用方需持有锁
+func (m *MemoryStore) matchedRazors(filter model.RazorFilter) []model.Razor {
+	matched := make([]model.Razor, 0)
+	for i := range m.razors {
+		if m.visible(m.razors[i].UserID, m.razors[i].DeletedAt) && matchRazor(&m.razors[i], filter) {
+			matched = append(matched, m.razors[i])
+		}
+	}
+	sortByFields(len(matched),
+		func(i, j int) { matched[i], matched[j] = matched[j], matched[i] },
+		func(i int, field string) interface{} { return razorField(&matched[i], field) },
+		filter.Sort, defaultRazorSort)
+	return matched
+}
+
+func (m *MemoryStore) UpdateRazor(razor *model.Razor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findRazor(razor.ID)
+	if idx < 0 {
+		return ErrRazorNotFound
+	}
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityRazor, razor.ID); err != nil {
+		return err
+	}
+	razor.UserID = m.razors[idx].UserID
+	razor.CreatedAt = m.razors[idx].CreatedAt
+	razor.UpdatedAt = time.Now()
+	stored := *razor
+	stored.UsageRecords = nil
+	stored.Purchases = nil
+	m.razors[idx] = stored
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) DeleteRazor(id uint, opts model.DeleteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findRazor(id)
+	if idx < 0 {
+		return ErrRazorNotFound
+	}
+	audit := m.newAudit()
+	if err := m.watchRazorCascade(audit, id); err != nil {
+		return err
+	}
+	switch {
+	case opts.ReassignTo != 0:
+		if err := m.reassignRazorUsage(audit, &m.razors[idx], opts.ReassignTo); err != nil {
+			return err
+		}
+	case !opts.Cascade:
+		report := &model.DependencyReport{Entity: model.AuditEntityRazor, ID: id}
+		for i := range m.usageRecords {
+			if m.usageRecords[i].RazorID == id && !m.usageRecords[i].DeletedAt.Valid {
+				report.UsageRecordIDs = append(report.UsageRecordIDs, m.usageRecords[i].ID)
+			}
+		}
+		for i := range m.blades {
+			if containsID(m.blades[i].CompatibleRazorIDs, id) && !m.blades[i].DeletedAt.Valid {
+				report.CompatibleBladeIDs = append(report.CompatibleBladeIDs, m.blades[i].ID)
+			}
+		}
+		if report.HasDependents() {
+			return &DependencyError{Err: ErrRazorInUse, Report: report}
+		}
+	}
+	at := trashTime()
+	if err := m.trashUsageRecords(audit, func(r *model.UsageRecord) bool { return r.RazorID == id }, at); err != nil {
+		return err
+	}
+	m.razors[idx].DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) RestoreRazor(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findTrashedRazor(id)
+	if idx < 0 {
+		return ErrRazorNotFound
+	}
+	audit := m.newAudit()
+	if err := m.watchRazorCascade(audit, id); err != nil {
+		return err
+	}
+	if err := audit.watchRestore(model.AuditEntityRazor, id); err != nil {
+		return err
+	}
+	at := m.razors[idx].DeletedAt.Time
+	m.razors[idx].DeletedAt = gorm.DeletedAt{}
+	err := m.restoreUsageRecords(audit, func(r *model.UsageRecord) bool { return r.RazorID == id }, at)
+	if err != nil {
+		m.razors[idx].DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+		return err
+	}
+	return m.commitAudit(audit)
+}
+
+// Blade相关方法
+func (m *MemoryStore) CreateBlade(blade *model.Blade) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkRazorsExist(blade.CompatibleRazorIDs); err != nil {
+		return err
+	}
+
+	blade.ID = m.nextBladeID
+	m.nextBladeID++
+	blade.UserID = m.owner(blade.UserID)
+	blade.CreatedAt = time.Now()
+	blade.UpdatedAt = time.Now()
+	blade.CompatibleRazorIDs = uniqueIDs(blade.CompatibleRazorIDs)
+
+	audit := m.newAudit()
+	m.blades = append(m.blades, cloneBlade(*blade))
+	audit.created(model.AuditEntityBlade, blade.ID)
+	if len(blade.Purchases) > 0 {
+		for i := range blade.Purchases {
+			blade.Purchases[i].RazorID, blade.Purchases[i].BladeID = nil, &blade.ID
+			blade.Purchases[i].UserID = blade.UserID
+			m.insertPurchase(&blade.Purchases[i])
+			audit.created(model.AuditEntityPurchase, blade.Purchases[i].ID)
+		}
+		if err := m.syncBladePurchases(blade.ID); err != nil {
+			return err
+		}
+		purchases := blade.Purchases
+		*blade = cloneBlade(m.blades[len(m.blades)-1])
+		blade.Purchases = purchases
+	}
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) GetBladeByID(id uint) (*model.Blade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if idx := m.findBlade(id); idx >= 0 {
+		blade := m.liveBlade(m.blades[idx])
+		return &blade, nil
+	}
+	return nil, ErrBladeNotFound
+}
+
+func (m *MemoryStore) GetBlades(filter model.BladeFilter, offset, limit int) ([]model.Blade, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := m.matchedBlades(filter)
+	return paginate(matched, offset, limit), int64(len(matched)), nil
+}
+
+func (m *MemoryStore) GetAllBlades(filter model.BladeFilter) ([]model.Blade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.matchedBlades(filter), nil
+}
+
+// matchedBlades 返回满足条件并排好序的刀片副本，调用方需持有锁
+func (m *MemoryStore) matchedBlades(filter model.BladeFilter) []model.Blade {
+	matched := make([]model.Blade, 0)
+	for i := range m.blades {
+		if m.visible(m.blades[i].UserID, m.blades[i].DeletedAt) && matchBlade(&m.blades[i], filter) {
+			matched = append(matched, m.liveBlade(m.blades[i]))
+		}
+	}
+	sortByFields(len(matched),
+		func(i, j int) { matched[i], matched[j] = matched[j], matched[i] },
+		func(i int, field string) interface{} { return bladeField(&matched[i], field) },
+		filter.Sort, defaultBladeSort)
+	return matched
+}
+
+func (m *MemoryStore) UpdateBlade(blade *model.Blade) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findBlade(blade.ID)
+	if idx < 0 {
+		return ErrBladeNotFound
+	}
+	if err := m.checkRazorsExist(blade.CompatibleRazorIDs); err != nil {
+		return err
+	}
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityBlade, blade.ID); err != nil {
+		return err
+	}
+	blade.UserID = m.blades[idx].UserID
+	blade.CreatedAt = m.blades[idx].CreatedAt
+	blade.UpdatedAt = time.Now()
+	blade.CompatibleRazorIDs = uniqueIDs(blade.CompatibleRazorIDs)
+	stored := cloneBlade(*blade)
+	stored.UsageRecords = nil
+	// 与回收站中剃须刀的兼容关系保留
+	for _, razorID := range m.blades[idx].CompatibleRazorIDs {
+		if m.findTrashedRazor(razorID) >= 0 {
+			stored.CompatibleRazorIDs = append(stored.CompatibleRazorIDs, razorID)
+		}
+	}
+	m.blades[idx] = stored
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) GetCompatibleBlades(razorID uint) ([]model.Blade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findRazor(razorID) < 0 {
+		return nil, ErrRazorNotFound
+	}
+	blades := make([]model.Blade, 0)
+	for _, blade := range m.blades {
+		if m.visible(blade.UserID, blade.DeletedAt) && containsID(blade.CompatibleRazorIDs, razorID) {
+			blades = append(blades, m.liveBlade(blade))
+		}
+	}
+	return blades, nil
+}
+
+func (m *MemoryStore) IsBladeCompatible(razorID, bladeID uint) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	idx := m.findBlade(bladeID)
+	if idx < 0 {
+		return false, nil
+	}
+	return containsID(m.blades[idx].CompatibleRazorIDs, razorID) && m.findRazor(razorID) >= 0, nil
+}
+
+// 校验引用的剃须刀都存在，调用方需持有锁
+func (m *MemoryStore) checkRazorsExist(razorIDs []uint) error {
+	for _, id := range razorIDs {
+		if m.findRazor(id) < 0 {
+			return fmt.Errorf("%w: %d", ErrRazorNotFound, id)
+		}
+	}
+	return nil
+}
+
+// cloneBlade 复制刀片，避免调用方与存储共享切片
+func cloneBlade(blade model.Blade) model.Blade {
+	blade.CompatibleRazorIDs = append([]uint{}, blade.CompatibleRazorIDs...)
+	blade.Purchases = nil
+	return blade
+}
+
+// liveBlade 复制刀片，兼容列表不包含回收站中的剃须刀，调用方需持有锁
+func (m *MemoryStore) liveBlade(blade model.Blade) model.Blade {
+	blade = cloneBlade(blade)
+	ids := blade.CompatibleRazorIDs[:0]
+	for _, razorID := range blade.CompatibleRazorIDs {
+		if m.findTrashedRazor(razorID) < 0 {
+			ids = append(ids, razorID)
+		}
+	}
+	blade.CompatibleRazorIDs = ids
+	return blade
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStore) DeleteBlade(id uint, opts model.DeleteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findBlade(id)
+	if idx < 0 {
+		return ErrBladeNotFound
+	}
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityBlade, id); err != nil {
+		return err
+	}
+	switch {
+	case opts.ReassignTo != 0:
+		if err := m.reassignBladeUsage(audit, idx, opts.ReassignTo); err != nil {
+			return err
+		}
+	case !opts.Cascade:
+		report := &model.DependencyReport{Entity: model.AuditEntityBlade, ID: id}
+		for i := range m.usageRecords {
+			if m.usageRecords[i].BladeID == id && !m.usageRecords[i].DeletedAt.Valid {
+				report.UsageRecordIDs = append(report.UsageRecordIDs, m.usageRecords[i].ID)
+			}
+		}
+		if report.HasDependents() {
+			return &DependencyError{Err: ErrBladeInUse, Report: report}
+		}
+	}
+	// 先删除使用记录，归还的库存随刀片一起保留在回收站中
+	at := trashTime()
+	if err := m.trashUsageRecords(audit, func(r *model.UsageRecord) bool { return r.BladeID == id }, at); err != nil {
+		return err
+	}
+	m.blades[idx].DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) RestoreBlade(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findTrashedBlade(id)
+	if idx < 0 {
+		return ErrBladeNotFound
+	}
+	audit := m.newAudit()
+	if err := audit.watchRestore(model.AuditEntityBlade, id); err != nil {
+		return err
+	}
+	at := m.blades[idx].DeletedAt.Time
+	m.blades[idx].DeletedAt = gorm.DeletedAt{}
+	err := m.restoreUsageRecords(audit, func(r *model.UsageRecord) bool { return r.BladeID == id }, at)
+	if err != nil {
+		m.blades[idx].DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+		return err
+	}
+	return m.commitAudit(audit)
+}
+
+// reassignRazorUsage 把剃须刀的使用记录转移到同一用户的另一剃须刀，调用方需持有写锁
+func (m *MemoryStore) reassignRazorUsage(audit *auditBatch, razor *model.Razor, targetID uint) error {
+	target := m.findRazor(targetID)
+	if target < 0 || targetID == razor.ID || m.razors[target].UserID != razor.UserID {
+		return ErrReassignTargetNotFound
+	}
+	now := time.Now()
+	for i := range m.usageRecords {
+		record := &m.usageRecords[i]
+		if record.RazorID != razor.ID || record.DeletedAt.Valid {
+			continue
+		}
+		if err := audit.watch(model.AuditEntityUsageRecord, record.ID); err != nil {
+			return err
+		}
+		record.RazorID = targetID
+		record.UpdatedAt = now
+	}
+	m.renumberBladeUsage(targetID, nil)
+	return nil
+}
+
+// reassignBladeUsage 把刀片的使用记录转移到同一用户的另一刀片，换过刀片的记录改为扣减目标刀片的库存，
+// 库存不足时返回ErrInsufficientStock且不做修改，调用方需持有写锁
+func (m *MemoryStore) reassignBladeUsage(audit *auditBatch, idx int, targetID uint) error {
+	blade := &m.blades[idx]
+	target := m.findBlade(targetID)
+	if target < 0 || targetID == blade.ID || m.blades[target].UserID != blade.UserID {
+		return ErrReassignTargetNotFound
+	}
+	var indexes []int
+	changes := 0
+	for i := range m.usageRecords {
+		if m.usageRecords[i].BladeID == blade.ID && !m.usageRecords[i].DeletedAt.Valid {
+			indexes = append(indexes, i)
+			if m.usageRecords[i].NeedBladeChange {
+				changes++
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	if m.blades[target].RemainingQuantity < changes {
+		return ErrInsufficientStock
+	}
+	if err := audit.watch(model.AuditEntityBlade, targetID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var razorIDs []uint
+	for _, i := range indexes {
+		record := &m.usageRecords[i]
+		if err := audit.watch(model.AuditEntityUsageRecord, record.ID); err != nil {
+			return err
+		}
+		record.BladeID = targetID
+		record.UpdatedAt = now
+		razorIDs = append(razorIDs, record.RazorID)
+	}
+	if changes > 0 {
+		m.blades[target].RemainingQuantity -= changes
+		m.blades[target].UpdatedAt = now
+		blade.RemainingQuantity += changes
+		blade.UpdatedAt = now
+	}
+	for _, razorID := range uniqueIDs(razorIDs) {
+		m.renumberBladeUsage(razorID, nil)
+	}
+	return nil
+}
+
+// trashUsageRecords 把匹配的使用记录以相同的删除时间移入回收站，归还换刀扣减的库存，调用方需持有写锁
+func (m *MemoryStore) trashUsageRecords(audit *auditBatch, match func(*model.UsageRecord) bool, at time.Time) error {
+	var razorIDs []uint
+	for i := range m.usageRecords {
+		record := &m.usageRecords[i]
+		if record.DeletedAt.Valid || !match(record) {
+			continue
+		}
+		if err := audit.watch(model.AuditEntityUsageRecord, record.ID); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(record, nil)...); err != nil {
+			return err
+		}
+		if record.NeedBladeChange {
+			if bladeIdx := m.findBlade(record.BladeID); bladeIdx >= 0 {
+				m.blades[bladeIdx].RemainingQuantity++
+				m.blades[bladeIdx].UpdatedAt = time.Now()
+			}
+		}
+		record.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+		razorIDs = append(razorIDs, record.RazorID)
+	}
+	for _, razorID := range uniqueIDs(razorIDs) {
+		m.renumberBladeUsage(razorID, nil)
+	}
+	return nil
+}
+
+// restoreUsageRecords 恢复与剃须刀或刀片一起删除的使用记录，另一端仍在回收站中的不恢复，
+// 库存不足时返回ErrInsufficientStock且不做修改，调用方需持有写锁
+func (m *MemoryStore) restoreUsageRecords(audit *auditBatch, match func(*model.UsageRecord) bool, at time.Time) error {
+	var indexes []int
+	needed := make(map[uint]int)
+	for i := range m.usageRecords {
+		record := &m.usageRecords[i]
+		if !record.DeletedAt.Valid || !record.DeletedAt.Time.Equal(at) || !match(record) ||
+			m.findRazor(record.RazorID) < 0 || m.findBlade(record.BladeID) < 0 {
+			continue
+		}
+		indexes = append(indexes, i)
+		if record.NeedBladeChange {
+			needed[record.BladeID]++
+		}
+	}
+	for bladeID, n := range needed {
+		if m.blades[m.findBlade(bladeID)].RemainingQuantity < n {
+			return ErrInsufficientStock
+		}
+	}
+
+	var razorIDs []uint
+	for _, i := range indexes {
+		record := &m.usageRecords[i]
+		if err := audit.watchRestore(model.AuditEntityUsageRecord, record.ID); err != nil {
+			return err
+		}
+		if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(record, nil)...); err != nil {
+			return err
+		}
+	}
+	for _, i := range indexes {
+		record := &m.usageRecords[i]
+		if record.NeedBladeChange {
+			bladeIdx := m.findBlade(record.BladeID)
+			m.blades[bladeIdx].RemainingQuantity--
+			m.blades[bladeIdx].UpdatedAt = time.Now()
+		}
+		record.DeletedAt = gorm.DeletedAt{}
+		razorIDs = append(razorIDs, record.RazorID)
+	}
+	for _, razorID := range uniqueIDs(razorIDs) {
+		m.renumberBladeUsage(razorID, nil)
+	}
+	return nil
+}
+
+// UsageRecord相关方法
+func (m *MemoryStore) CreateUsageRecord(record *model.UsageRecord) error {
+	// 整个流程在同一把锁内完成
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	razorIdx := m.findRazor(record.RazorID)
+	if razorIdx < 0 {
+		return ErrRazorNotFound
+	}
+	bladeIdx := m.findBlade(record.BladeID)
+	if bladeIdx < 0 {
+		return ErrBladeNotFound
+	}
+
+	now := time.Now()
+	audit := m.newAudit()
+	if record.NeedBladeChange {
+		if m.blades[bladeIdx].RemainingQuantity <= 0 {
+			return ErrInsufficientStock
+		}
+		if err := audit.watch(model.AuditEntityBlade, record.BladeID); err != nil {
+			return err
+		}
+		m.blades[bladeIdx].RemainingQuantity--
+		m.blades[bladeIdx].UpdatedAt = now
+	}
+
+	record.ID = m.nextUsageRecordID
+	m.nextUsageRecordID++
+	record.UserID = m.razors[razorIdx].UserID
+	record.CreatedAt = now
+	record.UpdatedAt = now
+
+	m.usageRecords = append(m.usageRecords, *record)
+	m.renumberBladeUsage(record.RazorID, record)
+	audit.created(model.AuditEntityUsageRecord, record.ID)
+	return m.commitAudit(audit)
+}
+
+// 查找剃须刀下标，调用方需持有锁
+func (m *MemoryStore) findRazor(id uint) int {
+	for i := range m.razors {
+		if m.razors[i].ID == id && m.visible(m.razors[i].UserID, m.razors[i].DeletedAt) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 查找刀片下标，调用方需持有锁
+func (m *MemoryStore) findBlade(id uint) int {
+	for i := range m.blades {
+		if m.blades[i].ID == id && m.visible(m.blades[i].UserID, m.blades[i].DeletedAt) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 查找回收站中剃须刀的下标，调用方需持有锁
+func (m *MemoryStore) findTrashedRazor(id uint) int {
+	for i := range m.razors {
+		if m.razors[i].ID == id && m.owns(m.razors[i].UserID) && m.razors[i].DeletedAt.Valid {
+			return i
+		}
+	}
+	return -1
+}
+
+// 查找回收站中刀片的下标，调用方需持有锁
+func (m *MemoryStore) findTrashedBlade(id uint) int {
+	for i := range m.blades {
+		if m.blades[i].ID == id && m.owns(m.blades[i].UserID) && m.blades[i].DeletedAt.Valid {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MemoryStore) GetUsageRecordByID(id uint) (*model.UsageRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	idx := m.findUsageRecord(id)
+	if idx < 0 {
+		return nil, ErrUsageRecordNotFound
+	}
+	// 创建副本并填充关联对象
+	result := m.usageRecords[idx]
+	m.fillAssociations(&result)
+	return &result, nil
+}
+
+func (m *MemoryStore) GetUsageRecords(filter model.UsageRecordFilter, offset, limit int) ([]model.UsageRecord, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := make([]model.UsageRecord, 0)
+	for i := range m.usageRecords {
+		if m.visible(m.usageRecords[i].UserID, m.usageRecords[i].DeletedAt) && matchUsageRecord(&m.usageRecords[i], filter) {
+			matched = append(matched, m.usageRecords[i])
+		}
+	}
+	sortByFields(len(matched),
+		func(i, j int) { matched[i], matched[j] = matched[j], matched[i] },
+		func(i int, field string) interface{} { return usageRecordField(&matched[i], field) },
+		filter.Sort, defaultUsageRecordSort)
+
+	records := paginate(matched, offset, limit)
+	for i := range records {
+		m.fillAssociations(&records[i])
+	}
+	return records, int64(len(matched)), nil
+}
+
+func (m *MemoryStore) GetAllUsageRecords(filter model.UsageRecordFilter) ([]model.UsageRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]model.UsageRecord, 0)
+	for i := range m.usageRecords {
+		if m.visible(m.usageRecords[i].UserID, m.usageRecords[i].DeletedAt) && matchUsageRecord(&m.usageRecords[i], filter) {
+			records = append(records, m.usageRecords[i])
+		}
+	}
+	sortByFields(len(records),
+		func(i, j int) { records[i], records[j] = records[j], records[i] },
+		func(i int, field string) interface{} { return usageRecordField(&records[i], field) },
+		filter.Sort, defaultUsageRecordSort)
+	return records, nil
+}
+
+func (m *MemoryStore) GetUsageRecordsByCursor(filter model.UsageRecordFilter, cursor model.UsageRecordCursor, limit int) ([]model.UsageRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scanDesc := cursor.Desc != cursor.Backward
+	matched := make([]model.UsageRecord, 0)
+	for i := range m.usageRecords {
+		r := &m.usageRecords[i]
+		if !m.visible(r.UserID, r.DeletedAt) || !matchUsageRecord(r, filter) {
+			continue
+		}
+		if cursor.ID != 0 && !beyondCursor(r, cursor, scanDesc) {
+			continue
+		}
+		matched = append(matched, *r)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := &matched[i], &matched[j]
+		if !a.UsageTime.Equal(b.UsageTime) {
+			return a.UsageTime.Before(b.UsageTime) != scanDesc
+		}
+		return (a.ID < b.ID) != scanDesc
+	})
+
+	records := paginate(matched, 0, limit)
+	if cursor.Backward {
+		reverse(records)
+	}
+	for i := range records {
+		m.fillAssociations(&records[i])
+	}
+	return records, nil
+}
+
+// beyondCursor 判断记录是否位于游标之后（按扫描方向）
+func beyondCursor(r *model.UsageRecord, cursor model.UsageRecordCursor, scanDesc bool) bool {
+	if !r.UsageTime.Equal(cursor.UsageTime) {
+		return r.UsageTime.After(cursor.UsageTime) != scanDesc
+	}
+	return r.ID != cursor.ID && (r.ID > cursor.ID) != scanDesc
+}
+
+// paginate 返回offset/limit对应的切片
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+// 返回当前用户按usage_time倒序排列的使用记录副本，调用方需持有锁
+func (m *MemoryStore) sortedUsageRecords() []model.UsageRecord {
+	records := make([]model.UsageRecord, 0, len(m.usageRecords))
+	for _, record := range m.usageRecords {
+		if m.visible(record.UserID, record.DeletedAt) {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].UsageTime.After(records[j].UsageTime)
+	})
+	return records
+}
+
+// 填充使用记录关联的Razor和Blade对象，调用方需持有锁
+func (m *MemoryStore) fillAssociations(record *model.UsageRecord) {
+	if idx := m.findRazor(record.RazorID); idx >= 0 {
+		record.Razor = m.razors[idx]
+	}
+	if idx := m.findBlade(record.BladeID); idx >= 0 {
+		// 与SQL实现的Preload一致，关联刀片不带兼容列表
+		record.Blade = m.blades[idx]
+		record.Blade.CompatibleRazorIDs = nil
+	}
+}
+
+func (m *MemoryStore) UpdateUsageRecord(record *model.UsageRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findUsageRecord(record.ID)
+	if idx < 0 {
+		return ErrUsageRecordNotFound
+	}
+	if m.findRazor(record.RazorID) < 0 {
+		return ErrRazorNotFound
+	}
+	newBladeIdx := m.findBlade(record.BladeID)
+	if newBladeIdx < 0 {
+		return ErrBladeNotFound
+	}
+
+	old := m.usageRecords[idx]
+	now := time.Now()
+	// 先校验再修改，避免库存不足时留下部分归还
+	oldBladeIdx := -1
+	if old.NeedBladeChange {
+		oldBladeIdx = m.findBlade(old.BladeID)
+	}
+	if record.NeedBladeChange {
+		available := m.blades[newBladeIdx].RemainingQuantity
+		if oldBladeIdx == newBladeIdx {
+			available++
+		}
+		if available <= 0 {
+			return ErrInsufficientStock
+		}
+	}
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityUsageRecord, record.ID); err != nil {
+		return err
+	}
+	if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&old, record)...); err != nil {
+		return err
+	}
+	if oldBladeIdx >= 0 {
+		m.blades[oldBladeIdx].RemainingQuantity++
+		m.blades[oldBladeIdx].UpdatedAt = now
+	}
+	if record.NeedBladeChange {
+		m.blades[newBladeIdx].RemainingQuantity--
+		m.blades[newBladeIdx].UpdatedAt = now
+	}
+
+	record.UserID = old.UserID
+	record.CreatedAt = old.CreatedAt
+	record.UpdatedAt = now
+	stored := *record
+	stored.Razor = model.Razor{}
+	stored.Blade = model.Blade{}
+	m.usageRecords[idx] = stored
+	if old.RazorID != record.RazorID {
+		m.renumberBladeUsage(old.RazorID, nil)
+	}
+	m.renumberBladeUsage(record.RazorID, record)
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) DeleteUsageRecord(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findUsageRecord(id)
+	if idx < 0 {
+		return ErrUsageRecordNotFound
+	}
+
+	old := m.usageRecords[idx]
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityUsageRecord, id); err != nil {
+		return err
+	}
+	if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&old, nil)...); err != nil {
+		return err
+	}
+	if old.NeedBladeChange {
+		if bladeIdx := m.findBlade(old.BladeID); bladeIdx >= 0 {
+			m.blades[bladeIdx].RemainingQuantity++
+			m.blades[bladeIdx].UpdatedAt = time.Now()
+		}
+	}
+
+	m.usageRecords[idx].DeletedAt = gorm.DeletedAt{Time: trashTime(), Valid: true}
+	m.renumberBladeUsage(old.RazorID, nil)
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) RestoreUsageRecord(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findTrashedUsageRecord(id)
+	if idx < 0 {
+		return ErrUsageRecordNotFound
+	}
+	record := m.usageRecords[idx]
+	if m.findRazor(record.RazorID) < 0 {
+		return ErrRazorNotFound
+	}
+	bladeIdx := m.findBlade(record.BladeID)
+	if bladeIdx < 0 {
+		return ErrBladeNotFound
+	}
+	if record.NeedBladeChange && m.blades[bladeIdx].RemainingQuantity <= 0 {
+		return ErrInsufficientStock
+	}
+
+	audit := m.newAudit()
+	if err := audit.watchRestore(model.AuditEntityUsageRecord, id); err != nil {
+		return err
+	}
+	if err := audit.watch(model.AuditEntityBlade, stockChangedBlades(&record, nil)...); err != nil {
+		return err
+	}
+	if record.NeedBladeChange {
+		m.blades[bladeIdx].RemainingQuantity--
+		m.blades[bladeIdx].UpdatedAt = time.Now()
+	}
+	m.usageRecords[idx].DeletedAt = gorm.DeletedAt{}
+	m.renumberBladeUsage(record.RazorID, nil)
+	return m.commitAudit(audit)
+}
+
+// renumberBladeUsage 重新计算剃须刀全部使用记录的刀片使用次数，调用方需持有写锁。
+// record非空时同步写回其计算结果
+func (m *MemoryStore) renumberBladeUsage(razorID uint, record *model.UsageRecord) {
+	var indexes []int
+	for i := range m.usageRecords {
+		if m.usageRecords[i].RazorID == razorID && !m.usageRecords[i].DeletedAt.Valid {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Slice(indexes, func(a, b int) bool {
+		ra, rb := &m.usageRecords[indexes[a]], &m.usageRecords[indexes[b]]
+		if !ra.UsageTime.Equal(rb.UsageTime) {
+			return ra.UsageTime.Before(rb.UsageTime)
+		}
+		return ra.ID < rb.ID
+	})
+
+	timeline := make([]model.UsageRecord, len(indexes))
+	for i, idx := range indexes {
+		timeline[i] = m.usageRecords[idx]
+	}
+	counts := bladeUsageCounts(timeline)
+	for _, idx := range indexes {
+		m.usageRecords[idx].BladeUsageCount = counts[m.usageRecords[idx].ID]
+	}
+	if record != nil {
+		record.BladeUsageCount = counts[record.ID]
+	}
+}
+
+func (m *MemoryStore) RecountBladeInventory(id uint) (*model.Blade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findBlade(id)
+	if idx < 0 {
+		return nil, ErrBladeNotFound
+	}
+
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityBlade, id); err != nil {
+		return nil, err
+	}
+	changes := 0
+	for _, record := range m.usageRecords {
+		if record.BladeID == id && record.NeedBladeChange && !record.DeletedAt.Valid {
+			changes++
+		}
+	}
+
+	blade := &m.blades[idx]
+	blade.RemainingQuantity = remainingAfterChanges(blade.TotalQuantity, int64(changes))
+	blade.UpdatedAt = time.Now()
+	if err := m.commitAudit(audit); err != nil {
+		return nil, err
+	}
+
+	result := *blade
+	return &result, nil
+}
+
+// 查找使用记录下标，调用方需持有锁
+func (m *MemoryStore) findUsageRecord(id uint) int {
+	for i := range m.usageRecords {
+		if m.usageRecords[i].ID == id && m.visible(m.usageRecords[i].UserID, m.usageRecords[i].DeletedAt) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 查找回收站中使用记录的下标，调用方需持有锁
+func (m *MemoryStore) findTrashedUsageRecord(id uint) int {
+	for i := range m.usageRecords {
+		if m.usageRecords[i].ID == id && m.owns(m.usageRecords[i].UserID) && m.usageRecords[i].DeletedAt.Valid {
+			return i
+		}
+	}
+	return -1
+}
+
+// 统计相关方法
+func (m *MemoryStore) GetUsageStatistics() (map[string]interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[string]interface{})
+
+	// 总使用次数
+	var totalUsage int64
+	for _, record := range m.usageRecords {
+		if m.visible(record.UserID, record.DeletedAt) {
+			totalUsage++
+		}
+	}
+	stats["total_usage"] = totalUsage
+
+	// 剃须刀数量
+	var razorCount int64
+	for _, razor := range m.razors {
+		if m.visible(razor.UserID, razor.DeletedAt) {
+			razorCount++
+		}
+	}
+	stats["razor_count"] = razorCount
+
+	// 刀片数量
+	var bladeCount int64
+	for _, blade := range m.blades {
+		if m.visible(blade.UserID, blade.DeletedAt) {
+			bladeCount++
+		}
+	}
+	stats["blade_count"] = bladeCount
+
+	// 计算平均评分
+	var totalRating float64
+	var ratingCount int
+	for _, record := range m.usageRecords {
+		if m.visible(record.UserID, record.DeletedAt) && record.Rating != nil {
+			totalRating += float64(*record.Rating)
+			ratingCount++
+		}
+	}
+
+	var avgRating float64
+	if ratingCount > 0 {
+		avgRating = totalRating / float64(ratingCount)
+	}
+	stats["average_rating"] = avgRating
+
+	return stats, nil
+}
+
+func (m *MemoryStore) GetUsageTimeSeries(filter model.UsageRecordFilter, boundaries []time.Time) ([]model.TimeSeriesPoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	points := emptyTimeSeries(boundaries)
+	ratingSums := make([]float64, len(points))
+	ratingCounts := make([]int, len(points))
+	for i := range m.usageRecords {
+		record := &m.usageRecords[i]
+		if !m.visible(record.UserID, record.DeletedAt) || !matchUsageRecord(record, filter) {
+			continue
+		}
+		// 第一个End晚于使用时间的桶
+		idx := sort.Search(len(points), func(j int) bool { return points[j].End.After(record.UsageTime) })
+		if idx == len(points) || record.UsageTime.Before(points[idx].Start) {
+			continue
+		}
+
+		p := &points[idx]
+		p.ShaveCount++
+		if record.Rating != nil {
+			ratingSums[idx] += float64(*record.Rating)
+			ratingCounts[idx]++
+		}
+		if record.NeedBladeChange {
+			p.BladeChanges++
+			if p.ChangesByBlade == nil {
+				p.ChangesByBlade = make(map[uint]int64)
+			}
+			p.ChangesByBlade[record.BladeID]++
+		}
+	}
+
+	for i := range points {
+		if ratingCounts[i] > 0 {
+			avg := ratingSums[i] / float64(ratingCounts[i])
+			points[i].AverageRating = &avg
+		}
+	}
+	return points, nil
+}
+
+func (m *MemoryStore) GetRecentUsageRecords(limit int) ([]model.UsageRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := m.sortedUsageRecords()
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	for i := range records {
+		m.fillAssociations(&records[i])
+	}
+
+	return records, nil
+}
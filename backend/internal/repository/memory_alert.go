@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"sort"
+	"time"
+)
+
+func (m *MemoryStore) CreateAlert(alert *model.Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alert.ID = m.nextAlertID
+	m.nextAlertID++
+	alert.UserID = m.owner(alert.UserID)
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	m.alerts = append(m.alerts, *alert)
+	return nil
+}
+
+func (m *MemoryStore) GetOpenAlert(bladeID uint) (*model.Alert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.alerts) - 1; i >= 0; i-- {
+		if m.alerts[i].BladeID == bladeID && m.alerts[i].ResolvedAt == nil && m.owns(m.alerts[i].UserID) {
+			alert := m.alerts[i]
+			return &alert, nil
+		}
+	}
+	return nil, ErrAlertNotFound
+}
+
+func (m *MemoryStore) ResolveAlert(id uint, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.alerts {
+		if m.alerts[i].ID == id && m.alerts[i].ResolvedAt == nil && m.owns(m.alerts[i].UserID) {
+			resolved := at.UTC()
+			m.alerts[i].ResolvedAt = &resolved
+			return nil
+		}
+	}
+	return ErrAlertNotFound
+}
+
+func (m *MemoryStore) GetAlerts(filter model.AlertFilter, offset, limit int) ([]model.Alert, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := make([]model.Alert, 0)
+	for _, alert := range m.alerts {
+		if !m.owns(alert.UserID) {
+			continue
+		}
+		if filter.BladeID != 0 && alert.BladeID != filter.BladeID {
+			continue
+		}
+		if filter.Open != nil && *filter.Open != (alert.ResolvedAt == nil) {
+			continue
+		}
+		matched = append(matched, alert)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	return paginate(matched, offset, limit), int64(len(matched)), nil
+}
+
+// removeBladeAlerts 删除刀片的全部告警，与数据库的级联删除保持一致，调用方需持有写锁
+func (m *MemoryStore) removeBladeAlerts(bladeID uint) {
+	kept := m.alerts[:0]
+	for _, alert := range m.alerts {
+		if alert.BladeID != bladeID {
+			kept = append(kept, alert)
+		}
+	}
+	m.alerts = kept
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"fmt"
+	"razor-blade/internal/model"
+	"sort"
+	"time"
+)
+
+func (m *MemoryStore) WithActor(actor model.AuditActor) Store {
+	return &MemoryStore{memoryData: m.memoryData, userID: m.userID, actor: actor}
+}
+
+// newAudit 创建在当前锁内读取快照的审计批次，调用方需持有写锁
+func (m *MemoryStore) newAudit() *auditBatch {
+	return newAuditBatch(m.actor, m.auditSnapshot)
+}
+
+// commitAudit 生成并保存审计事件，在修改成功后调用，调用方需持有写锁
+func (m *MemoryStore) commitAudit(audit *auditBatch) error {
+	events, err := audit.events(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		events[i].ID = m.nextAuditEventID
+		m.nextAuditEventID++
+		m.auditEvents = append(m.auditEvents, events[i])
+	}
+	return nil
+}
+
+// auditSnapshot 读取实体当前的内容，调用方需持有锁
+func (m *MemoryStore) auditSnapshot(entityType string, id uint) (auditSnapshot, error) {
+	var (
+		data   map[string]interface{}
+		userID uint
+		err    error
+	)
+	switch entityType {
+	case model.AuditEntityRazor:
+		idx := m.findRazor(id)
+		if idx < 0 {
+			return auditSnapshot{}, nil
+		}
+		data, err = auditData(&m.razors[idx])
+		userID = m.razors[idx].UserID
+	case model.AuditEntityBlade:
+		idx := m.findBlade(id)
+		if idx < 0 {
+			return auditSnapshot{}, nil
+		}
+		blade := m.liveBlade(m.blades[idx])
+		data, err = auditData(&blade)
+		userID = blade.UserID
+	case model.AuditEntityUsageRecord:
+		idx := m.findUsageRecord(id)
+		if idx < 0 {
+			return auditSnapshot{}, nil
+		}
+		data, err = usageRecordAuditData(&m.usageRecords[idx])
+		userID = m.usageRecords[idx].UserID
+	case model.AuditEntityPurchase:
+		idx := m.findPurchase(id)
+		if idx < 0 {
+			return auditSnapshot{}, nil
+		}
+		data, err = auditData(&m.purchases[idx])
+		userID = m.purchases[idx].UserID
+	default:
+		return auditSnapshot{}, fmt.Errorf("unknown audit entity type %q", entityType)
+	}
+	if err != nil {
+		return auditSnapshot{}, err
+	}
+	return auditSnapshot{data: data, userID: userID}, nil
+}
+
+// watchRazorCascade 删除或恢复剃须刀前记录剃须刀和兼容列表随之变化的刀片，调用方需持有锁
+func (m *MemoryStore) watchRazorCascade(audit *auditBatch, razorID uint) error {
+	if err := audit.watch(model.AuditEntityRazor, razorID); err != nil {
+		return err
+	}
+	for _, blade := range m.blades {
+		if containsID(blade.CompatibleRazorIDs, razorID) {
+			if err := audit.watch(model.AuditEntityBlade, blade.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetAuditEvents(filter model.AuditFilter, offset, limit int) ([]model.AuditEvent, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := make([]model.AuditEvent, 0)
+	for i := range m.auditEvents {
+		if m.owns(m.auditEvents[i].UserID) && matchAuditEvent(&m.auditEvents[i], filter) {
+			matched = append(matched, m.auditEvents[i])
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	return paginate(matched, offset, limit), int64(len(matched)), nil
+}
+
+func matchAuditEvent(event *model.AuditEvent, filter model.AuditFilter) bool {
+	if filter.EntityType != "" && event.EntityType != filter.EntityType {
+		return false
+	}
+	if filter.EntityID != 0 && event.EntityID != filter.EntityID {
+		return false
+	}
+	if filter.Action != "" && event.Action != filter.Action {
+		return false
+	}
+	if filter.From != nil && event.CreatedAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !event.CreatedAt.Before(*filter.To) {
+		return false
+	}
+	return true
+}
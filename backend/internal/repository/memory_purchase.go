@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"strings"
+	"time"
+)
+
+func (m *MemoryStore) CreatePurchase(purchase *model.Purchase) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkPurchaseItem(purchase); err != nil {
+		return err
+	}
+	audit := m.newAudit()
+	if err := watchPurchaseItem(audit, purchase); err != nil {
+		return err
+	}
+	m.insertPurchase(purchase)
+	if err := m.syncPurchaseItem(purchase); err != nil {
+		return err
+	}
+	audit.created(model.AuditEntityPurchase, purchase.ID)
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) GetPurchaseByID(id uint) (*model.Purchase, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if idx := m.findPurchase(id); idx >= 0 {
+		purchase := clonePurchase(m.purchases[idx])
+		return &purchase, nil
+	}
+	return nil, ErrPurchaseNotFound
+}
+
+func (m *MemoryStore) GetPurchases(filter model.PurchaseFilter, offset, limit int) ([]model.Purchase, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := m.matchedPurchases(filter)
+	return paginate(matched, offset, limit), int64(len(matched)), nil
+}
+
+func (m *MemoryStore) GetAllPurchases(filter model.PurchaseFilter) ([]model.Purchase, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.matchedPurchases(filter), nil
+}
+
+func (m *MemoryStore) UpdatePurchase(purchase *model.Purchase) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findPurchase(purchase.ID)
+	if idx < 0 {
+		return ErrPurchaseNotFound
+	}
+	old := m.purchases[idx]
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityPurchase, purchase.ID); err != nil {
+		return err
+	}
+	if err := watchPurchaseItem(audit, &old); err != nil {
+		return err
+	}
+	purchase.RazorID, purchase.BladeID = old.RazorID, old.BladeID
+	purchase.UserID = old.UserID
+	purchase.CreatedAt = old.CreatedAt
+	purchase.UpdatedAt = time.Now()
+	m.purchases[idx] = clonePurchase(*purchase)
+	if err := m.syncPurchaseItem(purchase); err != nil {
+		m.purchases[idx] = old
+		return err
+	}
+	return m.commitAudit(audit)
+}
+
+func (m *MemoryStore) DeletePurchase(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findPurchase(id)
+	if idx < 0 {
+		return ErrPurchaseNotFound
+	}
+	purchase := m.purchases[idx]
+	audit := m.newAudit()
+	if err := audit.watch(model.AuditEntityPurchase, id); err != nil {
+		return err
+	}
+	if err := watchPurchaseItem(audit, &purchase); err != nil {
+		return err
+	}
+	m.purchases = append(m.purchases[:idx], m.purchases[idx+1:]...)
+	if err := m.syncPurchaseItem(&purchase); err != nil {
+		m.purchases = append(m.purchases[:idx], append([]model.Purchase{purchase}, m.purchases[idx:]...)...)
+		return err
+	}
+	return m.commitAudit(audit)
+}
+
+// matchedPurchases 返回满足条件并排好序的购买记录副本，调用方需持有锁
+func (m *MemoryStore) matchedPurchases(filter model.PurchaseFilter) []model.Purchase {
+	matched := make([]model.Purchase, 0)
+	for i := range m.purchases {
+		if m.owns(m.purchases[i].UserID) && !m.purchaseInTrash(&m.purchases[i]) && matchPurchase(&m.purchases[i], filter) {
+			matched = append(matched, clonePurchase(m.purchases[i]))
+		}
+	}
+	sortByFields(len(matched),
+		func(i, j int) { matched[i], matched[j] = matched[j], matched[i] },
+		func(i int, field string) interface{} { return purchaseField(&matched[i], field) },
+		filter.Sort, defaultPurchaseSort)
+	return matched
+}
+
+func matchPurchase(purchase *model.Purchase, filter model.PurchaseFilter) bool {
+	if filter.RazorID != 0 && (purchase.RazorID == nil || *purchase.RazorID != filter.RazorID) {
+		return false
+	}
+	if filter.BladeID != 0 && (purchase.BladeID == nil || *purchase.BladeID != filter.BladeID) {
+		return false
+	}
+	if filter.ItemType != "" && purchase.ItemType() != filter.ItemType {
+		return false
+	}
+	if filter.Vendor != "" && !strings.EqualFold(purchase.Vendor, filter.Vendor) {
+		return false
+	}
+	if filter.From != nil && purchase.PurchaseDate.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !purchase.PurchaseDate.Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+func purchaseField(purchase *model.Purchase, field string) interface{} {
+	switch field {
+	case "purchase_date":
+		return purchase.PurchaseDate
+	case "quantity":
+		return purchase.Quantity
+	case "unit_price":
+		return purchase.UnitPrice
+	case "vendor":
+		return purchase.Vendor
+	case "created_at":
+		return purchase.CreatedAt
+	case "updated_at":
+		return purchase.UpdatedAt
+	}
+	return purchase.ID
+}
+
+// insertPurchase 分配ID并保存购买记录，调用方需持有写锁
+func (m *MemoryStore) insertPurchase(purchase *model.Purchase) {
+	purchase.ID = m.nextPurchaseID
+	m.nextPurchaseID++
+	purchase.CreatedAt = time.Now()
+	purchase.UpdatedAt = time.Now()
+	m.purchases = append(m.purchases, clonePurchase(*purchase))
+}
+
+func (m *MemoryStore) findPurchase(id uint) int {
+	for i := range m.purchases {
+		if m.purchases[i].ID == id && m.owns(m.purchases[i].UserID) && !m.purchaseInTrash(&m.purchases[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// purchaseInTrash 判断购买对象是否在回收站中，调用方需持有锁
+func (m *MemoryStore) purchaseInTrash(purchase *model.Purchase) bool {
+	if purchase.RazorID != nil {
+		return m.findTrashedRazor(*purchase.RazorID) >= 0
+	}
+	return m.findTrashedBlade(*purchase.BladeID) >= 0
+}
+
+// clonePurchase 复制购买记录，避免与调用方共享ID指针
+func clonePurchase(purchase model.Purchase) model.Purchase {
+	if purchase.RazorID != nil {
+		id := *purchase.RazorID
+		purchase.RazorID = &id
+	}
+	if purchase.BladeID != nil {
+		id := *purchase.BladeID
+		purchase.BladeID = &id
+	}
+	return purchase
+}
+
+// checkPurchaseItem 校验购买对象存在，购买记录与购买对象归属同一用户，调用方需持有锁
+func (m *MemoryStore) checkPurchaseItem(purchase *model.Purchase) error {
+	if purchase.RazorID != nil {
+		idx := m.findRazor(*purchase.RazorID)
+		if idx < 0 {
+			return ErrRazorNotFound
+		}
+		purchase.UserID = m.razors[idx].UserID
+		return nil
+	}
+	idx := m.findBlade(*purchase.BladeID)
+	if idx < 0 {
+		return ErrBladeNotFound
+	}
+	purchase.UserID = m.blades[idx].UserID
+	return nil
+}
+
+// syncPurchaseItem 刷新购买记录所属剃须刀或刀片的购买信息，调用方需持有写锁
+func (m *MemoryStore) syncPurchaseItem(purchase *model.Purchase) error {
+	if purchase.RazorID != nil {
+		m.syncRazorPurchases(*purchase.RazorID)
+		return nil
+	}
+	return m.syncBladePurchases(*purchase.BladeID)
+}
+
+// syncRazorPurchases 用最近一次购买记录刷新剃须刀的购买日期、价格和币种
+func (m *MemoryStore) syncRazorPurchases(razorID uint) {
+	idx := m.findRazor(razorID)
+	if idx < 0 {
+		return
+	}
+	razor := &m.razors[idx]
+	razor.PurchaseDate, razor.Price, razor.Currency = nil, nil, ""
+	if latest := m.latestPurchase(func(p *model.Purchase) bool {
+		return p.RazorID != nil && *p.RazorID == razorID
+	}); latest != nil {
+		date := latest.PurchaseDate
+		razor.PurchaseDate, razor.Price, razor.Currency = &date, latest.UnitPrice, latest.Currency
+	}
+	razor.UpdatedAt = time.Now()
+}
+
+// syncBladePurchases 重新累加刀片的总数量，剩余数量按总数量的变化调整，
+// 并用最近一次购买记录刷新购买日期、单价和币种
+func (m *MemoryStore) syncBladePurchases(bladeID uint) error {
+	idx := m.findBlade(bladeID)
+	if idx < 0 {
+		return ErrBladeNotFound
+	}
+	isBlade := func(p *model.Purchase) bool { return p.BladeID != nil && *p.BladeID == bladeID }
+
+	total := 0
+	for i := range m.purchases {
+		if isBlade(&m.purchases[i]) {
+			total += m.purchases[i].Quantity
+		}
+	}
+	blade := &m.blades[idx]
+	remaining := blade.RemainingQuantity + total - blade.TotalQuantity
+	if remaining < 0 {
+		return ErrInsufficientStock
+	}
+
+	blade.TotalQuantity, blade.RemainingQuantity = total, remaining
+	blade.PurchaseDate, blade.UnitPrice, blade.Currency = nil, nil, ""
+	if latest := m.latestPurchase(isBlade); latest != nil {
+		date := latest.PurchaseDate
+		blade.PurchaseDate, blade.UnitPrice, blade.Currency = &date, latest.UnitPrice, latest.Currency
+	}
+	blade.UpdatedAt = time.Now()
+	return nil
+}
+
+// latestPurchase 返回满足条件且购买日期最晚的一条记录，与数据库一致同日期取ID较大者
+func (m *MemoryStore) latestPurchase(match func(*model.Purchase) bool) *model.Purchase {
+	var latest *model.Purchase
+	for i := range m.purchases {
+		p := &m.purchases[i]
+		if !match(p) {
+			continue
+		}
+		if latest == nil || p.PurchaseDate.After(latest.PurchaseDate) ||
+			(p.PurchaseDate.Equal(latest.PurchaseDate) && p.ID > latest.ID) {
+			latest = p
+		}
+	}
+	return latest
+}
+
+// removePurchases 删除满足条件的购买记录，与数据库的级联删除保持一致，调用方需持有写锁
+func (m *MemoryStore) removePurchases(match func(*model.Purchase) bool) {
+	kept := m.purchases[:0]
+	for i := range m.purchases {
+		if !match(&m.purchases[i]) {
+			kept = append(kept, m.purchases[i])
+		}
+	}
+	m.purchases = kept
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+func matchRazor(razor *model.Razor, filter model.RazorFilter) bool {
+	if filter.Brand != "" && !strings.EqualFold(razor.Brand, filter.Brand) {
+		return false
+	}
+	if filter.Model != "" && !strings.EqualFold(razor.Model, filter.Model) {
+		return false
+	}
+	if filter.Query != "" && !containsFold(razor.Notes, filter.Query) {
+		return false
+	}
+	return true
+}
+
+func matchBlade(blade *model.Blade, filter model.BladeFilter) bool {
+	if filter.Brand != "" && !strings.EqualFold(blade.Brand, filter.Brand) {
+		return false
+	}
+	if filter.Model != "" && !strings.EqualFold(blade.Model, filter.Model) {
+		return false
+	}
+	if filter.Query != "" && !containsFold(blade.Notes, filter.Query) {
+		return false
+	}
+	if filter.LowStockThreshold != nil && blade.RemainingQuantity > blade.EffectiveLowStockThreshold(*filter.LowStockThreshold) {
+		return false
+	}
+	return true
+}
+
+func matchUsageRecord(record *model.UsageRecord, filter model.UsageRecordFilter) bool {
+	if filter.RazorID != 0 && record.RazorID != filter.RazorID {
+		return false
+	}
+	if filter.BladeID != 0 && record.BladeID != filter.BladeID {
+		return false
+	}
+	if filter.Query != "" && !containsFold(record.ExperienceText, filter.Query) {
+		return false
+	}
+	// 与SQL一致，未评分的记录不满足评分条件
+	if filter.RatingMin != nil && (record.Rating == nil || *record.Rating < *filter.RatingMin) {
+		return false
+	}
+	if filter.RatingMax != nil && (record.Rating == nil || *record.Rating > *filter.RatingMax) {
+		return false
+	}
+	if filter.From != nil && record.UsageTime.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !record.UsageTime.Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func razorField(razor *model.Razor, field string) interface{} {
+	switch field {
+	case "brand":
+		return razor.Brand
+	case "model":
+		return razor.Model
+	case "purchase_date":
+		return razor.PurchaseDate
+	case "price":
+		return razor.Price
+	case "created_at":
+		return razor.CreatedAt
+	case "updated_at":
+		return razor.UpdatedAt
+	}
+	return razor.ID
+}
+
+func bladeField(blade *model.Blade, field string) interface{} {
+	switch field {
+	case "brand":
+		return blade.Brand
+	case "model":
+		return blade.Model
+	case "purchase_date":
+		return blade.PurchaseDate
+	case "unit_price":
+		return blade.UnitPrice
+	case "total_quantity":
+		return blade.TotalQuantity
+	case "remaining_quantity":
+		return blade.RemainingQuantity
+	case "created_at":
+		return blade.CreatedAt
+	case "updated_at":
+		return blade.UpdatedAt
+	}
+	return blade.ID
+}
+
+func usageRecordField(record *model.UsageRecord, field string) interface{} {
+	switch field {
+	case "usage_time":
+		return record.UsageTime
+	case "rating":
+		return record.Rating
+	case "blade_usage_count":
+		return record.BladeUsageCount
+	case "created_at":
+		return record.CreatedAt
+	case "updated_at":
+		return record.UpdatedAt
+	}
+	return record.ID
+}
+
+// sortByFields 按与orderBy相同的规则排序：空值始终在最后，最后以id升序兜底
+func sortByFields(n int, swap func(i, j int), value func(i int, field string) interface{}, sorts []model.SortField, fallback []model.SortField) {
+	if len(sorts) == 0 {
+		sorts = fallback
+	}
+	sorts = append(append([]model.SortField{}, sorts...), model.SortField{Field: "id"})
+	sort.Stable(fieldSorter{n: n, swap: swap, less: func(i, j int) bool {
+		for _, s := range sorts {
+			a, b := value(i, s.Field), value(j, s.Field)
+			aNil, bNil := isNilValue(a), isNilValue(b)
+			switch {
+			case aNil && bNil:
+				continue
+			case aNil:
+				return false
+			case bNil:
+				return true
+			}
+			c := compareValues(a, b)
+			if c == 0 {
+				continue
+			}
+			if s.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	}})
+}
+
+type fieldSorter struct {
+	n    int
+	swap func(i, j int)
+	less func(i, j int) bool
+}
+
+func (s fieldSorter) Len() int           { return s.n }
+func (s fieldSorter) Swap(i, j int)      { s.swap(i, j) }
+func (s fieldSorter) Less(i, j int) bool { return s.less(i, j) }
+
+func isNilValue(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case *time.Time:
+		return x == nil
+	case *float64:
+		return x == nil
+	case *int:
+		return x == nil
+	}
+	return false
+}
+
+// compareValues 比较两个同类型的非空字段值
+func compareValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case uint:
+		return compareOrdered(x, b.(uint))
+	case int:
+		return compareOrdered(x, b.(int))
+	case *int:
+		return compareOrdered(*x, *b.(*int))
+	case *float64:
+		return compareOrdered(*x, *b.(*float64))
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	case *time.Time:
+		return x.Compare(*b.(*time.Time))
+	}
+	return 0
+}
+
+func compareOrdered[T int | uint | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"sort"
+	"time"
+)
+
+func (m *MemoryStore) CreateAPIToken(token *model.APIToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token.ID = m.nextAPITokenID
+	m.nextAPITokenID++
+	token.UserID = m.owner(token.UserID)
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	m.apiTokens = append(m.apiTokens, cloneAPIToken(*token))
+	m.insertTokenEvent(&model.APITokenEvent{
+		TokenID:   token.ID,
+		Event:     model.TokenEventCreated,
+		CreatedAt: token.CreatedAt,
+	})
+	return nil
+}
+
+func (m *MemoryStore) GetAPITokenByID(id uint) (*model.APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if idx := m.findAPIToken(id); idx >= 0 {
+		token := cloneAPIToken(m.apiTokens[idx])
+		return &token, nil
+	}
+	return nil, ErrAPITokenNotFound
+}
+
+func (m *MemoryStore) GetAPITokens() ([]model.APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tokens := make([]model.APIToken, 0)
+	for _, token := range m.apiTokens {
+		if m.owns(token.UserID) {
+			tokens = append(tokens, cloneAPIToken(token))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID > tokens[j].ID
+	})
+	return tokens, nil
+}
+
+func (m *MemoryStore) RevokeAPIToken(id uint, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.findAPIToken(id)
+	if idx < 0 {
+		return ErrAPITokenNotFound
+	}
+	if m.apiTokens[idx].RevokedAt != nil {
+		return nil
+	}
+	revoked := at.UTC()
+	m.apiTokens[idx].RevokedAt = &revoked
+	m.insertTokenEvent(&model.APITokenEvent{
+		TokenID:   id,
+		Event:     model.TokenEventRevoked,
+		CreatedAt: revoked,
+	})
+	return nil
+}
+
+func (m *MemoryStore) GetAPITokenByHash(tokenHash string) (*model.APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, token := range m.apiTokens {
+		if token.TokenHash == tokenHash {
+			token = cloneAPIToken(token)
+			return &token, nil
+		}
+	}
+	return nil, ErrAPITokenNotFound
+}
+
+func (m *MemoryStore) RecordAPITokenUse(event *model.APITokenEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.apiTokens {
+		if m.apiTokens[i].ID == event.TokenID {
+			used := event.CreatedAt.UTC()
+			m.apiTokens[i].LastUsedAt = &used
+			m.insertTokenEvent(event)
+			return nil
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+func (m *MemoryStore) GetAPITokenEvents(tokenID uint, offset, limit int) ([]model.APITokenEvent, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findAPIToken(tokenID) < 0 {
+		return nil, 0, ErrAPITokenNotFound
+	}
+	matched := make([]model.APITokenEvent, 0)
+	for _, event := range m.apiTokenEvents {
+		if event.TokenID == tokenID {
+			matched = append(matched, event)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	return paginate(matched, offset, limit), int64(len(matched)), nil
+}
+
+// 查找当前用户的API令牌下标，调用方需持有锁
+func (m *MemoryStore) findAPIToken(id uint) int {
+	for i := range m.apiTokens {
+		if m.apiTokens[i].ID == id && m.owns(m.apiTokens[i].UserID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertTokenEvent 分配ID并保存审计记录，调用方需持有写锁
+func (m *MemoryStore) insertTokenEvent(event *model.APITokenEvent) {
+	event.ID = m.nextTokenEventID
+	m.nextTokenEventID++
+	m.apiTokenEvents = append(m.apiTokenEvents, *event)
+}
+
+// cloneAPIToken 复制令牌，避免调用方与存储共享权限切片
+func cloneAPIToken(token model.APIToken) model.APIToken {
+	token.Scopes = append([]string{}, token.Scopes...)
+	return token
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (m *MemoryStore) GetTrash() (*model.Trash, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trash := &model.Trash{
+		Razors:       []model.Razor{},
+		Blades:       []model.Blade{},
+		UsageRecords: []model.UsageRecord{},
+	}
+	for i := range m.razors {
+		if m.findTrashedRazor(m.razors[i].ID) == i {
+			trash.Razors = append(trash.Razors, m.razors[i])
+		}
+	}
+	for i := range m.blades {
+		if m.findTrashedBlade(m.blades[i].ID) == i {
+			trash.Blades = append(trash.Blades, m.liveBlade(m.blades[i]))
+		}
+	}
+	for i := range m.usageRecords {
+		if m.findTrashedUsageRecord(m.usageRecords[i].ID) < 0 {
+			continue
+		}
+		// 使用记录的剃须刀或刀片可能也在回收站中
+		record := m.usageRecords[i]
+		for _, razor := range m.razors {
+			if razor.ID == record.RazorID {
+				record.Razor = razor
+			}
+		}
+		for _, blade := range m.blades {
+			if blade.ID == record.BladeID {
+				record.Blade = blade
+				record.Blade.CompatibleRazorIDs = nil
+			}
+		}
+		trash.UsageRecords = append(trash.UsageRecords, record)
+	}
+
+	sortTrash(trash.Razors, func(r *model.Razor) (gorm.DeletedAt, uint) { return r.DeletedAt, r.ID })
+	sortTrash(trash.Blades, func(b *model.Blade) (gorm.DeletedAt, uint) { return b.DeletedAt, b.ID })
+	sortTrash(trash.UsageRecords, func(r *model.UsageRecord) (gorm.DeletedAt, uint) { return r.DeletedAt, r.ID })
+	return trash, nil
+}
+
+// sortTrash 按删除时间倒序排列，删除时间相同时按ID倒序
+func sortTrash[T any](items []T, key func(*T) (gorm.DeletedAt, uint)) {
+	sort.Slice(items, func(i, j int) bool {
+		ai, aid := key(&items[i])
+		bi, bid := key(&items[j])
+		if !ai.Time.Equal(bi.Time) {
+			return ai.Time.After(bi.Time)
+		}
+		return aid > bid
+	})
+}
+
+func (m *MemoryStore) PurgeTrash(before time.Time) (*model.PurgeResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expired := func(userID uint, deletedAt gorm.DeletedAt) bool {
+		return m.owns(userID) && deletedAt.Valid && deletedAt.Time.Before(before)
+	}
+	result := &model.PurgeResult{}
+	razorIDs := make(map[uint]bool)
+	keptRazors := m.razors[:0]
+	for _, razor := range m.razors {
+		if expired(razor.UserID, razor.DeletedAt) {
+			razorIDs[razor.ID] = true
+			continue
+		}
+		keptRazors = append(keptRazors, razor)
+	}
+	m.razors = keptRazors
+	result.Razors = int64(len(razorIDs))
+
+	bladeIDs := make(map[uint]bool)
+	keptBlades := m.blades[:0]
+	for _, blade := range m.blades {
+		if expired(blade.UserID, blade.DeletedAt) {
+			bladeIDs[blade.ID] = true
+			m.removeBladeAlerts(blade.ID)
+			continue
+		}
+		keptBlades = append(keptBlades, blade)
+	}
+	m.blades = keptBlades
+	result.Blades = int64(len(bladeIDs))
+
+	keptRecords := m.usageRecords[:0]
+	for _, record := range m.usageRecords {
+		if expired(record.UserID, record.DeletedAt) || razorIDs[record.RazorID] || bladeIDs[record.BladeID] {
+			result.UsageRecords++
+			continue
+		}
+		keptRecords = append(keptRecords, record)
+	}
+	m.usageRecords = keptRecords
+
+	// 与关联表和购买记录的级联删除保持一致
+	m.removePurchases(func(p *model.Purchase) bool {
+		return (p.RazorID != nil && razorIDs[*p.RazorID]) || (p.BladeID != nil && bladeIDs[*p.BladeID])
+	})
+	for i := range m.blades {
+		ids := m.blades[i].CompatibleRazorIDs[:0:0]
+		for _, razorID := range m.blades[i].CompatibleRazorIDs {
+			if !razorIDs[razorID] {
+				ids = append(ids, razorID)
+			}
+		}
+		m.blades[i].CompatibleRazorIDs = ids
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"time"
+)
+
+func (m *MemoryStore) CreateUser(user *model.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range m.users {
+		if u.Username == user.Username {
+			return ErrUsernameTaken
+		}
+	}
+
+	user.ID = m.nextUserID
+	m.nextUserID++
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+	if len(m.users) == 0 {
+		m.adoptUnowned(user.ID)
+	}
+	m.users = append(m.users, *user)
+	return nil
+}
+
+// adoptUnowned 把没有所属用户的数据交给指定用户，调用方需持有写锁
+func (m *MemoryStore) adoptUnowned(userID uint) {
+	for i := range m.razors {
+		if m.razors[i].UserID == 0 {
+			m.razors[i].UserID = userID
+		}
+	}
+	for i := range m.blades {
+		if m.blades[i].UserID == 0 {
+			m.blades[i].UserID = userID
+		}
+	}
+	for i := range m.usageRecords {
+		if m.usageRecords[i].UserID == 0 {
+			m.usageRecords[i].UserID = userID
+		}
+	}
+	for i := range m.purchases {
+		if m.purchases[i].UserID == 0 {
+			m.purchases[i].UserID = userID
+		}
+	}
+	for i := range m.alerts {
+		if m.alerts[i].UserID == 0 {
+			m.alerts[i].UserID = userID
+		}
+	}
+}
+
+func (m *MemoryStore) GetUserByID(id uint) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.ID == id {
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (m *MemoryStore) GetUserByUsername(username string) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (m *MemoryStore) CreateSession(session *model.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session.ID = m.nextSessionID
+	m.nextSessionID++
+	session.CreatedAt = time.Now()
+	m.sessions = append(m.sessions, *session)
+	return nil
+}
+
+func (m *MemoryStore) GetSessionUser(tokenHash string, now time.Time) (*model.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		if session.TokenHash != tokenHash || !session.ExpiresAt.After(now) {
+			continue
+		}
+		for _, user := range m.users {
+			if user.ID == session.UserID {
+				return &user, nil
+			}
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+func (m *MemoryStore) DeleteSession(tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.sessions {
+		if m.sessions[i].TokenHash == tokenHash {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSessionNotFound
+}
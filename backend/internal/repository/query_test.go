@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+func TestGetUsageStatistics(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		empty, err := store.GetUsageStatistics()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertStatistics(t, empty, 0, 0, 0, 0)
+
+		user := &model.User{Username: "alice", PasswordHash: "x"}
+		if err := store.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+		scoped := store.ForUser(user.ID)
+		razor := mustCreateRazor(t, scoped, "Merkur", "34C")
+		blade := mustCreateBlade(t, scoped, "Astra", "SP", 5, razor.ID)
+		trashed := mustCreateBlade(t, scoped, "Derby", "Extra", 0)
+		if err := scoped.DeleteBlade(trashed.ID, model.DeleteOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		for i, rating := range []*int{intPtr(5), intPtr(2), nil} {
+			record := &model.UsageRecord{UsageTime: baseTime.Add(time.Duration(i) * time.Hour),
+				RazorID: razor.ID, BladeID: blade.ID, Rating: rating}
+			if err := scoped.CreateUsageRecord(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// 其他用户的数据不计入
+		other := &model.User{Username: "bob", PasswordHash: "x"}
+		if err := store.CreateUser(other); err != nil {
+			t.Fatal(err)
+		}
+		mustCreateRazor(t, store.ForUser(other.ID), "Gillette", "Tech")
+
+		stats, err := scoped.GetUsageStatistics()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertStatistics(t, stats, 3, 1, 1, 3.5)
+	})
+}
+
+func TestListSortingAndFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		razor := mustCreateRazor(t, store, "Merkur", "34C")
+		threshold := 4
+		for _, b := range []*model.Blade{
+			{Brand: "Astra", Model: "SP", Purchases: []model.Purchase{{PurchaseDate: baseTime, Quantity: 10, UnitPrice: floatPtr(0.5)}}},
+			{Brand: "Feather", Model: "Hi-Stainless", Purchases: []model.Purchase{{PurchaseDate: baseTime, Quantity: 3}}},
+			{Brand: "Derby", Model: "Extra", LowStockThreshold: &threshold,
+				Purchases: []model.Purchase{{PurchaseDate: baseTime, Quantity: 4, UnitPrice: floatPtr(0.2)}}},
+		} {
+			b.CompatibleRazorIDs = []uint{razor.ID}
+			if err := store.CreateBlade(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// 空值始终排在最后
+		blades, _, err := store.GetBlades(model.BladeFilter{Sort: []model.SortField{{Field: "unit_price", Desc: true}}}, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bladeBrands(blades); got != "Astra,Derby,Feather" {
+			t.Errorf("unit_price desc = %s", got)
+		}
+		blades, _, err = store.GetBlades(model.BladeFilter{Sort: []model.SortField{{Field: "unit_price"}}}, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bladeBrands(blades); got != "Derby,Astra,Feather" {
+			t.Errorf("unit_price asc = %s", got)
+		}
+
+		// 库存不足按各刀片自己的阈值判断，没有设置时使用传入的全局阈值
+		global := 3
+		blades, total, err := store.GetBlades(model.BladeFilter{LowStockThreshold: &global}, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 2 || bladeBrands(blades) != "Feather,Derby" {
+			t.Errorf("low stock = %d %s", total, bladeBrands(blades))
+		}
+
+		for i, rating := range []*int{intPtr(1), intPtr(4), nil, intPtr(5)} {
+			record := &model.UsageRecord{UsageTime: baseTime.Add(time.Duration(i) * time.Hour),
+				RazorID: razor.ID, BladeID: blades[0].ID, Rating: rating, ExperienceText: "Smooth"}
+			if err := store.CreateUsageRecord(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+		min, max := 2, 5
+		records, err := store.GetAllUsageRecords(model.UsageRecordFilter{
+			RatingMin: &min, RatingMax: &max, Query: "SMOO",
+			Sort: []model.SortField{{Field: "rating", Desc: true}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || *records[0].Rating != 5 || *records[1].Rating != 4 {
+			t.Errorf("rating filter = %v", usageIDs(records))
+		}
+	})
+}
+
+func assertStatistics(t *testing.T, stats map[string]interface{}, usage, razors, blades int64, rating float64) {
+	t.Helper()
+	if stats["total_usage"] != usage || stats["razor_count"] != razors || stats["blade_count"] != blades || stats["average_rating"] != rating {
+		t.Errorf("statistics = %v, want usage=%d razors=%d blades=%d rating=%v", stats, usage, razors, blades, rating)
+	}
+}
+
+func bladeBrands(blades []model.Blade) string {
+	brands := ""
+	for i, blade := range blades {
+		if i > 0 {
+			brands += ","
+		}
+		brands += blade.Brand
+	}
+	return brands
+}
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"errors"
+	"razor-blade/internal/model"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrRazorNotFound 剃须刀不存在
+	ErrRazorNotFound = errors.New("razor not found")
+	// ErrBladeNotFound 刀片不存在
+	ErrBladeNotFound = errors.New("blade not found")
+	// ErrUsageRecordNotFound 使用记录不存在
+	ErrUsageRecordNotFound = errors.New("usage record not found")
+	// ErrInsufficientStock 刀片库存不足
+	ErrInsufficientStock = errors.New("insufficient blade stock")
+	// ErrRazorInUse 剃须刀仍被使用记录或刀片的兼容关系引用
+	ErrRazorInUse = errors.New("razor is referenced by usage records or blades")
+	// ErrBladeInUse 刀片仍被使用记录引用
+	ErrBladeInUse = errors.New("blade is referenced by usage records")
+	// ErrReassignTargetNotFound 转移使用记录的目标剃须刀或刀片不存在，或与被删除的数据不属于同一用户
+	ErrReassignTargetNotFound = errors.New("reassign target not found")
+	// ErrAlertNotFound 告警不存在
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrPurchaseNotFound 购买记录不存在
+	ErrPurchaseNotFound = errors.New("purchase not found")
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUsernameTaken 用户名已被注册
+	ErrUsernameTaken = errors.New("username already taken")
+	// ErrSessionNotFound 会话不存在或已过期
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrAPITokenNotFound API令牌不存在
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
+// DependencyError 删除时仍有数据引用，可用errors.Is判断ErrRazorInUse或ErrBladeInUse
+type DependencyError struct {
+	Err    error
+	Report *model.DependencyReport
+}
+
+func (e *DependencyError) Error() string { return e.Err.Error() }
+
+func (e *DependencyError) Unwrap() error { return e.Err }
+
+// Store 数据存储接口，Service只依赖该接口。
+// 查询不到记录时返回对应的ErrXxxNotFound哨兵错误。
+// 业务数据按所属用户隔离，其他用户的数据视为不存在。
+//
+// 剃须刀、刀片和使用记录删除时移入回收站，回收站中的数据视为不存在，各关联的处理方式：
+//   - 剃须刀、刀片 → 使用记录：默认拒绝删除；指定Cascade时级联，使用记录以相同的删除时间一起移入回收站，
+//     恢复时一起恢复；指定ReassignTo时先在同一事务内转移到另一剃须刀或刀片
+//   - 剃须刀 → 刀片兼容关系：默认拒绝删除，指定Cascade或ReassignTo时保留但不可见
+//   - 使用记录 → 刀片库存：与删除使用记录相同，换过刀片的记录移入回收站时归还库存，恢复时重新扣减
+//   - 剃须刀、刀片 → 购买记录、兼容关系：保留但不可见，恢复后重新可见，彻底删除时一并删除
+//   - 刀片 → 库存告警：保留，彻底删除时一并删除
+type Store interface {
+	// ForUser 返回只读写指定用户数据的存储，与原存储共享底层数据。
+	// 新建的剃须刀和刀片归属该用户，购买记录、使用记录和告警归属其引用的剃须刀或刀片。
+	// userID为0时不按用户过滤，供库存告警等后台任务使用
+	ForUser(userID uint) Store
+	// WithActor 返回以指定操作者写入审计事件的存储，与原存储共享底层数据
+	WithActor(actor model.AuditActor) Store
+	// Transaction 在同一事务内执行fn，fn通过传入的存储读写，返回错误时回滚其中的全部修改
+	Transaction(fn func(Store) error) error
+
+	// 剃须刀，列表查询的排序字段需已通过白名单校验
+	// CreateRazor 同时写入razor.Purchases中的购买记录
+	CreateRazor(razor *model.Razor) error
+	GetRazorByID(id uint) (*model.Razor, error)
+	GetRazors(filter model.RazorFilter, offset, limit int) ([]model.Razor, int64, error)
+	// GetAllRazors 返回满足条件的全部剃须刀，用于统计分析
+	GetAllRazors(filter model.RazorFilter) ([]model.Razor, error)
+	UpdateRazor(razor *model.Razor) error
+	// DeleteRazor 把剃须刀移入回收站，仍有依赖且opts未指定处理方式时返回*DependencyError
+	DeleteRazor(id uint, opts model.DeleteOptions) error
+	// RestoreRazor 从回收站恢复剃须刀及与其一起删除的使用记录，刀片仍在回收站中的使用记录不恢复。
+	// 剃须刀不在回收站中时返回ErrRazorNotFound
+	RestoreRazor(id uint) error
+
+	// 刀片，创建和更新时同步CompatibleRazorIDs，引用的剃须刀不存在时返回ErrRazorNotFound
+	// CreateBlade 同时写入blade.Purchases中的购买记录
+	CreateBlade(blade *model.Blade) error
+	GetBladeByID(id uint) (*model.Blade, error)
+	GetBlades(filter model.BladeFilter, offset, limit int) ([]model.Blade, int64, error)
+	// GetAllBlades 返回满足条件的全部刀片，用于统计分析
+	GetAllBlades(filter model.BladeFilter) ([]model.Blade, error)
+	UpdateBlade(blade *model.Blade) error
+	// DeleteBlade 把刀片移入回收站，仍有使用记录且opts未指定处理方式时返回*DependencyError。
+	// 转移到另一刀片时换过刀片的记录从目标刀片扣减库存，库存不足返回ErrInsufficientStock
+	DeleteBlade(id uint, opts model.DeleteOptions) error
+	// RestoreBlade 从回收站恢复刀片及与其一起删除的使用记录，剃须刀仍在回收站中的使用记录不恢复
+	RestoreBlade(id uint) error
+	// GetCompatibleBlades 返回声明兼容指定剃须刀的刀片
+	GetCompatibleBlades(razorID uint) ([]model.Blade, error)
+	// IsBladeCompatible 剃须刀和刀片都对当前用户可见、不在回收站中且声明兼容时返回true
+	IsBladeCompatible(razorID, bladeID uint) (bool, error)
+	// RecountBladeInventory 根据总数量减去已记录的换刀次数重建刀片剩余库存
+	RecountBladeInventory(id uint) (*model.Blade, error)
+
+	// 使用记录
+
+	// CreateUsageRecord 在同一个工作单元内校验剃须刀和刀片、按需扣减刀片库存并写入使用记录，
+	// 任一步骤失败都不会留下部分修改。库存扣减是条件更新，并发请求不会超卖。
+	CreateUsageRecord(record *model.UsageRecord) error
+	GetUsageRecordByID(id uint) (*model.UsageRecord, error)
+	// GetUsageRecords 分页返回满足条件的使用记录，默认按usage_time倒序
+	GetUsageRecords(filter model.UsageRecordFilter, offset, limit int) ([]model.UsageRecord, int64, error)
+	// GetAllUsageRecords 返回满足条件的全部使用记录，不加载关联，用于统计分析
+	GetAllUsageRecords(filter model.UsageRecordFilter) ([]model.UsageRecord, error)
+	// GetUsageRecordsByCursor 按 usage_time, id 键集分页返回至多limit条记录，不统计总数，
+	// 结果始终按cursor.Desc指定的方向排列，filter.Sort被忽略
+	GetUsageRecordsByCursor(filter model.UsageRecordFilter, cursor model.UsageRecordCursor, limit int) ([]model.UsageRecord, error)
+	// UpdateUsageRecord 更新使用记录并在同一事务内调整库存：
+	// 原记录若更换过刀片则归还原刀片一片，新记录若更换刀片则从新刀片扣减一片。
+	UpdateUsageRecord(record *model.UsageRecord) error
+	// DeleteUsageRecord 把使用记录移入回收站，若该记录更换过刀片则在同一事务内归还库存
+	DeleteUsageRecord(id uint) error
+	// RestoreUsageRecord 从回收站恢复使用记录并重新扣减库存，
+	// 剃须刀或刀片仍在回收站中时返回ErrRazorNotFound或ErrBladeNotFound
+	RestoreUsageRecord(id uint) error
+	GetRecentUsageRecords(limit int) ([]model.UsageRecord, error)
+
+	// 回收站
+	// GetTrash 返回回收站中的剃须刀、刀片和使用记录，按删除时间倒序
+	GetTrash() (*model.Trash, error)
+	// PurgeTrash 彻底删除在before之前移入回收站的数据，被删除的剃须刀和刀片下仍在回收站中的使用记录一并删除
+	PurgeTrash(before time.Time) (*model.PurgeResult, error)
+
+	// 统计
+	GetUsageStatistics() (map[string]interface{}, error)
+	// GetUsageTimeSeries 按boundaries划分的时间桶聚合使用记录，
+	// boundaries为升序的桶边界，返回len(boundaries)-1个桶，空桶计数为0。
+	// 换刀次数按刀片分组聚合到ChangesByBlade；单价的币种折算依赖配置的汇率，
+	// 由服务层完成，Spend始终为0
+	GetUsageTimeSeries(filter model.UsageRecordFilter, boundaries []time.Time) ([]model.TimeSeriesPoint, error)
+
+	// 库存告警，刀片删除时一并删除
+	CreateAlert(alert *model.Alert) error
+	// GetOpenAlert 返回刀片未解除的告警，没有时返回ErrAlertNotFound
+	GetOpenAlert(bladeID uint) (*model.Alert, error)
+	ResolveAlert(id uint, at time.Time) error
+	// GetAlerts 分页返回告警历史，新告警在前
+	GetAlerts(filter model.AlertFilter, offset, limit int) ([]model.Alert, int64, error)
+
+	// 购买记录，剃须刀或刀片删除时一并删除。
+	// 写入购买记录时在同一事务内刷新对应剃须刀/刀片的购买信息，
+	// 刀片的总数量随之变化，剩余数量按相同差值调整，
+	// 调整后为负（已购的刀片已被消耗）时返回ErrInsufficientStock
+	CreatePurchase(purchase *model.Purchase) error
+	GetPurchaseByID(id uint) (*model.Purchase, error)
+	// GetPurchases 分页返回购买记录，默认按购买日期倒序
+	GetPurchases(filter model.PurchaseFilter, offset, limit int) ([]model.Purchase, int64, error)
+	// GetAllPurchases 返回满足条件的全部购买记录，用于统计分析
+	GetAllPurchases(filter model.PurchaseFilter) ([]model.Purchase, error)
+	// UpdatePurchase 更新购买记录，购买对象不能修改
+	UpdatePurchase(purchase *model.Purchase) error
+	DeletePurchase(id uint) error
+
+	// 用户与登录会话，不受ForUser限制
+	// CreateUser 用户名已存在时返回ErrUsernameTaken。
+	// 第一个注册的用户在同一事务内接管没有所属用户的已有数据
+	CreateUser(user *model.User) error
+	GetUserByID(id uint) (*model.User, error)
+	GetUserByUsername(username string) (*model.User, error)
+	CreateSession(session *model.Session) error
+	// GetSessionUser 返回令牌摘要对应且在now时仍有效的会话所属用户，没有时返回ErrSessionNotFound
+	GetSessionUser(tokenHash string, now time.Time) (*model.User, error)
+	DeleteSession(tokenHash string) error
+
+	// 个人API令牌，创建和撤销时在同一事务内写入审计记录
+	CreateAPIToken(token *model.APIToken) error
+	GetAPITokenByID(id uint) (*model.APIToken, error)
+	// GetAPITokens 返回当前用户的全部令牌（含已撤销的），新令牌在前
+	GetAPITokens() ([]model.APIToken, error)
+	// RevokeAPIToken 撤销令牌，已撤销的令牌保持不变
+	RevokeAPIToken(id uint, at time.Time) error
+	// GetAPITokenByHash 按令牌摘要查找，不受ForUser限制，用于认证
+	GetAPITokenByHash(tokenHash string) (*model.APIToken, error)
+	// RecordAPITokenUse 写入一条使用记录并更新令牌的最近使用时间
+	RecordAPITokenUse(event *model.APITokenEvent) error
+	// GetAPITokenEvents 分页返回令牌的审计记录，新记录在前
+	GetAPITokenEvents(tokenID uint, offset, limit int) ([]model.APITokenEvent, int64, error)
+
+	// 审计日志。剃须刀、刀片、使用记录和购买记录的每次新增、修改和删除都在同一事务内写入审计事件，
+	// 随之变化的库存、购买信息等关联实体也各记录一条
+	// GetAuditEvents 分页返回审计事件，新事件在前
+	GetAuditEvents(filter model.AuditFilter, offset, limit int) ([]model.AuditEvent, int64, error)
+}
+
+// 未指定排序时的默认顺序，两种实现共用
+var (
+	defaultRazorSort       = []model.SortField{{Field: "id"}}
+	defaultBladeSort       = []model.SortField{{Field: "id"}}
+	defaultUsageRecordSort = []model.SortField{{Field: "usage_time", Desc: true}, {Field: "id", Desc: true}}
+	defaultPurchaseSort    = []model.SortField{{Field: "purchase_date", Desc: true}, {Field: "id", Desc: true}}
+)
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*GormStore)(nil)
+)
+
+// trashTime 返回移入回收站的时间。级联删除的数据按删除时间匹配，
+// 截断到微秒保证PostgreSQL读回的时间与写入时相同
+func trashTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// 剩余数量 = 总数量 - 换刀次数，不小于0
+func remainingAfterChanges(total int, changes int64) int {
+	remaining := total - int(changes)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// uniqueIDs 去重并排序ID列表
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// reverse 原地反转切片
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
+// emptyTimeSeries 按桶边界生成空的时间序列
+func emptyTimeSeries(boundaries []time.Time) []model.TimeSeriesPoint {
+	if len(boundaries) < 2 {
+		return []model.TimeSeriesPoint{}
+	}
+	points := make([]model.TimeSeriesPoint, len(boundaries)-1)
+	for i := range points {
+		points[i].Start = boundaries[i]
+		points[i].End = boundaries[i+1]
+	}
+	return points
+}
+
+// bladeUsageCounts 为同一剃须刀按 usage_time, id 升序排列的使用记录计算刀片使用次数。
+// 首条记录、换用了其他刀片或上一条记录标记换刀时从1开始计数；
+// 设置了覆盖值的记录以覆盖值为准，后续记录从该值继续累加
+func bladeUsageCounts(records []model.UsageRecord) map[uint]int {
+	counts := make(map[uint]int, len(records))
+	count := 0
+	for i, record := range records {
+		if i == 0 || records[i-1].BladeID != record.BladeID || records[i-1].NeedBladeChange {
+			count = 0
+		}
+		count++
+		if record.BladeUsageCountOverride != nil {
+			count = *record.BladeUsageCountOverride
+		}
+		counts[record.ID] = count
+	}
+	return counts
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"razor-blade/internal/config"
+	"razor-blade/internal/migration"
+	"razor-blade/internal/model"
+	"razor-blade/pkg/database"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteStore 在临时目录中创建已执行全部迁移的SQLite存储，连接参数与服务相同
+func newSQLiteStore(t *testing.T) *GormStore {
+	t.Helper()
+	db, err := database.InitDB(&config.DatabaseConfig{
+		Driver: "sqlite",
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.Logger = db.Logger.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := migration.New(db).Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return NewGormStore(db)
+}
+
+// forEachStore 对内存存储和SQLite存储各运行一次fn
+func forEachStore(t *testing.T, fn func(t *testing.T, store Store)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, newSQLiteStore(t)) })
+}
+
+func mustCreateRazor(t *testing.T, store Store, brand, name string) *model.Razor {
+	t.Helper()
+	razor := &model.Razor{Brand: brand, Model: name}
+	if err := store.CreateRazor(razor); err != nil {
+		t.Fatalf("create razor: %v", err)
+	}
+	return razor
+}
+
+// mustCreateBlade 创建刀片，quantity大于0时附带一条购买记录作为库存
+func mustCreateBlade(t *testing.T, store Store, brand, name string, quantity int, razorIDs ...uint) *model.Blade {
+	t.Helper()
+	blade := &model.Blade{Brand: brand, Model: name, CompatibleRazorIDs: razorIDs}
+	if quantity > 0 {
+		blade.Purchases = []model.Purchase{{PurchaseDate: time.Now(), Quantity: quantity}}
+	}
+	if err := store.CreateBlade(blade); err != nil {
+		t.Fatalf("create blade: %v", err)
+	}
+	return blade
+}
+
+func mustGetBlade(t *testing.T, store Store, id uint) *model.Blade {
+	t.Helper()
+	blade, err := store.GetBladeByID(id)
+	if err != nil {
+		t.Fatalf("get blade %d: %v", id, err)
+	}
+	return blade
+}
+
+func TestCreateUsageRecordConcurrentStock(t *testing.T) {
+	const (
+		workers = 20
+		stock   = 5
+	)
+	forEachStore(t, func(t *testing.T, store Store) {
+		razor := mustCreateRazor(t, store, "Merkur", "34C")
+		blade := mustCreateBlade(t, store, "Astra", "SP", stock, razor.ID)
+
+		// 写入期间持续读取库存，确认任何时刻都不为负
+		done := make(chan struct{})
+		minSeen := make(chan int, 1)
+		go func() {
+			lowest := stock
+			for {
+				select {
+				case <-done:
+					minSeen <- lowest
+					return
+				default:
+				}
+				if b, err := store.GetBladeByID(blade.ID); err == nil && b.RemainingQuantity < lowest {
+					lowest = b.RemainingQuantity
+				}
+			}
+		}()
+
+		start := make(chan struct{})
+		errs := make([]error, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				errs[i] = store.CreateUsageRecord(&model.UsageRecord{
+					UsageTime:       time.Now().Add(time.Duration(i) * time.Minute),
+					RazorID:         razor.ID,
+					BladeID:         blade.ID,
+					NeedBladeChange: true,
+				})
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		close(done)
+
+		succeeded, rejected := 0, 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, ErrInsufficientStock):
+				rejected++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if succeeded != stock || rejected != workers-stock {
+			t.Errorf("succeeded=%d rejected=%d, want %d and %d", succeeded, rejected, stock, workers-stock)
+		}
+		if got := mustGetBlade(t, store, blade.ID).RemainingQuantity; got != 0 {
+			t.Errorf("remaining quantity = %d, want 0", got)
+		}
+		if lowest := <-minSeen; lowest < 0 {
+			t.Errorf("remaining quantity dropped to %d", lowest)
+		}
+		_, total, err := store.GetUsageRecords(model.UsageRecordFilter{BladeID: blade.ID}, 0, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != stock {
+			t.Errorf("usage records = %d, want %d", total, stock)
+		}
+	})
+}
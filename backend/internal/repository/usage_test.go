@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+var baseTime = time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+
+func mustCreateUsage(t *testing.T, store Store, razorID, bladeID uint, at time.Time, change bool) *model.UsageRecord {
+	t.Helper()
+	record := &model.UsageRecord{UsageTime: at, RazorID: razorID, BladeID: bladeID, NeedBladeChange: change}
+	if err := store.CreateUsageRecord(record); err != nil {
+		t.Fatalf("create usage record: %v", err)
+	}
+	return record
+}
+
+func mustUpdateUsage(t *testing.T, store Store, id uint, edit func(*model.UsageRecord)) {
+	t.Helper()
+	record, err := store.GetUsageRecordByID(id)
+	if err != nil {
+		t.Fatalf("get usage record %d: %v", id, err)
+	}
+	edit(record)
+	if err := store.UpdateUsageRecord(record); err != nil {
+		t.Fatalf("update usage record %d: %v", id, err)
+	}
+}
+
+func assertRemaining(t *testing.T, store Store, bladeID uint, want int) {
+	t.Helper()
+	if got := mustGetBlade(t, store, bladeID).RemainingQuantity; got != want {
+		t.Errorf("blade %d remaining quantity = %d, want %d", bladeID, got, want)
+	}
+}
+
+// assertUsageCounts 按使用时间升序比较剃须刀各记录的刀片使用次数
+func assertUsageCounts(t *testing.T, store Store, razorID uint, want ...int) {
+	t.Helper()
+	records, err := store.GetAllUsageRecords(model.UsageRecordFilter{
+		RazorID: razorID,
+		Sort:    []model.SortField{{Field: "usage_time"}, {Field: "id"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]int, len(records))
+	for i, record := range records {
+		got[i] = record.BladeUsageCount
+	}
+	if len(got) != len(want) {
+		t.Fatalf("usage counts = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("usage counts = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDeleteUsageRecordReturnsStock(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		razor := mustCreateRazor(t, store, "Rockwell", "6S")
+		blade := mustCreateBlade(t, store, "Feather", "Hi-Stainless", 3, razor.ID)
+		changed := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, true)
+		plain := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(24*time.Hour), false)
+		assertRemaining(t, store, blade.ID, 2)
+
+		if err := store.DeleteUsageRecord(plain.ID); err != nil {
+			t.Fatal(err)
+		}
+		assertRemaining(t, store, blade.ID, 2)
+
+		if err := store.DeleteUsageRecord(changed.ID); err != nil {
+			t.Fatal(err)
+		}
+		assertRemaining(t, store, blade.ID, 3)
+
+		if err := store.RestoreUsageRecord(changed.ID); err != nil {
+			t.Fatal(err)
+		}
+		assertRemaining(t, store, blade.ID, 2)
+	})
+}
+
+func TestUpdateUsageRecordReconcilesStock(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		razor := mustCreateRazor(t, store, "Gillette", "Tech")
+		first := mustCreateBlade(t, store, "Astra", "SP", 3, razor.ID)
+		second := mustCreateBlade(t, store, "Personna", "Lab Blue", 1, razor.ID)
+		record := mustCreateUsage(t, store, razor.ID, first.ID, baseTime, true)
+		assertRemaining(t, store, first.ID, 2)
+
+		// 换用另一刀片：原刀片归还，新刀片扣减
+		mustUpdateUsage(t, store, record.ID, func(r *model.UsageRecord) { r.BladeID = second.ID })
+		assertRemaining(t, store, first.ID, 3)
+		assertRemaining(t, store, second.ID, 0)
+
+		// 取消换刀标记归还库存，重新标记再扣减
+		mustUpdateUsage(t, store, record.ID, func(r *model.UsageRecord) { r.NeedBladeChange = false })
+		assertRemaining(t, store, second.ID, 1)
+		mustUpdateUsage(t, store, record.ID, func(r *model.UsageRecord) { r.NeedBladeChange = true })
+		assertRemaining(t, store, second.ID, 0)
+
+		// 新刀片库存不足时整体回滚
+		other := mustCreateUsage(t, store, razor.ID, first.ID, baseTime.Add(time.Hour), true)
+		got, err := store.GetUsageRecordByID(other.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.BladeID = second.ID
+		if err := store.UpdateUsageRecord(got); !errors.Is(err, ErrInsufficientStock) {
+			t.Fatalf("update onto empty blade: err = %v, want ErrInsufficientStock", err)
+		}
+		assertRemaining(t, store, first.ID, 2)
+		assertRemaining(t, store, second.ID, 0)
+		if after, err := store.GetUsageRecordByID(other.ID); err != nil || after.BladeID != first.ID {
+			t.Errorf("record after failed update: %+v, %v", after, err)
+		}
+	})
+}
+
+func TestRecountBladeInventory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		razor := mustCreateRazor(t, store, "Karve", "CB")
+		blade := mustCreateBlade(t, store, "Nacet", "Stainless", 5, razor.ID)
+		mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, true)
+		mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(time.Hour), true)
+
+		// 库存被直接改错后按使用记录重建
+		drifted := mustGetBlade(t, store, blade.ID)
+		drifted.RemainingQuantity = 5
+		if err := store.UpdateBlade(drifted); err != nil {
+			t.Fatal(err)
+		}
+		recounted, err := store.RecountBladeInventory(blade.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recounted.RemainingQuantity != 3 {
+			t.Errorf("recounted remaining quantity = %d, want 3", recounted.RemainingQuantity)
+		}
+		assertRemaining(t, store, blade.ID, 3)
+	})
+}
+
+func TestBladeUsageCountRenumbering(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		razor := mustCreateRazor(t, store, "Blackland", "Blackbird")
+		blade := mustCreateBlade(t, store, "Gillette", "Platinum", 10, razor.ID)
+		day := 24 * time.Hour
+		first := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime, false)
+		mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(2*day), false)
+		last := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(4*day), false)
+		assertUsageCounts(t, store, razor.ID, 1, 2, 3)
+
+		// 在中间插入一条换刀记录，之后的记录从1重新计数
+		changed := mustCreateUsage(t, store, razor.ID, blade.ID, baseTime.Add(day), true)
+		assertUsageCounts(t, store, razor.ID, 1, 2, 1, 2)
+
+		// 把最后一条移到最前
+		mustUpdateUsage(t, store, last.ID, func(r *model.UsageRecord) { r.UsageTime = baseTime.Add(-day) })
+		assertUsageCounts(t, store, razor.ID, 1, 2, 3, 1)
+
+		// 删除中间的换刀记录后恢复连续计数
+		if err := store.DeleteUsageRecord(changed.ID); err != nil {
+			t.Fatal(err)
+		}
+		assertUsageCounts(t, store, razor.ID, 1, 2, 3)
+
+		// 覆盖值之后的记录从覆盖值继续累加
+		override := 7
+		mustUpdateUsage(t, store, first.ID, func(r *model.UsageRecord) { r.BladeUsageCountOverride = &override })
+		assertUsageCounts(t, store, razor.ID, 1, 7, 8)
+	})
+}
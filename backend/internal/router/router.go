@@ -3,15 +3,17 @@ package router
 import (
 	"razor-blade/internal/handler"
 	"razor-blade/internal/middleware"
+	"razor-blade/internal/model"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-func SetupRouter(h *handler.Handler, logger *logrus.Logger) *gin.Engine {
+func SetupRouter(h *handler.Handler, auth middleware.Authenticator, tokens middleware.APITokenAuthenticator, readOnly bool, logger *logrus.Logger) *gin.Engine {
 	r := gin.New()
 
 	// 中间件
+	r.Use(middleware.RequestIDMiddleware())
 	r.Use(middleware.LoggerMiddleware(logger))
 	r.Use(middleware.ErrorHandlerMiddleware(logger))
 	r.Use(middleware.CORSMiddleware())
@@ -22,41 +24,106 @@ func SetupRouter(h *handler.Handler, logger *logrus.Logger) *gin.Engine {
 
 	// API路由组
 	api := r.Group("/api/v1")
+	if readOnly {
+		// 数据库只读时拒绝修改数据；登录和退出只改动内存中的会话，仍然允许
+		api.Use(middleware.ReadOnlyMiddleware("/api/v1/auth/login", "/api/v1/auth/logout"))
+	}
+
+	// 注册和登录不需要令牌
+	api.POST("/auth/register", h.Register)
+	api.POST("/auth/login", h.Login)
+
+	// 其余接口需要登录或API令牌，只能访问当前用户的数据
+	authed := api.Group("",
+		middleware.APITokenMiddleware(tokens, logger),
+		middleware.AuthMiddleware(auth),
+		middleware.ScopeMiddleware())
 	{
+		authed.POST("/auth/logout", h.Logout)
+		authed.GET("/auth/me", h.GetCurrentUser)
+
+		// API令牌管理，API令牌需要admin权限
+		apiTokens := authed.Group("/tokens", middleware.RequireScope(model.ScopeAdmin))
+		{
+			apiTokens.POST("", h.CreateAPIToken)
+			apiTokens.GET("", h.GetAPITokens)
+			apiTokens.DELETE("/:id", h.RevokeAPIToken)
+			apiTokens.GET("/:id/events", h.GetAPITokenEvents)
+		}
+
 		// 剃须刀路由
-		razors := api.Group("/razors")
+		razors := authed.Group("/razors")
 		{
 			razors.POST("", h.CreateRazor)
 			razors.GET("", h.GetRazors)
 			razors.GET("/:id", h.GetRazor)
 			razors.PUT("/:id", h.UpdateRazor)
 			razors.DELETE("/:id", h.DeleteRazor)
+			razors.POST("/:id/restore", h.RestoreRazor)
+			razors.GET("/:id/compatible-blades", h.GetCompatibleBlades)
+			razors.GET("/:id/mounted-blade", h.GetMountedBlade)
 		}
 
 		// 刀片路由
-		blades := api.Group("/blades")
+		blades := authed.Group("/blades")
 		{
 			blades.POST("", h.CreateBlade)
 			blades.GET("", h.GetBlades)
+			blades.GET("/forecast", h.GetBladeForecast)
 			blades.GET("/:id", h.GetBlade)
 			blades.PUT("/:id", h.UpdateBlade)
 			blades.DELETE("/:id", h.DeleteBlade)
+			blades.POST("/:id/restore", h.RestoreBlade)
+			blades.POST("/:id/recount", h.RecountBlade)
+			blades.GET("/:id/lifetime", h.GetBladeLifetime)
 		}
 
 		// 使用记录路由
-		usageRecords := api.Group("/usage-records")
+		usageRecords := authed.Group("/usage-records")
 		{
 			usageRecords.POST("", h.CreateUsageRecord)
 			usageRecords.GET("", h.GetUsageRecords)
 			usageRecords.GET("/:id", h.GetUsageRecord)
 			usageRecords.PUT("/:id", h.UpdateUsageRecord)
 			usageRecords.DELETE("/:id", h.DeleteUsageRecord)
+			usageRecords.POST("/:id/restore", h.RestoreUsageRecord)
+		}
+
+		// 购买记录路由
+		purchases := authed.Group("/purchases")
+		{
+			purchases.POST("", h.CreatePurchase)
+			purchases.GET("", h.GetPurchases)
+			purchases.GET("/:id", h.GetPurchase)
+			purchases.PUT("/:id", h.UpdatePurchase)
+			purchases.DELETE("/:id", h.DeletePurchase)
 		}
 
 		// 统计和仪表板路由
-		api.GET("/dashboard", h.GetDashboard)
-		api.GET("/statistics", h.GetStatistics)
+		authed.GET("/dashboard", h.GetDashboard)
+		authed.GET("/statistics", h.GetStatistics)
+		authed.GET("/statistics/timeseries", h.GetTimeSeries)
+		authed.GET("/statistics/cost", h.GetCostReport)
+
+		// 库存告警路由
+		authed.GET("/alerts", h.GetAlerts)
+
+		// 审计日志路由
+		authed.GET("/audit", h.GetAuditEvents)
+
+		// 回收站路由，删除的数据在这里查看，通过各资源的restore接口恢复
+		authed.GET("/trash", h.GetTrash)
+
+		// 导出和导入路由，导入接受导出的格式
+		authed.GET("/export", h.Export)
+		authed.POST("/import", h.Import)
+
+		// 管理接口，只有配置的管理员可以访问，API令牌还需要admin权限
+		admin := authed.Group("/admin", middleware.RequireAdmin(auth), middleware.RequireScope(model.ScopeAdmin))
+		{
+			admin.GET("/backup", h.Backup)
+		}
 	}
 
 	return r
-}
\ No newline at end of file
+}
@@ -0,0 +1,179 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"razor-blade/internal/config"
+	"razor-blade/internal/handler"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"razor-blade/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestRouter 使用内存存储组装完整的路由和中间件
+func newTestRouter(t *testing.T) (*gin.Engine, *config.Config) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		Inventory: config.InventoryConfig{LowStockThreshold: 2},
+		Cost:      config.CostConfig{BaseCurrency: "CNY"},
+		Auth:      config.AuthConfig{SessionTTL: time.Hour},
+	}
+	svc := service.NewService(repository.NewMemoryStore(), cfg, nil)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := handler.NewHandler(svc, nil, model.StorageInfo{Mode: "memory"}, logger)
+	return SetupRouter(h, svc, svc, false, logger), cfg
+}
+
+// call 发送JSON请求，token为空时不带Authorization
+func call(t *testing.T, r *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// mustCall 要求请求成功，把响应中的data解析到out
+func mustCall(t *testing.T, r *gin.Engine, method, path, token, body string, out interface{}) {
+	t.Helper()
+	w := call(t, r, method, path, token, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s = %d: %s", method, path, w.Code, w.Body.String())
+	}
+	if out == nil {
+		return
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &struct{ Data interface{} }{Data: out}); err != nil {
+		t.Fatalf("decode %s %s: %v", method, path, err)
+	}
+}
+
+// register 注册用户并返回登录令牌
+func register(t *testing.T, r *gin.Engine, username string) string {
+	t.Helper()
+	var auth model.AuthResponse
+	mustCall(t, r, http.MethodPost, "/api/v1/auth/register", "",
+		`{"username":"`+username+`","password":"correct horse"}`, &auth)
+	return auth.Token
+}
+
+func id(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func TestOtherUsersDataIsNotFound(t *testing.T) {
+	r, _ := newTestRouter(t)
+	alice := register(t, r, "alice")
+	bob := register(t, r, "bob")
+
+	var razor model.Razor
+	mustCall(t, r, http.MethodPost, "/api/v1/razors", alice, `{"brand":"Merkur","model":"34C"}`, &razor)
+	var blade model.Blade
+	mustCall(t, r, http.MethodPost, "/api/v1/blades", alice,
+		`{"brand":"Astra","model":"SP","total_quantity":5,"compatible_razor_ids":[`+id(razor.ID)+`]}`, &blade)
+	usageBody := `{"usage_time":"2024-03-01T07:00:00Z","razor_id":` + id(razor.ID) + `,"blade_id":` + id(blade.ID) + `,"need_blade_change":true}`
+	var record model.UsageRecord
+	mustCall(t, r, http.MethodPost, "/api/v1/usage-records", alice, usageBody, &record)
+
+	// bob自己的数据，用来尝试引用alice的剃须刀和刀片
+	var bobRazor model.Razor
+	mustCall(t, r, http.MethodPost, "/api/v1/razors", bob, `{"brand":"Gillette","model":"Tech"}`, &bobRazor)
+
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/v1/razors/" + id(razor.ID), ""},
+		{http.MethodPut, "/api/v1/razors/" + id(razor.ID), `{"brand":"Mine"}`},
+		{http.MethodDelete, "/api/v1/razors/" + id(razor.ID) + "?cascade=true", ""},
+		{http.MethodGet, "/api/v1/razors/" + id(razor.ID) + "/compatible-blades", ""},
+		{http.MethodGet, "/api/v1/blades/" + id(blade.ID), ""},
+		{http.MethodPut, "/api/v1/blades/" + id(blade.ID), `{"brand":"Mine"}`},
+		{http.MethodDelete, "/api/v1/blades/" + id(blade.ID) + "?cascade=true", ""},
+		{http.MethodPost, "/api/v1/blades/" + id(blade.ID) + "/recount", ""},
+		{http.MethodGet, "/api/v1/blades/" + id(blade.ID) + "/lifetime", ""},
+		{http.MethodGet, "/api/v1/usage-records/" + id(record.ID), ""},
+		{http.MethodPut, "/api/v1/usage-records/" + id(record.ID), usageBody},
+		{http.MethodDelete, "/api/v1/usage-records/" + id(record.ID), ""},
+	} {
+		if w := call(t, r, tc.method, tc.path, bob, tc.body); w.Code != http.StatusNotFound {
+			t.Errorf("bob %s %s = %d, want 404: %s", tc.method, tc.path, w.Code, w.Body.String())
+		}
+	}
+
+	// 引用其他用户的刀片等同于引用不存在的刀片
+	body := `{"usage_time":"2024-03-01T07:00:00Z","razor_id":` + id(bobRazor.ID) + `,"blade_id":` + id(blade.ID) + `}`
+	if w := call(t, r, http.MethodPost, "/api/v1/usage-records", bob, body); w.Code != http.StatusBadRequest {
+		t.Errorf("bob uses alice's blade = %d, want 400: %s", w.Code, w.Body.String())
+	}
+
+	var list struct {
+		Total int64 `json:"total"`
+	}
+	for _, path := range []string{"/api/v1/razors", "/api/v1/blades", "/api/v1/usage-records"} {
+		mustCall(t, r, http.MethodGet, path, bob, "", &list)
+		want := int64(0)
+		if path == "/api/v1/razors" {
+			want = 1
+		}
+		if list.Total != want {
+			t.Errorf("bob lists %s: total %d, want %d", path, list.Total, want)
+		}
+	}
+
+	// alice的数据没有被改动
+	var got model.Razor
+	mustCall(t, r, http.MethodGet, "/api/v1/razors/"+id(razor.ID), alice, "", &got)
+	if got.Brand != "Merkur" {
+		t.Errorf("alice's razor brand = %q", got.Brand)
+	}
+	var gotBlade model.Blade
+	mustCall(t, r, http.MethodGet, "/api/v1/blades/"+id(blade.ID), alice, "", &gotBlade)
+	if gotBlade.Brand != "Astra" || gotBlade.RemainingQuantity != 4 {
+		t.Errorf("alice's blade = %s, remaining %d", gotBlade.Brand, gotBlade.RemainingQuantity)
+	}
+	mustCall(t, r, http.MethodGet, "/api/v1/usage-records/"+id(record.ID), alice, "", nil)
+}
+
+func TestSessionRequired(t *testing.T) {
+	r, cfg := newTestRouter(t)
+	valid := register(t, r, "alice")
+	loggedOut := register(t, r, "carol")
+	mustCall(t, r, http.MethodPost, "/api/v1/auth/logout", loggedOut, "", nil)
+
+	// 有效期为负，注册时签发的令牌已过期
+	cfg.Auth.SessionTTL = -time.Minute
+	expired := register(t, r, "bob")
+
+	for name, token := range map[string]string{
+		"missing":    "",
+		"unknown":    "not-a-session",
+		"expired":    expired,
+		"logged out": loggedOut,
+	} {
+		for _, path := range []string{"/api/v1/razors", "/api/v1/auth/me"} {
+			if w := call(t, r, http.MethodGet, path, token, ""); w.Code != http.StatusUnauthorized {
+				t.Errorf("%s token GET %s = %d, want 401", name, path, w.Code)
+			}
+		}
+		if w := call(t, r, http.MethodPost, "/api/v1/razors", token, `{"brand":"Merkur","model":"34C"}`); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s token POST razor = %d, want 401", name, w.Code)
+		}
+	}
+	if w := call(t, r, http.MethodGet, "/api/v1/razors", valid, ""); w.Code != http.StatusOK {
+		t.Errorf("valid token = %d, want 200", w.Code)
+	}
+}
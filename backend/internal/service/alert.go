@@ -0,0 +1,32 @@
+package service
+
+import (
+	"math"
+	"razor-blade/internal/model"
+)
+
+// evaluateStock 库存变化后检查告警
+func (s *Service) evaluateStock(bladeIDs ...uint) {
+	if s.alerts != nil {
+		s.alerts.Evaluate(bladeIDs...)
+	}
+}
+
+// GetAlerts 分页查询库存告警历史
+func (s *Service) GetAlerts(req *model.AlertListRequest) (*model.PaginationResponse, error) {
+	filter := model.AlertFilter{BladeID: req.BladeID, Open: req.Open}
+
+	offset := normalizePage(&req.PaginationRequest)
+	alerts, total, err := s.repo.GetAlerts(filter, offset, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginationResponse{
+		Items:      alerts,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.PageSize))),
+	}, nil
+}
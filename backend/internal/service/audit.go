@@ -0,0 +1,44 @@
+package service
+
+import (
+	"math"
+	"razor-blade/internal/model"
+)
+
+// WithActor 返回以指定操作者记录审计事件的服务
+func (s *Service) WithActor(actor model.AuditActor) *Service {
+	return &Service{repo: s.repo.WithActor(actor), cfg: s.cfg, alerts: s.alerts, sessions: s.sessions}
+}
+
+// GetAuditEvents 分页查询审计日志，新事件在前
+func (s *Service) GetAuditEvents(req *model.AuditListRequest) (*model.PaginationResponse, error) {
+	from, err := parseTimeParam("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeParam("to", req.To)
+	if err != nil {
+		return nil, err
+	}
+	filter := model.AuditFilter{
+		EntityType: req.Entity,
+		EntityID:   req.ID,
+		Action:     req.Action,
+		From:       from,
+		To:         to,
+	}
+
+	offset := normalizePage(&req.PaginationRequest)
+	events, total, err := s.repo.GetAuditEvents(filter, offset, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginationResponse{
+		Items:      events,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.PageSize))),
+	}, nil
+}
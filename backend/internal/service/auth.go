@@ -0,0 +1,188 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUnauthorized 未登录、令牌无效或用户名密码错误
+	ErrUnauthorized = errors.New("认证失败")
+	// ErrUsernameTaken 用户名已被注册
+	ErrUsernameTaken = errors.New("用户名已被注册")
+)
+
+// ForUser 返回只访问指定用户数据的服务
+func (s *Service) ForUser(userID uint) *Service {
+	return &Service{repo: s.repo.ForUser(userID), cfg: s.cfg, alerts: s.alerts, sessions: s.sessions}
+}
+
+// Register 注册用户并直接登录。第一个注册的用户接管已有的数据
+func (s *Service) Register(req *model.RegisterRequest) (*model.AuthResponse, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &model.User{
+		Username:     strings.TrimSpace(req.Username),
+		PasswordHash: string(hash),
+	}
+	if err := s.repo.CreateUser(user); err != nil {
+		if errors.Is(err, repository.ErrUsernameTaken) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+	return s.createSession(user)
+}
+
+// Login 校验用户名和密码并签发新的令牌
+func (s *Service) Login(req *model.LoginRequest) (*model.AuthResponse, error) {
+	user, err := s.repo.GetUserByUsername(strings.TrimSpace(req.Username))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: 用户名或密码错误", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, fmt.Errorf("%w: 用户名或密码错误", ErrUnauthorized)
+	}
+	return s.createSession(user)
+}
+
+// Logout 使令牌失效，令牌已失效时不报错
+func (s *Service) Logout(token string) error {
+	if s.sessions != nil {
+		s.sessions.revoke(hashToken(token))
+		return nil
+	}
+	err := s.repo.DeleteSession(hashToken(token))
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Authenticate 返回令牌对应的用户
+func (s *Service) Authenticate(token string) (*model.User, error) {
+	if s.sessions != nil {
+		userID, found, err := s.sessions.lookup(hashToken(token), time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthorized, err.Error())
+		}
+		if found {
+			return s.repo.GetUserByID(userID)
+		}
+	}
+	user, err := s.repo.GetSessionUser(hashToken(token), time.Now())
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, fmt.Errorf("%w: 令牌无效或已过期", ErrUnauthorized)
+	}
+	return user, err
+}
+
+// createSession 生成随机令牌，数据库中只保存其摘要
+func (s *Service) createSession(user *model.User) (*model.AuthResponse, error) {
+	var token string
+	for token == "" || strings.HasPrefix(token, model.APITokenPrefix) {
+		// 登录令牌不能与API令牌的前缀相同
+		var err error
+		if token, err = newToken(""); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &model.Session{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.Auth.SessionTTL).UTC(),
+	}
+	if s.sessions != nil {
+		s.sessions.add(session)
+	} else if err := s.repo.CreateSession(session); err != nil {
+		return nil, err
+	}
+	return &model.AuthResponse{Token: token, ExpiresAt: session.ExpiresAt, User: *user}, nil
+}
+
+// memorySessions 数据库只读时在内存中保存的会话，服务重启后失效。
+// 数据库中已有的会话退出时无法删除，记录为已撤销
+type memorySessions struct {
+	mu       sync.Mutex
+	sessions map[string]model.Session // 以令牌摘要为键
+	revoked  map[string]bool
+}
+
+func newMemorySessions() *memorySessions {
+	return &memorySessions{sessions: make(map[string]model.Session), revoked: make(map[string]bool)}
+}
+
+func (m *memorySessions) add(session *model.Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.TokenHash] = *session
+}
+
+// lookup 返回令牌摘要对应的用户，found为false时应继续查数据库，令牌已撤销或过期时返回错误
+func (m *memorySessions) lookup(tokenHash string, now time.Time) (userID uint, found bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.revoked[tokenHash] {
+		return 0, false, errors.New("令牌已失效")
+	}
+	session, ok := m.sessions[tokenHash]
+	if !ok {
+		return 0, false, nil
+	}
+	if !session.ExpiresAt.After(now) {
+		delete(m.sessions, tokenHash)
+		return 0, false, errors.New("令牌无效或已过期")
+	}
+	return session.UserID, true, nil
+}
+
+func (m *memorySessions) revoke(tokenHash string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[tokenHash]; ok {
+		delete(m.sessions, tokenHash)
+		return
+	}
+	m.revoked[tokenHash] = true
+}
+
+// newToken 生成带前缀的随机令牌
+func newToken(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAdmin 判断用户是否在配置的管理员列表中
+func (s *Service) IsAdmin(user *model.User) bool {
+	for _, username := range s.cfg.Auth.AdminUsers {
+		if username == user.Username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"razor-blade/internal/config"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestReadOnlySessions(t *testing.T) {
+	store := repository.NewMemoryStore()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: "alice", PasswordHash: string(hash)}
+	if err := store.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	// 切换为只读之前登录的会话保存在数据库中
+	existing := &model.Session{UserID: user.ID, TokenHash: hashToken("persisted"), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.CreateSession(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Database: config.DatabaseConfig{ReadOnly: true}, Auth: config.AuthConfig{SessionTTL: time.Hour}}
+	s := NewService(store, cfg, nil)
+
+	auth, err := s.Login(&model.LoginRequest{Username: "alice", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Authenticate(auth.Token); err != nil || got.ID != user.ID {
+		t.Fatalf("authenticate in-memory session: %v, %v", got, err)
+	}
+	if _, err := store.GetSessionUser(hashToken(auth.Token), time.Now()); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Errorf("session written to read-only store: err = %v", err)
+	}
+	if _, err := s.Authenticate("persisted"); err != nil {
+		t.Errorf("authenticate stored session: %v", err)
+	}
+
+	for _, token := range []string{auth.Token, "persisted"} {
+		if err := s.Logout(token); err != nil {
+			t.Fatalf("logout: %v", err)
+		}
+		if _, err := s.Authenticate(token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("authenticate after logout: err = %v, want ErrUnauthorized", err)
+		}
+	}
+	// 数据库中的会话没有被删除，只在内存中撤销
+	if _, err := store.GetSessionUser(existing.TokenHash, time.Now()); err != nil {
+		t.Errorf("stored session deleted from read-only store: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"razor-blade/internal/model"
+	"sort"
+	"strings"
+)
+
+// currencyConverter 按配置的汇率把购买金额折算为基础货币
+type currencyConverter struct {
+	base        string
+	rates       map[string]float64
+	unconverted map[string]bool
+}
+
+func newCurrencyConverter(base string, rates map[string]float64) *currencyConverter {
+	c := &currencyConverter{
+		base:        strings.ToUpper(base),
+		rates:       make(map[string]float64, len(rates)),
+		unconverted: make(map[string]bool),
+	}
+	// viper读取配置时会把map的键转为小写
+	for currency, rate := range rates {
+		c.rates[strings.ToUpper(currency)] = rate
+	}
+	return c
+}
+
+// convert 折算金额，币种没有配置汇率时返回false并记录下来
+func (c *currencyConverter) convert(amount float64, currency string) (float64, bool) {
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == c.base {
+		return amount, true
+	}
+	rate, ok := c.rates[currency]
+	if !ok || rate <= 0 {
+		c.unconverted[currency] = true
+		return 0, false
+	}
+	return amount * rate, true
+}
+
+// unconvertedCurrencies 返回遇到过的无法折算的币种
+func (c *currencyConverter) unconvertedCurrencies() []string {
+	if len(c.unconverted) == 0 {
+		return nil
+	}
+	currencies := make([]string, 0, len(c.unconverted))
+	for currency := range c.unconverted {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// GetCostReport 统计购买花费、每次剃须成本、按月花费以及各剃须刀的成本对比
+func (s *Service) GetCostReport(req *model.CostRequest) (*model.CostReport, error) {
+	loc, err := loadLocation(req.TZ)
+	if err != nil {
+		return nil, err
+	}
+
+	razors, err := s.repo.GetAllRazors(model.RazorFilter{})
+	if err != nil {
+		return nil, err
+	}
+	purchases, err := s.repo.GetAllPurchases(model.PurchaseFilter{})
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.GetAllUsageRecords(model.UsageRecordFilter{
+		Sort: []model.SortField{{Field: "usage_time"}, {Field: "id"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conv := newCurrencyConverter(s.cfg.Cost.BaseCurrency, s.cfg.Cost.ExchangeRates)
+	report := &model.CostReport{
+		BaseCurrency: conv.base,
+		MonthlySpend: []model.MonthlySpend{},
+		Razors:       make([]model.RazorCost, 0, len(razors)),
+	}
+
+	// 按购买记录汇总花费，每条记录按各自的币种折算
+	months := make(map[string]*model.MonthlySpend)
+	razorPrices := make(map[uint]*float64)
+	for _, purchase := range purchases {
+		if purchase.UnitPrice == nil {
+			continue
+		}
+		amount, ok := conv.convert(*purchase.UnitPrice*float64(purchase.Quantity), purchase.Currency)
+		if !ok {
+			continue
+		}
+		key := purchase.PurchaseDate.In(loc).Format("2006-01")
+		month := months[key]
+		if month == nil {
+			month = &model.MonthlySpend{Month: key}
+			months[key] = month
+		}
+		month.Total += amount
+
+		if purchase.RazorID != nil {
+			report.RazorSpend += amount
+			month.Razors += amount
+			if razorPrices[*purchase.RazorID] == nil {
+				razorPrices[*purchase.RazorID] = new(float64)
+			}
+			*razorPrices[*purchase.RazorID] += amount
+			continue
+		}
+		report.BladeSpend += amount
+		month.Blades += amount
+	}
+	report.TotalSpend = report.RazorSpend + report.BladeSpend
+	unitPrices := bladeUnitPrices(purchases, conv)
+
+	for _, month := range months {
+		report.MonthlySpend = append(report.MonthlySpend, *month)
+	}
+	sort.Slice(report.MonthlySpend, func(i, j int) bool {
+		return report.MonthlySpend[i].Month < report.MonthlySpend[j].Month
+	})
+
+	// 按剃须刀分组，保持时间升序
+	timelines := make(map[uint][]model.UsageRecord)
+	for _, record := range records {
+		timelines[record.RazorID] = append(timelines[record.RazorID], record)
+	}
+
+	var usedRazorSpend, pricedBladeSpend float64
+	var usedRazorShaves, pricedBladeShaves int
+	for _, razor := range razors {
+		cost := razorCost(razor, razorPrices[razor.ID], timelines[razor.ID], unitPrices)
+		report.Razors = append(report.Razors, cost.RazorCost)
+		report.TotalShaves += cost.Shaves
+
+		if cost.Price != nil && cost.Shaves > 0 {
+			usedRazorSpend += *cost.Price
+			usedRazorShaves += cost.Shaves
+		}
+		pricedBladeSpend += cost.pricedBladeSpend
+		pricedBladeShaves += cost.pricedBladeShaves
+	}
+	report.RazorCostPerShave = ratio(usedRazorSpend, usedRazorShaves)
+	report.BladeCostPerShave = ratio(pricedBladeSpend, pricedBladeShaves)
+	report.CostPerShave = sumCosts(report.RazorCostPerShave, report.BladeCostPerShave)
+
+	sort.SliceStable(report.Razors, func(i, j int) bool {
+		a, b := report.Razors[i].CostPerShave, report.Razors[j].CostPerShave
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+	report.UnconvertedCurrencies = conv.unconvertedCurrencies()
+
+	return report, nil
+}
+
+// bladeUnitPrices 按基础货币计算各刀片的单价，取各批购买的加权平均。
+// 没有单价或无法折算的购买记录不参与计算
+func bladeUnitPrices(purchases []model.Purchase, conv *currencyConverter) map[uint]float64 {
+	spend := make(map[uint]float64)
+	quantity := make(map[uint]int)
+	for _, purchase := range purchases {
+		if purchase.BladeID == nil || purchase.UnitPrice == nil {
+			continue
+		}
+		amount, ok := conv.convert(*purchase.UnitPrice*float64(purchase.Quantity), purchase.Currency)
+		if !ok {
+			continue
+		}
+		spend[*purchase.BladeID] += amount
+		quantity[*purchase.BladeID] += purchase.Quantity
+	}
+
+	prices := make(map[uint]float64, len(spend))
+	for bladeID, total := range spend {
+		if quantity[bladeID] > 0 {
+			prices[bladeID] = total / float64(quantity[bladeID])
+		}
+	}
+	return prices
+}
+
+// razorCostDetail 单把剃须刀的成本，附带汇总全局时需要的有价刀片数据
+type razorCostDetail struct {
+	model.RazorCost
+	pricedBladeSpend  float64
+	pricedBladeShaves int
+}
+
+// razorCost 计算单把剃须刀的每次剃须成本。
+// 剃须刀价格按使用记录数分摊；每片刀片的单价除以它实际用到的次数，
+// 没有单价（或无法折算）的刀片不参与刀片成本的计算
+func razorCost(razor model.Razor, price *float64, timeline []model.UsageRecord, unitPrices map[uint]float64) razorCostDetail {
+	detail := razorCostDetail{RazorCost: model.RazorCost{
+		RazorID: razor.ID,
+		Brand:   razor.Brand,
+		Model:   razor.Model,
+		Price:   price,
+		Shaves:  len(timeline),
+	}}
+
+	lives := segmentLives(timeline)
+	detail.BladesUsed = len(lives)
+	for _, life := range lives {
+		unit, ok := unitPrices[life.bladeID]
+		if !ok {
+			continue
+		}
+		detail.BladeSpend += unit
+		detail.pricedBladeSpend += unit
+		detail.pricedBladeShaves += life.shaveIndex(len(life.records) - 1)
+	}
+
+	if price != nil {
+		detail.RazorCostPerShave = ratio(*price, detail.Shaves)
+	}
+	detail.BladeCostPerShave = ratio(detail.pricedBladeSpend, detail.pricedBladeShaves)
+	if detail.Shaves > 0 {
+		detail.CostPerShave = sumCosts(detail.RazorCostPerShave, detail.BladeCostPerShave)
+	}
+	return detail
+}
+
+// ratio 计算amount/count，count为0时返回nil
+func ratio(amount float64, count int) *float64 {
+	if count == 0 {
+		return nil
+	}
+	v := amount / float64(count)
+	return &v
+}
+
+// sumCosts 合计分项成本，忽略无法计算的分项，全部无法计算时返回nil
+func sumCosts(costs ...*float64) *float64 {
+	var total *float64
+	for _, c := range costs {
+		if c == nil {
+			continue
+		}
+		if total == nil {
+			total = new(float64)
+		}
+		*total += *c
+	}
+	return total
+}
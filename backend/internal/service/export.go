@@ -0,0 +1,140 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"razor-blade/internal/export"
+	"razor-blade/internal/model"
+	"strings"
+	"time"
+)
+
+// exportBatchSize 导出时每次从存储读取的行数
+const exportBatchSize = 500
+
+// Export 已校验参数的导出任务，写出时分批读取，不把全部数据载入内存
+type Export struct {
+	s        *Service
+	format   string
+	entities []string
+	filter   model.UsageRecordFilter
+	at       time.Time
+}
+
+// NewExport 校验导出参数，参数错误在开始写出之前返回
+func (s *Service) NewExport(req *model.ExportRequest) (*Export, error) {
+	format := req.Format
+	if format == "" {
+		format = export.FormatJSON
+	}
+	entities, err := parseExportEntities(req.Entities)
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseTimeParam("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeParam("to", req.To)
+	if err != nil {
+		return nil, err
+	}
+	return &Export{
+		s:        s,
+		format:   format,
+		entities: entities,
+		filter:   model.UsageRecordFilter{From: from, To: to},
+		at:       time.Now(),
+	}, nil
+}
+
+// parseExportEntities 解析逗号分隔的实体列表，按export.Entities的顺序输出
+func parseExportEntities(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return export.Entities, nil
+	}
+	selected := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		entity := strings.TrimSpace(part)
+		if _, ok := export.Columns[entity]; !ok {
+			return nil, fmt.Errorf("%w: 不支持导出 %q", ErrInvalidParam, entity)
+		}
+		selected[entity] = true
+	}
+	var entities []string
+	for _, entity := range export.Entities {
+		if selected[entity] {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+func (e *Export) ContentType() string {
+	return export.ContentType(e.format, e.entities)
+}
+
+func (e *Export) Filename() string {
+	return export.Filename(e.format, e.entities, e.at)
+}
+
+// Write 写出导出内容，出错时w中可能已有部分内容
+func (e *Export) Write(w io.Writer) error {
+	return export.Write(w, e.format, e.entities, e.rows)
+}
+
+// rows 按ID顺序分批读取剃须刀和刀片，按使用时间顺序分批读取使用记录
+func (e *Export) rows(entity string, fn func(export.Row) error) error {
+	byID := []model.SortField{{Field: "id"}}
+	switch entity {
+	case export.EntityRazors:
+		for offset := 0; ; offset += exportBatchSize {
+			razors, _, err := e.s.repo.GetRazors(model.RazorFilter{Sort: byID}, offset, exportBatchSize)
+			if err != nil {
+				return err
+			}
+			for i := range razors {
+				if err := fn(export.RazorRow(&razors[i])); err != nil {
+					return err
+				}
+			}
+			if len(razors) < exportBatchSize {
+				return nil
+			}
+		}
+	case export.EntityBlades:
+		for offset := 0; ; offset += exportBatchSize {
+			blades, _, err := e.s.repo.GetBlades(model.BladeFilter{Sort: byID}, offset, exportBatchSize)
+			if err != nil {
+				return err
+			}
+			for i := range blades {
+				if err := fn(export.BladeRow(&blades[i])); err != nil {
+					return err
+				}
+			}
+			if len(blades) < exportBatchSize {
+				return nil
+			}
+		}
+	case export.EntityUsageRecords:
+		var cursor model.UsageRecordCursor
+		for {
+			records, err := e.s.repo.GetUsageRecordsByCursor(e.filter, cursor, exportBatchSize)
+			if err != nil {
+				return err
+			}
+			for i := range records {
+				if err := fn(export.UsageRecordRow(&records[i])); err != nil {
+					return err
+				}
+			}
+			if len(records) < exportBatchSize {
+				return nil
+			}
+			last := records[len(records)-1]
+			cursor = model.UsageRecordCursor{UsageTime: last.UsageTime, ID: last.ID}
+		}
+	}
+	return fmt.Errorf("unknown export entity %q", entity)
+}
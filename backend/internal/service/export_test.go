@@ -0,0 +1,241 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"razor-blade/internal/export"
+	"razor-blade/internal/model"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedExportData 写入覆盖各列取值的数据，其中一把剃须刀删除后使ID不连续
+func seedExportData(t *testing.T, s *Service) {
+	t.Helper()
+	at := time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)
+	price := func(v float64) *float64 { return &v }
+	num := func(v int) *int { return &v }
+
+	gone := mustRazor(t, s, "Gone", "Razor")
+	merkur, err := s.CreateRazor(&model.CreateRazorRequest{Brand: "Merkur", Model: "34C", PurchaseDate: &at,
+		Price: price(39.9), Currency: "EUR", Notes: `heavy, "HD" 头`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tech := mustRazor(t, s, "Gillette", "Tech")
+	if err := s.repo.DeleteRazor(gone.ID, model.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	astra, err := s.CreateBlade(&model.CreateBladeRequest{Brand: "Astra", Model: "SP", CompatibleRazorIDs: []uint{merkur.ID, tech.ID},
+		PurchaseDate: &at, UnitPrice: price(0.35), Currency: "USD", TotalQuantity: 10, LowStockThreshold: num(3), Notes: "line1\nline2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feather := mustBlade(t, s, "Feather", "Hi-Stainless", 0, tech.ID)
+
+	for i, req := range []model.CreateUsageRecordRequest{
+		{RazorID: merkur.ID, BladeID: astra.ID, Rating: num(4), ExperienceText: "顺滑", NeedBladeChange: true},
+		{RazorID: merkur.ID, BladeID: astra.ID, ExperienceText: "a,b;c"},
+		{RazorID: tech.ID, BladeID: feather.ID, Rating: num(5), BladeUsageCountOverride: num(3)},
+		{RazorID: merkur.ID, BladeID: astra.ID, Rating: num(2), NeedBladeChange: true},
+	} {
+		req.UsageTime = at.Add(time.Duration(i) * 36 * time.Hour)
+		if _, err := s.CreateUsageRecord(&req); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func exportBytes(t *testing.T, s *Service, format string) []byte {
+	t.Helper()
+	e, err := s.NewExport(&model.ExportRequest{Format: format})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// idMapper 把一个导出中的ID换成另一个存储中对应的ID，nil表示不转换
+type idMapper map[string]map[uint]uint
+
+func newIDMapper(result *model.ImportResult) idMapper {
+	return idMapper{
+		export.EntityRazors:       result.Razors.IDMap,
+		export.EntityBlades:       result.Blades.IDMap,
+		export.EntityUsageRecords: result.UsageRecords.IDMap,
+	}
+}
+
+// value 规范化一个值：时间戳置空，ID按引用的实体转换
+func (m idMapper) value(entity, column, value string) string {
+	switch column {
+	case "created_at", "updated_at":
+		return ""
+	case "id":
+		return m.id(entity, value)
+	case "razor_id":
+		return m.id(export.EntityRazors, value)
+	case "blade_id":
+		return m.id(export.EntityBlades, value)
+	}
+	return value
+}
+
+func (m idMapper) id(entity, value string) string {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || m == nil {
+		return value
+	}
+	mapped, ok := m[entity][uint(id)]
+	if !ok {
+		return "unmapped:" + value
+	}
+	return strconv.FormatUint(uint64(mapped), 10)
+}
+
+var (
+	jsonEntityLine = regexp.MustCompile(`^"(\w+)": \[`)
+	jsonScalar     = regexp.MustCompile(`"(id|razor_id|blade_id|created_at|updated_at)":("[^"]*"|\d+)`)
+	jsonRazorIDs   = regexp.MustCompile(`"compatible_razor_ids":\[([\d,]*)\]`)
+)
+
+// normalizeJSON 逐行替换导出JSON中的ID和时间戳，其余字节保持不变
+func normalizeJSON(t *testing.T, data []byte, m idMapper) string {
+	t.Helper()
+	var out strings.Builder
+	entity := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := jsonEntityLine.FindStringSubmatch(line); match != nil {
+			entity = match[1]
+		}
+		line = jsonScalar.ReplaceAllStringFunc(line, func(field string) string {
+			match := jsonScalar.FindStringSubmatch(field)
+			value := m.value(entity, match[1], strings.Trim(match[2], `"`))
+			if strings.HasPrefix(match[2], `"`) {
+				value = strconv.Quote(value)
+			}
+			return fmt.Sprintf("%q:%s", match[1], value)
+		})
+		line = jsonRazorIDs.ReplaceAllStringFunc(line, func(field string) string {
+			ids := strings.Split(jsonRazorIDs.FindStringSubmatch(field)[1], ",")
+			for i := range ids {
+				ids[i] = m.id(export.EntityRazors, ids[i])
+			}
+			return `"compatible_razor_ids":[` + strings.Join(ids, ",") + "]"
+		})
+		out.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// normalizeZip 逐个文件替换CSV中的ID和时间戳后重新写出，zip本身的修改时间不参与比较
+func normalizeZip(t *testing.T, data []byte, m idMapper) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	for _, f := range zr.File {
+		entity := strings.TrimSuffix(f.Name, ".csv")
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(rc).ReadAll()
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows[1:] {
+			for i, column := range rows[0] {
+				if column == "compatible_razor_ids" && row[i] != "" {
+					ids := strings.Split(row[i], ";")
+					for j := range ids {
+						ids[j] = m.id(export.EntityRazors, ids[j])
+					}
+					row[i] = strings.Join(ids, ";")
+					continue
+				}
+				row[i] = m.value(entity, column, row[i])
+			}
+		}
+		out.WriteString("== " + f.Name + "\n")
+		cw := csv.NewWriter(&out)
+		cw.WriteAll(rows)
+	}
+	return out.String()
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		format    string
+		normalize func(*testing.T, []byte, idMapper) string
+	}{
+		{export.FormatJSON, normalizeJSON},
+		{export.FormatCSV, normalizeZip},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			source := newTestService(t, "")
+			seedExportData(t, source)
+			original := exportBytes(t, source, tc.format)
+
+			// 目标库先写入再删除其他数据，使导入的各实体ID都与原库不同
+			target := newTestService(t, "")
+			placeholder := mustRazor(t, target, "Other", "Razor")
+			mustRazor(t, target, "Another", "Razor")
+			mustRazor(t, target, "Third", "Razor")
+			blade := mustBlade(t, target, "Other", "Blade", 5, placeholder.ID)
+			if _, err := target.CreateUsageRecord(&model.CreateUsageRecordRequest{
+				UsageTime: time.Now(), RazorID: placeholder.ID, BladeID: blade.ID,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			result, err := target.Import(&model.ImportRequest{Format: tc.format}, original)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Errors) > 0 || result.Razors.Created != 2 || result.Blades.Created != 2 || result.UsageRecords.Created != 4 {
+				t.Fatalf("import result = %+v", result)
+			}
+			for _, razor := range []string{"Other", "Another", "Third"} {
+				razors, err := target.repo.GetAllRazors(model.RazorFilter{Brand: razor})
+				if err != nil || len(razors) != 1 {
+					t.Fatalf("find placeholder %s: %v", razor, err)
+				}
+				if err := target.repo.DeleteRazor(razors[0].ID, model.DeleteOptions{Cascade: true}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := target.repo.DeleteBlade(blade.ID, model.DeleteOptions{Cascade: true}); err != nil {
+				t.Fatal(err)
+			}
+			roundTrip := exportBytes(t, target, tc.format)
+
+			want := tc.normalize(t, original, newIDMapper(result))
+			got := tc.normalize(t, roundTrip, nil)
+			if strings.Contains(want, "unmapped:") {
+				t.Fatalf("original export references IDs missing from the import result:\n%s", want)
+			}
+			if got != want {
+				t.Errorf("re-export differs from original\n--- original\n%s\n--- re-export\n%s", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"math"
+	"razor-blade/internal/model"
+	"sort"
+	"time"
+)
+
+// forecastZ 90%双侧置信区间对应的正态分位数
+const (
+	forecastConfidence = 0.9
+	forecastZ          = 1.645
+)
+
+// GetBladeForecast 根据近期换刀频率预测各刀片的耗尽日期和建议补货日期。
+// 换刀次数按泊松过程估计消耗速度，置信区间用正态近似
+func (s *Service) GetBladeForecast(req *model.BladeForecastRequest) (*model.BladeForecastReport, error) {
+	windowDays := s.cfg.Inventory.ForecastWindowDays
+	if req.WindowDays != nil {
+		windowDays = *req.WindowDays
+	}
+	if windowDays <= 0 {
+		windowDays = 90
+	}
+	leadTimeDays := s.cfg.Inventory.ReorderLeadTimeDays
+	if req.LeadTimeDays != nil {
+		leadTimeDays = *req.LeadTimeDays
+	}
+
+	now := time.Now()
+	windowStart := now.AddDate(0, 0, -windowDays)
+
+	blades, err := s.repo.GetAllBlades(model.BladeFilter{})
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.repo.GetAllUsageRecords(model.UsageRecordFilter{From: &windowStart, To: &now})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[uint]int)
+	for _, record := range records {
+		if record.NeedBladeChange {
+			changes[record.BladeID]++
+		}
+	}
+
+	report := &model.BladeForecastReport{
+		GeneratedAt:     now,
+		WindowDays:      windowDays,
+		LeadTimeDays:    leadTimeDays,
+		ConfidenceLevel: forecastConfidence,
+		Items:           make([]model.BladeForecast, 0, len(blades)),
+	}
+	for _, blade := range blades {
+		// 窗口内才开始使用的刀片按实际使用天数计算速度，至少按1天
+		observedDays := float64(windowDays)
+		if changes[blade.ID] > 0 {
+			first, err := s.firstUsageTime(blade.ID)
+			if err != nil {
+				return nil, err
+			}
+			if first.After(windowStart) {
+				observedDays = math.Max(now.Sub(first).Hours()/24, 1)
+			}
+		}
+		item := forecastBlade(blade, changes[blade.ID], observedDays, now)
+		if item.RunOutEarliest != nil {
+			reorder := item.RunOutEarliest.AddDate(0, 0, -leadTimeDays)
+			item.ReorderDate = &reorder
+			item.ReorderNow = !reorder.After(now)
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	// 越早耗尽越靠前，无法预测的排在最后
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i].RunOutDate, report.Items[j].RunOutDate
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+	return report, nil
+}
+
+// firstUsageTime 返回刀片最早的使用时间，不受预测窗口限制
+func (s *Service) firstUsageTime(bladeID uint) (time.Time, error) {
+	records, _, err := s.repo.GetUsageRecords(model.UsageRecordFilter{
+		BladeID: bladeID,
+		Sort:    []model.SortField{{Field: "usage_time"}, {Field: "id"}},
+	}, 0, 1)
+	if err != nil || len(records) == 0 {
+		return time.Time{}, err
+	}
+	return records[0].UsageTime, nil
+}
+
+// forecastBlade 按观察期内的换刀次数估算单个刀片的耗尽时间
+func forecastBlade(blade model.Blade, changes int, observedDays float64, now time.Time) model.BladeForecast {
+	item := model.BladeForecast{
+		BladeID:           blade.ID,
+		Brand:             blade.Brand,
+		Model:             blade.Model,
+		RemainingQuantity: blade.RemainingQuantity,
+		ChangesInWindow:   changes,
+		DailyRate:         float64(changes) / observedDays,
+	}
+	if blade.RemainingQuantity <= 0 {
+		// 已经用完
+		item.RunOutDate, item.RunOutEarliest, item.RunOutLatest = &now, &now, &now
+		return item
+	}
+	if changes == 0 {
+		return item
+	}
+
+	n := float64(changes)
+	remaining := float64(blade.RemainingQuantity)
+	runOut := now.Add(daysToDuration(remaining / item.DailyRate))
+	item.RunOutDate = &runOut
+
+	// 速度越快越早耗尽：速度上界对应最早耗尽，下界对应最晚耗尽
+	highRate := (n + forecastZ*math.Sqrt(n)) / observedDays
+	earliest := now.Add(daysToDuration(remaining / highRate))
+	item.RunOutEarliest = &earliest
+	if lowRate := (n - forecastZ*math.Sqrt(n)) / observedDays; lowRate > 0 {
+		latest := now.Add(daysToDuration(remaining / lowRate))
+		item.RunOutLatest = &latest
+	}
+	return item
+}
+
+// daysToDuration 天数转为Duration，超过100年按100年计，避免溢出
+func daysToDuration(days float64) time.Duration {
+	return time.Duration(math.Min(days, 36500) * float64(24*time.Hour))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"math"
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+func TestGetBladeForecastWindowedRate(t *testing.T) {
+	s := newTestService(t, "")
+	razor := mustRazor(t, s, "Merkur", "34C")
+	// 窗口开始前就在使用，窗口内第一次换刀在60天前
+	veteran := mustBlade(t, s, "Astra", "SP", 20, razor.ID)
+	// 10天前才开始使用
+	newcomer := mustBlade(t, s, "Feather", "Pro", 20, razor.ID)
+	// 窗口内没有换刀
+	idle := mustBlade(t, s, "Derby", "Extra", 5, razor.ID)
+
+	now := time.Now().UTC()
+	daysAgo := func(d float64) time.Time { return now.Add(-time.Duration(d * float64(24*time.Hour))) }
+	changeAt := func(bladeID uint, at time.Time) {
+		t.Helper()
+		_, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{
+			UsageTime: at, RazorID: razor.ID, BladeID: bladeID, NeedBladeChange: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	changeAt(veteran.ID, daysAgo(200))
+	for i := 0; i < 9; i++ {
+		changeAt(veteran.ID, daysAgo(60-float64(i)))
+	}
+	for i := 0; i < 5; i++ {
+		changeAt(newcomer.ID, daysAgo(10-float64(i)))
+	}
+
+	window, lead := 90, 7
+	report, err := s.GetBladeForecast(&model.BladeForecastRequest{WindowDays: &window, LeadTimeDays: &lead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make(map[uint]model.BladeForecast)
+	for _, item := range report.Items {
+		items[item.BladeID] = item
+	}
+
+	for _, tc := range []struct {
+		name       string
+		bladeID    uint
+		changes    int
+		rate       float64 // 每天换刀次数
+		runOutDays float64 // 剩余数量 / 速度
+	}{
+		// 9次 / 90天，剩余 20-10=10
+		{"veteran", veteran.ID, 9, 0.1, 100},
+		// 5次 / 10天，剩余 20-5=15
+		{"newcomer", newcomer.ID, 5, 0.5, 30},
+	} {
+		item := items[tc.bladeID]
+		if item.ChangesInWindow != tc.changes {
+			t.Errorf("%s: changes = %d, want %d", tc.name, item.ChangesInWindow, tc.changes)
+		}
+		if math.Abs(item.DailyRate-tc.rate) > 1e-3 {
+			t.Errorf("%s: daily rate = %v, want %v", tc.name, item.DailyRate, tc.rate)
+		}
+		if item.RunOutDate == nil {
+			t.Fatalf("%s: no run-out date", tc.name)
+		}
+		want := now.Add(time.Duration(tc.runOutDays * float64(24*time.Hour)))
+		if d := item.RunOutDate.Sub(want); d < -time.Hour || d > time.Hour {
+			t.Errorf("%s: run out %v, want about %v", tc.name, item.RunOutDate, want)
+		}
+		if item.ReorderDate == nil || !item.ReorderDate.Equal(item.RunOutEarliest.AddDate(0, 0, -lead)) {
+			t.Errorf("%s: reorder date %v, earliest run out %v", tc.name, item.ReorderDate, item.RunOutEarliest)
+		}
+	}
+
+	if item := items[idle.ID]; item.DailyRate != 0 || item.RunOutDate != nil {
+		t.Errorf("idle: rate %v, run out %v", item.DailyRate, item.RunOutDate)
+	}
+	// 按耗尽时间排序，无法预测的在最后
+	if len(report.Items) != 3 || report.Items[0].BladeID != newcomer.ID || report.Items[2].BladeID != idle.ID {
+		t.Errorf("order = %+v", report.Items)
+	}
+}
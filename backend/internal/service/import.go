@@ -0,0 +1,473 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"razor-blade/internal/export"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// errImportRejected 写入某一行失败，事务已回滚，错误记录在结果中
+	errImportRejected = errors.New("import rejected")
+	// errImportDryRun 预演结束，回滚事务
+	errImportDryRun = errors.New("import dry run")
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Za-z]{3}$`)
+
+type importRazor struct {
+	line     int
+	sourceID uint
+	req      model.CreateRazorRequest
+}
+
+type importBlade struct {
+	line     int
+	sourceID uint
+	razorIDs []uint // 文件中的剃须刀ID，写入时映射
+	req      model.CreateBladeRequest
+}
+
+type importUsageRecord struct {
+	line     int
+	sourceID uint
+	req      model.CreateUsageRecordRequest // RazorID和BladeID为文件中的ID，写入时映射
+}
+
+// Import 导入与导出格式相同的数据，全部行通过校验后在一个事务内写入，任一行失败则全部不写入。
+// 剃须刀和刀片按品牌和型号（忽略大小写）匹配已有数据，匹配到时不新建也不修改；使用记录总是新建。
+// 文件中引用的剃须刀和刀片ID优先对应文件中的行，其次对应当前用户已有的数据
+func (s *Service) Import(req *model.ImportRequest, data []byte) (*model.ImportResult, error) {
+	format := req.Format
+	if format == "" {
+		format = export.FormatJSON
+	}
+	records, err := export.Decode(data, format, req.Entity)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParam, err.Error())
+	}
+
+	result := &model.ImportResult{
+		DryRun:       req.DryRun,
+		Razors:       model.ImportEntityResult{IDMap: map[uint]uint{}},
+		Blades:       model.ImportEntityResult{IDMap: map[uint]uint{}},
+		UsageRecords: model.ImportEntityResult{IDMap: map[uint]uint{}},
+		Errors:       []model.ImportRowError{},
+	}
+	razors := parseImportRazors(records[export.EntityRazors], result)
+	blades := parseImportBlades(records[export.EntityBlades], result)
+	usageRecords := parseImportUsageRecords(records[export.EntityUsageRecords], result)
+
+	existingRazors, err := s.repo.GetAllRazors(model.RazorFilter{})
+	if err != nil {
+		return nil, err
+	}
+	existingBlades, err := s.repo.GetAllBlades(model.BladeFilter{})
+	if err != nil {
+		return nil, err
+	}
+	checkImportReferences(razors, blades, usageRecords, existingRazors, existingBlades, result)
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	var changed []uint
+	err = s.repo.Transaction(func(repo repository.Store) error {
+		// 库存告警在提交后统一检查
+		tx := &Service{repo: repo, cfg: s.cfg}
+		var err error
+		if changed, err = tx.applyImport(razors, blades, usageRecords, existingRazors, existingBlades, result); err != nil {
+			return err
+		}
+		if req.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errImportRejected):
+		// 已回滚，写入到一半的统计没有意义
+		result.Razors = model.ImportEntityResult{IDMap: map[uint]uint{}}
+		result.Blades = model.ImportEntityResult{IDMap: map[uint]uint{}}
+		result.UsageRecords = model.ImportEntityResult{IDMap: map[uint]uint{}}
+		return result, nil
+	case errors.Is(err, errImportDryRun):
+		return result, nil
+	case err != nil:
+		return nil, err
+	}
+	s.evaluateStock(changed...)
+	return result, nil
+}
+
+// applyImport 依次写入剃须刀、刀片和使用记录，返回库存可能变化的刀片
+func (s *Service) applyImport(razors []importRazor, blades []importBlade, usageRecords []importUsageRecord,
+	existingRazors []model.Razor, existingBlades []model.Blade, result *model.ImportResult) ([]uint, error) {
+	reject := func(entity string, line int, err error) error {
+		result.Errors = append(result.Errors, model.ImportRowError{Entity: entity, Line: line, Error: err.Error()})
+		return errImportRejected
+	}
+
+	razorKeys := make(map[string]uint)
+	for _, razor := range existingRazors {
+		razorKeys[importKey(razor.Brand, razor.Model)] = razor.ID
+	}
+	for _, row := range razors {
+		key := importKey(row.req.Brand, row.req.Model)
+		id, ok := razorKeys[key]
+		if ok {
+			result.Razors.Matched++
+		} else {
+			razor, err := s.CreateRazor(&row.req)
+			if err != nil {
+				return nil, reject(export.EntityRazors, row.line, err)
+			}
+			id = razor.ID
+			razorKeys[key] = id
+			result.Razors.Created++
+		}
+		if row.sourceID != 0 {
+			result.Razors.IDMap[row.sourceID] = id
+		}
+	}
+	razorID := func(sourceID uint) uint {
+		if id, ok := result.Razors.IDMap[sourceID]; ok {
+			return id
+		}
+		return sourceID
+	}
+
+	bladeKeys := make(map[string]uint)
+	for _, blade := range existingBlades {
+		bladeKeys[importKey(blade.Brand, blade.Model)] = blade.ID
+	}
+	var changed []uint
+	for _, row := range blades {
+		key := importKey(row.req.Brand, row.req.Model)
+		id, ok := bladeKeys[key]
+		if ok {
+			result.Blades.Matched++
+		} else {
+			row.req.CompatibleRazorIDs = make([]uint, len(row.razorIDs))
+			for i, sourceID := range row.razorIDs {
+				row.req.CompatibleRazorIDs[i] = razorID(sourceID)
+			}
+			blade, err := s.CreateBlade(&row.req)
+			if err != nil {
+				return nil, reject(export.EntityBlades, row.line, err)
+			}
+			id = blade.ID
+			bladeKeys[key] = id
+			changed = append(changed, id)
+			result.Blades.Created++
+		}
+		if row.sourceID != 0 {
+			result.Blades.IDMap[row.sourceID] = id
+		}
+	}
+	bladeID := func(sourceID uint) uint {
+		if id, ok := result.Blades.IDMap[sourceID]; ok {
+			return id
+		}
+		return sourceID
+	}
+
+	// 按使用时间顺序写入，新记录的ID与时间顺序一致
+	sort.SliceStable(usageRecords, func(i, j int) bool {
+		return usageRecords[i].req.UsageTime.Before(usageRecords[j].req.UsageTime)
+	})
+	for _, row := range usageRecords {
+		row.req.RazorID = razorID(row.req.RazorID)
+		row.req.BladeID = bladeID(row.req.BladeID)
+		record, err := s.CreateUsageRecord(&row.req)
+		if err != nil {
+			return nil, reject(export.EntityUsageRecords, row.line, err)
+		}
+		if row.req.NeedBladeChange {
+			changed = append(changed, record.BladeID)
+		}
+		if row.sourceID != 0 {
+			result.UsageRecords.IDMap[row.sourceID] = record.ID
+		}
+		result.UsageRecords.Created++
+	}
+	return changed, nil
+}
+
+// checkImportReferences 检查兼容列表和使用记录引用的ID在文件或已有数据中存在
+func checkImportReferences(razors []importRazor, blades []importBlade, usageRecords []importUsageRecord,
+	existingRazors []model.Razor, existingBlades []model.Blade, result *model.ImportResult) {
+	razorIDs := make(map[uint]bool)
+	for _, razor := range existingRazors {
+		razorIDs[razor.ID] = true
+	}
+	for _, row := range razors {
+		if row.sourceID != 0 {
+			razorIDs[row.sourceID] = true
+		}
+	}
+	bladeIDs := make(map[uint]bool)
+	for _, blade := range existingBlades {
+		bladeIDs[blade.ID] = true
+	}
+	for _, row := range blades {
+		if row.sourceID != 0 {
+			bladeIDs[row.sourceID] = true
+		}
+	}
+
+	for _, row := range blades {
+		for _, id := range row.razorIDs {
+			if !razorIDs[id] {
+				addImportError(result, export.EntityBlades, row.line, fmt.Sprintf("compatible_razor_ids: 剃须刀 %d 不在文件中也不存在", id))
+			}
+		}
+	}
+	for _, row := range usageRecords {
+		if !razorIDs[row.req.RazorID] {
+			addImportError(result, export.EntityUsageRecords, row.line, fmt.Sprintf("razor_id: 剃须刀 %d 不在文件中也不存在", row.req.RazorID))
+		}
+		if !bladeIDs[row.req.BladeID] {
+			addImportError(result, export.EntityUsageRecords, row.line, fmt.Sprintf("blade_id: 刀片 %d 不在文件中也不存在", row.req.BladeID))
+		}
+	}
+}
+
+func importKey(brand, model string) string {
+	return strings.ToLower(strings.TrimSpace(brand)) + "\x00" + strings.ToLower(strings.TrimSpace(model))
+}
+
+func addImportError(result *model.ImportResult, entity string, line int, message string) {
+	result.Errors = append(result.Errors, model.ImportRowError{Entity: entity, Line: line, Error: message})
+}
+
+func parseImportRazors(records []export.Record, result *model.ImportResult) []importRazor {
+	var rows []importRazor
+	seen := make(map[uint]bool)
+	for _, record := range records {
+		p := &rowParser{values: record.Values}
+		row := importRazor{line: record.Line, sourceID: p.id("id")}
+		row.req = model.CreateRazorRequest{
+			Brand:        p.required("brand"),
+			Model:        p.required("model"),
+			PurchaseDate: p.time("purchase_date"),
+			Price:        p.amount("price"),
+			Currency:     p.currency("currency"),
+			Notes:        p.text("notes"),
+		}
+		p.unique(seen, row.sourceID)
+		if p.report(result, export.EntityRazors, record.Line) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func parseImportBlades(records []export.Record, result *model.ImportResult) []importBlade {
+	var rows []importBlade
+	seen := make(map[uint]bool)
+	for _, record := range records {
+		p := &rowParser{values: record.Values}
+		row := importBlade{line: record.Line, sourceID: p.id("id"), razorIDs: p.ids("compatible_razor_ids")}
+		row.req = model.CreateBladeRequest{
+			Brand:             p.required("brand"),
+			Model:             p.required("model"),
+			PurchaseDate:      p.time("purchase_date"),
+			UnitPrice:         p.amount("unit_price"),
+			Currency:          p.currency("currency"),
+			TotalQuantity:     p.count("total_quantity"),
+			LowStockThreshold: p.number("low_stock_threshold", 0),
+			Notes:             p.text("notes"),
+		}
+		p.unique(seen, row.sourceID)
+		if p.report(result, export.EntityBlades, record.Line) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func parseImportUsageRecords(records []export.Record, result *model.ImportResult) []importUsageRecord {
+	var rows []importUsageRecord
+	seen := make(map[uint]bool)
+	for _, record := range records {
+		p := &rowParser{values: record.Values}
+		row := importUsageRecord{line: record.Line, sourceID: p.id("id")}
+		usageTime := p.time("usage_time")
+		if usageTime == nil && p.text("usage_time") == "" {
+			p.fail("usage_time 不能为空")
+		}
+		row.req = model.CreateUsageRecordRequest{
+			RazorID:                 p.requiredID("razor_id"),
+			BladeID:                 p.requiredID("blade_id"),
+			BladeUsageCountOverride: p.number("blade_usage_count_override", 1),
+			Rating:                  p.number("rating", 1),
+			ExperienceText:          p.text("experience_text"),
+			NeedBladeChange:         p.bool("need_blade_change"),
+		}
+		if usageTime != nil {
+			row.req.UsageTime = *usageTime
+		}
+		// 评分范围与列表筛选一致
+		if row.req.Rating != nil && *row.req.Rating > 5 {
+			p.fail("rating 应为1到5的整数")
+		}
+		p.unique(seen, row.sourceID)
+		if p.report(result, export.EntityUsageRecords, record.Line) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// rowParser 按列解析一行，记录全部不合法的列而不是在第一个错误处停止。
+// 校验规则与对应的创建接口相同
+type rowParser struct {
+	values map[string]string
+	errs   []string
+}
+
+func (p *rowParser) fail(message string) {
+	p.errs = append(p.errs, message)
+}
+
+// report 把错误记入结果，返回该行是否合法
+func (p *rowParser) report(result *model.ImportResult, entity string, line int) bool {
+	if len(p.errs) == 0 {
+		return true
+	}
+	addImportError(result, entity, line, strings.Join(p.errs, "; "))
+	return false
+}
+
+func (p *rowParser) text(column string) string {
+	return p.values[column]
+}
+
+func (p *rowParser) required(column string) string {
+	value := strings.TrimSpace(p.values[column])
+	if value == "" {
+		p.fail(column + " 不能为空")
+	}
+	return value
+}
+
+func (p *rowParser) id(column string) uint {
+	raw := strings.TrimSpace(p.values[column])
+	if raw == "" {
+		return 0
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		p.fail(column + " 应为正整数")
+		return 0
+	}
+	return uint(id)
+}
+
+func (p *rowParser) requiredID(column string) uint {
+	if strings.TrimSpace(p.values[column]) == "" {
+		p.fail(column + " 不能为空")
+		return 0
+	}
+	return p.id(column)
+}
+
+func (p *rowParser) ids(column string) []uint {
+	raw := strings.TrimSpace(p.values[column])
+	if raw == "" {
+		return nil
+	}
+	var ids []uint
+	for _, part := range strings.Split(raw, ";") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || id == 0 {
+			p.fail(column + " 应为分号分隔的正整数")
+			return nil
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// unique 同一实体中文件ID不能重复
+func (p *rowParser) unique(seen map[uint]bool, id uint) {
+	if id == 0 {
+		return
+	}
+	if seen[id] {
+		p.fail(fmt.Sprintf("id %d 重复", id))
+	}
+	seen[id] = true
+}
+
+func (p *rowParser) time(column string) *time.Time {
+	raw := strings.TrimSpace(p.values[column])
+	t, err := parseTimeParam(column, raw)
+	if err != nil {
+		p.fail(column + " 应为RFC3339或YYYY-MM-DD格式")
+		return nil
+	}
+	return t
+}
+
+func (p *rowParser) amount(column string) *float64 {
+	raw := strings.TrimSpace(p.values[column])
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 {
+		p.fail(column + " 应为不小于0的数字")
+		return nil
+	}
+	return &value
+}
+
+func (p *rowParser) currency(column string) string {
+	raw := strings.TrimSpace(p.values[column])
+	if raw != "" && !currencyPattern.MatchString(raw) {
+		p.fail(column + " 应为3位字母的货币代码")
+	}
+	return raw
+}
+
+// number 解析可为空的整数，不能小于min
+func (p *rowParser) number(column string, min int) *int {
+	raw := strings.TrimSpace(p.values[column])
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min {
+		p.fail(fmt.Sprintf("%s 应为不小于%d的整数", column, min))
+		return nil
+	}
+	return &value
+}
+
+func (p *rowParser) count(column string) int {
+	if value := p.number(column, 0); value != nil {
+		return *value
+	}
+	return 0
+}
+
+func (p *rowParser) bool(column string) bool {
+	raw := strings.TrimSpace(p.values[column])
+	if raw == "" {
+		return false
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		p.fail(column + " 应为true或false")
+	}
+	return value
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"razor-blade/internal/export"
+	"razor-blade/internal/model"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// assertNothingImported 要求存储中仍只有导入前的数据
+func assertNothingImported(t *testing.T, s *Service, razors, blades, records int) {
+	t.Helper()
+	gotRazors, _ := s.repo.GetAllRazors(model.RazorFilter{})
+	gotBlades, _ := s.repo.GetAllBlades(model.BladeFilter{})
+	gotRecords, _ := s.repo.GetAllUsageRecords(model.UsageRecordFilter{})
+	if len(gotRazors) != razors || len(gotBlades) != blades || len(gotRecords) != records {
+		t.Errorf("stored %d razors, %d blades, %d records, want %d, %d, %d",
+			len(gotRazors), len(gotBlades), len(gotRecords), razors, blades, records)
+	}
+}
+
+// importErrors 把错误整理为 实体:行号:错误 便于比较
+func importErrors(result *model.ImportResult) []string {
+	var list []string
+	for _, e := range result.Errors {
+		list = append(list, e.Entity+":"+strconv.Itoa(e.Line)+":"+e.Error)
+	}
+	return list
+}
+
+func TestImportRejectsBadReferences(t *testing.T) {
+	s := newTestService(t, "")
+	existing := mustRazor(t, s, "Merkur", "34C")
+
+	data := `{
+		"razors": [{"id": 1, "brand": "Gillette", "model": "Tech"}],
+		"blades": [
+			{"id": 10, "brand": "Astra", "model": "SP", "total_quantity": 5, "compatible_razor_ids": [1, ` + strconv.FormatUint(uint64(existing.ID), 10) + `]},
+			{"id": 11, "brand": "Feather", "model": "Pro", "compatible_razor_ids": [77]}
+		],
+		"usage_records": [
+			{"id": 1, "usage_time": "2024-03-01T07:00:00Z", "razor_id": 1, "blade_id": 10},
+			{"id": 2, "usage_time": "2024-03-02T07:00:00Z", "razor_id": 99, "blade_id": 98}
+		]
+	}`
+	result, err := s.Import(&model.ImportRequest{}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := importErrors(result)
+	want := []string{
+		"blades:2:compatible_razor_ids: 剃须刀 77 不在文件中也不存在",
+		"usage_records:2:razor_id: 剃须刀 99 不在文件中也不存在",
+		"usage_records:2:blade_id: 刀片 98 不在文件中也不存在",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	assertNothingImported(t, s, 1, 0, 0)
+}
+
+func TestImportReportsEveryInvalidColumn(t *testing.T) {
+	s := newTestService(t, "")
+	data := `{
+		"razors": [{"id": 1, "brand": "", "model": "Tech", "currency": "EURO"}],
+		"usage_records": [{"id": 1, "usage_time": "yesterday", "razor_id": 1, "blade_id": 0, "rating": 7}]
+	}`
+	result, err := s.Import(&model.ImportRequest{}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每行一条错误，列出该行全部不合法的列
+	if len(result.Errors) != 2 {
+		t.Fatalf("errors = %v, want one per row", importErrors(result))
+	}
+	for _, tc := range []struct {
+		entity  string
+		columns []string
+	}{
+		{export.EntityRazors, []string{"brand", "currency"}},
+		{export.EntityUsageRecords, []string{"usage_time", "blade_id", "rating"}},
+	} {
+		var found *model.ImportRowError
+		for i := range result.Errors {
+			if result.Errors[i].Entity == tc.entity {
+				found = &result.Errors[i]
+			}
+		}
+		if found == nil || found.Line != 1 {
+			t.Errorf("%s: error = %+v", tc.entity, found)
+			continue
+		}
+		for _, column := range tc.columns {
+			if !strings.Contains(found.Error, column) {
+				t.Errorf("%s: error %q does not mention %s", tc.entity, found.Error, column)
+			}
+		}
+	}
+	assertNothingImported(t, s, 0, 0, 0)
+
+	if _, err := s.Import(&model.ImportRequest{}, []byte(`{"razors": [`)); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("malformed json: err = %v, want ErrInvalidParam", err)
+	}
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	s := newTestService(t, "")
+	mustRazor(t, s, "Merkur", "34C")
+	data := `{
+		"razors": [{"id": 1, "brand": "merkur", "model": "34c"}, {"id": 2, "brand": "Gillette", "model": "Tech"}],
+		"blades": [{"id": 10, "brand": "Astra", "model": "SP", "total_quantity": 5, "compatible_razor_ids": [1, 2]}],
+		"usage_records": [{"id": 1, "usage_time": "2024-03-01T07:00:00Z", "razor_id": 2, "blade_id": 10, "need_blade_change": true}]
+	}`
+	result, err := s.Import(&model.ImportRequest{DryRun: true}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.DryRun || len(result.Errors) != 0 {
+		t.Fatalf("dry run result = %+v", result)
+	}
+	// 预演仍然报告会新建和匹配的数量
+	if result.Razors.Matched != 1 || result.Razors.Created != 1 || result.Blades.Created != 1 || result.UsageRecords.Created != 1 {
+		t.Errorf("dry run counts: razors %+v, blades %+v, records %+v", result.Razors, result.Blades, result.UsageRecords)
+	}
+	assertNothingImported(t, s, 1, 0, 0)
+
+	if result, err = s.Import(&model.ImportRequest{}, []byte(data)); err != nil || len(result.Errors) != 0 {
+		t.Fatalf("import after dry run: %+v, %v", result, err)
+	}
+	assertNothingImported(t, s, 2, 1, 1)
+}
+
+func TestImportRollsBackOnFailedRow(t *testing.T) {
+	s := newTestService(t, "")
+	// 第二条记录换刀时刀片已没有库存，只有写入时才能发现
+	data := `{
+		"razors": [{"id": 1, "brand": "Gillette", "model": "Tech"}],
+		"blades": [{"id": 10, "brand": "Astra", "model": "SP", "total_quantity": 1}],
+		"usage_records": [
+			{"id": 1, "usage_time": "2024-03-01T07:00:00Z", "razor_id": 1, "blade_id": 10, "need_blade_change": true},
+			{"id": 2, "usage_time": "2024-03-02T07:00:00Z", "razor_id": 1, "blade_id": 10, "need_blade_change": true}
+		]
+	}`
+	result, err := s.Import(&model.ImportRequest{}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Entity != export.EntityUsageRecords || result.Errors[0].Line != 2 ||
+		!strings.Contains(result.Errors[0].Error, ErrInsufficientStock.Error()) {
+		t.Errorf("errors = %v, want insufficient stock on usage_records line 2", importErrors(result))
+	}
+	if result.Razors.Created != 0 || result.Blades.Created != 0 || result.UsageRecords.Created != 0 || len(result.Razors.IDMap) != 0 {
+		t.Errorf("counts after rollback: razors %+v, blades %+v, records %+v", result.Razors, result.Blades, result.UsageRecords)
+	}
+	assertNothingImported(t, s, 0, 0, 0)
+}
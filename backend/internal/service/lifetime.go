@@ -0,0 +1,216 @@
+package service
+
+import (
+	"razor-blade/internal/model"
+	"sort"
+)
+
+// defaultRatingThreshold 评分下降判定的默认阈值
+const defaultRatingThreshold = 3.0
+
+// bladeLife 分段过程中的一段刀片寿命
+type bladeLife struct {
+	razorID   uint
+	bladeID   uint
+	records   []model.UsageRecord
+	completed bool
+}
+
+// shaveIndex 返回第i条记录是该刀片的第几次使用。
+// 以首条记录的BladeUsageCount为起点，兼容从中途开始记录的刀片
+func (l *bladeLife) shaveIndex(i int) int {
+	start := l.records[0].BladeUsageCount
+	if start < 1 {
+		start = 1
+	}
+	return start + i
+}
+
+// segmentLives 将同一剃须刀按时间升序排列的使用记录切分为刀片寿命。
+// 标记NeedBladeChange的记录是当前刀片的最后一次使用；
+// 中途换用其他刀片也视为旧刀片已换下。最后一段若未标记换刀则仍在使用中
+func segmentLives(records []model.UsageRecord) []*bladeLife {
+	var lives []*bladeLife
+	var current *bladeLife
+	for _, record := range records {
+		if current != nil && current.bladeID != record.BladeID {
+			current.completed = true
+			current = nil
+		}
+		if current == nil {
+			current = &bladeLife{razorID: record.RazorID, bladeID: record.BladeID}
+			lives = append(lives, current)
+		}
+		current.records = append(current.records, record)
+		if record.NeedBladeChange {
+			current.completed = true
+			current = nil
+		}
+	}
+	return lives
+}
+
+// razorUsageTimeline 返回某剃须刀按时间升序排列的全部使用记录
+func (s *Service) razorUsageTimeline(razorID uint) ([]model.UsageRecord, error) {
+	return s.repo.GetAllUsageRecords(model.UsageRecordFilter{
+		RazorID: razorID,
+		Sort:    []model.SortField{{Field: "usage_time"}, {Field: "id"}},
+	})
+}
+
+// GetBladeLifetime 分析刀片每片能用多少次以及评分从第几次开始下降
+func (s *Service) GetBladeLifetime(bladeID uint, req *model.BladeLifetimeRequest) (*model.BladeLifetime, error) {
+	if _, err := s.repo.GetBladeByID(bladeID); err != nil {
+		return nil, translateRepoError(err)
+	}
+	threshold := defaultRatingThreshold
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+
+	// 寿命按剃须刀切分，需要用到这些剃须刀上其他刀片的记录
+	bladeRecords, err := s.repo.GetAllUsageRecords(model.UsageRecordFilter{BladeID: bladeID})
+	if err != nil {
+		return nil, err
+	}
+	razorIDs := make(map[uint]bool)
+	for _, record := range bladeRecords {
+		razorIDs[record.RazorID] = true
+	}
+
+	var lives []*bladeLife
+	for razorID := range razorIDs {
+		timeline, err := s.razorUsageTimeline(razorID)
+		if err != nil {
+			return nil, err
+		}
+		for _, life := range segmentLives(timeline) {
+			if life.bladeID == bladeID {
+				lives = append(lives, life)
+			}
+		}
+	}
+	sort.Slice(lives, func(i, j int) bool {
+		return lives[i].records[0].UsageTime.Before(lives[j].records[0].UsageTime)
+	})
+
+	return summarizeLives(bladeID, lives, threshold), nil
+}
+
+// summarizeLives 汇总寿命分段，计算平均/中位使用次数和各次剃须的平均评分
+func summarizeLives(bladeID uint, lives []*bladeLife, threshold float64) *model.BladeLifetime {
+	result := &model.BladeLifetime{
+		BladeID:         bladeID,
+		RatingThreshold: threshold,
+		RatingByShave:   []model.ShaveRating{},
+		Lives:           make([]model.BladeLife, 0, len(lives)),
+	}
+
+	var shaveCounts []int
+	ratingSums := make(map[int]float64)
+	ratingCounts := make(map[int]int)
+	maxIndex := 0
+	for _, life := range lives {
+		var sum float64
+		var rated int
+		for i, record := range life.records {
+			idx := life.shaveIndex(i)
+			if idx > maxIndex {
+				maxIndex = idx
+			}
+			if record.Rating != nil {
+				sum += float64(*record.Rating)
+				rated++
+				ratingSums[idx] += float64(*record.Rating)
+				ratingCounts[idx]++
+			}
+		}
+
+		shaves := life.shaveIndex(len(life.records) - 1)
+		if life.completed {
+			shaveCounts = append(shaveCounts, shaves)
+		}
+		result.Lives = append(result.Lives, model.BladeLife{
+			RazorID:       life.razorID,
+			Start:         life.records[0].UsageTime,
+			End:           life.records[len(life.records)-1].UsageTime,
+			Shaves:        shaves,
+			AverageRating: average(sum, rated),
+			Completed:     life.completed,
+		})
+	}
+
+	result.CompletedLives = len(shaveCounts)
+	if len(shaveCounts) > 0 {
+		total := 0
+		for _, n := range shaveCounts {
+			total += n
+		}
+		avg := float64(total) / float64(len(shaveCounts))
+		result.AverageShaves = &avg
+		result.MedianShaves = median(shaveCounts)
+	}
+
+	for idx := 1; idx <= maxIndex; idx++ {
+		avg := average(ratingSums[idx], ratingCounts[idx])
+		result.RatingByShave = append(result.RatingByShave, model.ShaveRating{
+			ShaveIndex:    idx,
+			AverageRating: avg,
+			Samples:       ratingCounts[idx],
+		})
+		if result.DropShave == nil && avg != nil && *avg < threshold {
+			drop := idx
+			result.DropShave = &drop
+		}
+	}
+	return result
+}
+
+// average 计算平均值，没有样本时返回nil
+func average(sum float64, count int) *float64 {
+	if count == 0 {
+		return nil
+	}
+	avg := sum / float64(count)
+	return &avg
+}
+
+// median 计算中位数，偶数个时取中间两个的平均值
+func median(values []int) *float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	m := float64(sorted[mid])
+	if len(sorted)%2 == 0 {
+		m = float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return &m
+}
+
+// GetMountedBlade 根据最近一条使用记录推算剃须刀当前装着的刀片和下一次的使用次数
+func (s *Service) GetMountedBlade(razorID uint) (*model.MountedBlade, error) {
+	if _, err := s.repo.GetRazorByID(razorID); err != nil {
+		return nil, translateRepoError(err)
+	}
+	timeline, err := s.razorUsageTimeline(razorID)
+	if err != nil {
+		return nil, err
+	}
+
+	mounted := &model.MountedBlade{RazorID: razorID, NextShaveIndex: 1}
+	if len(timeline) == 0 {
+		return mounted, nil
+	}
+	last := timeline[len(timeline)-1]
+	blade, err := s.repo.GetBladeByID(last.BladeID)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	mounted.Blade = blade
+	mounted.LastUsedAt = &last.UsageTime
+	if !last.NeedBladeChange {
+		mounted.ShaveCount = last.BladeUsageCount
+		mounted.NextShaveIndex = last.BladeUsageCount + 1
+	}
+	return mounted, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+func TestGetBladeLifetime(t *testing.T) {
+	s := newTestService(t, "")
+	first := mustRazor(t, s, "Merkur", "34C")
+	second := mustRazor(t, s, "Gillette", "Tech")
+	astra := mustBlade(t, s, "Astra", "SP", 10)
+	feather := mustBlade(t, s, "Feather", "Pro", 10)
+
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	use := func(razor *model.Razor, blade *model.Blade, rating int, change bool) {
+		t.Helper()
+		at = at.Add(24 * time.Hour)
+		_, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{
+			UsageTime: at, RazorID: razor.ID, BladeID: blade.ID, Rating: &rating, NeedBladeChange: change,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 第一片：3次后换刀
+	use(first, astra, 5, false)
+	use(first, astra, 4, false)
+	use(first, astra, 2, true)
+	// 第二片：5次后换刀
+	use(first, astra, 5, false)
+	use(first, astra, 5, false)
+	use(first, astra, 4, false)
+	use(first, astra, 3, false)
+	use(first, astra, 2, true)
+	// 第三片在另一把剃须刀上，用了4次后改用其他刀片，视为已换下
+	use(second, astra, 5, false)
+	use(second, astra, 4, false)
+	use(second, astra, 3, false)
+	use(second, astra, 2, false)
+	use(second, feather, 5, false)
+	// 第四片仍在使用中，不计入平均次数
+	use(first, astra, 5, false)
+	use(first, astra, 5, false)
+
+	lifetime, err := s.GetBladeLifetime(astra.ID, &model.BladeLifetimeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lifetime.CompletedLives != 3 || len(lifetime.Lives) != 4 {
+		t.Fatalf("completed %d of %d lives, want 3 of 4", lifetime.CompletedLives, len(lifetime.Lives))
+	}
+	wantShaves := []int{3, 5, 4, 2}
+	wantRazors := []uint{first.ID, first.ID, second.ID, first.ID}
+	for i, life := range lifetime.Lives {
+		if life.Shaves != wantShaves[i] || life.RazorID != wantRazors[i] || life.Completed != (i < 3) {
+			t.Errorf("life %d = %+v, want %d shaves on razor %d", i, life, wantShaves[i], wantRazors[i])
+		}
+	}
+	if lifetime.AverageShaves == nil || *lifetime.AverageShaves != 4 || lifetime.MedianShaves == nil || *lifetime.MedianShaves != 4 {
+		t.Errorf("average %v, median %v, want 4 and 4", lifetime.AverageShaves, lifetime.MedianShaves)
+	}
+
+	// 各次平均评分：5, 4.5, 3, 2.5, 2
+	wantRatings := []float64{5, 4.5, 3, 2.5, 2}
+	if len(lifetime.RatingByShave) != len(wantRatings) {
+		t.Fatalf("rating by shave = %d entries, want %d", len(lifetime.RatingByShave), len(wantRatings))
+	}
+	for i, r := range lifetime.RatingByShave {
+		if r.ShaveIndex != i+1 || r.AverageRating == nil || *r.AverageRating != wantRatings[i] {
+			t.Errorf("shave %d rating = %v, want %v", r.ShaveIndex, r.AverageRating, wantRatings[i])
+		}
+	}
+	// 默认阈值3，等于3不算下降
+	if lifetime.DropShave == nil || *lifetime.DropShave != 4 {
+		t.Errorf("drop shave = %v, want 4", lifetime.DropShave)
+	}
+	threshold := 3.5
+	lifetime, err = s.GetBladeLifetime(astra.ID, &model.BladeLifetimeRequest{Threshold: &threshold})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lifetime.DropShave == nil || *lifetime.DropShave != 3 {
+		t.Errorf("drop shave at 3.5 = %v, want 3", lifetime.DropShave)
+	}
+
+	// 没有使用记录的刀片
+	empty := mustBlade(t, s, "Derby", "Extra", 1)
+	lifetime, err = s.GetBladeLifetime(empty.ID, &model.BladeLifetimeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lifetime.CompletedLives != 0 || lifetime.AverageShaves != nil || lifetime.DropShave != nil || len(lifetime.Lives) != 0 {
+		t.Errorf("empty blade lifetime = %+v", lifetime)
+	}
+
+	mounted, err := s.GetMountedBlade(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mounted.Blade == nil || mounted.Blade.ID != astra.ID || mounted.ShaveCount != 2 || mounted.NextShaveIndex != 3 {
+		t.Errorf("mounted on first razor = %+v", mounted)
+	}
+}
+
+func TestGetBladeLifetimeStartsFromOverride(t *testing.T) {
+	s := newTestService(t, "")
+	razor := mustRazor(t, s, "Merkur", "34C")
+	blade := mustBlade(t, s, "Astra", "SP", 5)
+
+	// 从第3次才开始记录的刀片
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	start := 3
+	for i, change := range []bool{false, true} {
+		req := &model.CreateUsageRecordRequest{UsageTime: at.Add(time.Duration(i) * time.Hour), RazorID: razor.ID, BladeID: blade.ID, NeedBladeChange: change}
+		if i == 0 {
+			req.BladeUsageCountOverride = &start
+		}
+		if _, err := s.CreateUsageRecord(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lifetime, err := s.GetBladeLifetime(blade.ID, &model.BladeLifetimeRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lifetime.Lives) != 1 || lifetime.Lives[0].Shaves != 4 || lifetime.AverageShaves == nil || *lifetime.AverageShaves != 4 {
+		t.Errorf("lives = %+v, average %v, want one life of 4 shaves", lifetime.Lives, lifetime.AverageShaves)
+	}
+	// 前两次没有记录，不计入样本
+	if len(lifetime.RatingByShave) != 4 || lifetime.RatingByShave[0].Samples != 0 {
+		t.Errorf("rating by shave = %+v", lifetime.RatingByShave)
+	}
+}
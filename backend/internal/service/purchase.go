@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"strings"
+	"time"
+)
+
+// newPurchase 创建购买记录，未填写日期时使用当前时间
+func newPurchase(date *time.Time, quantity int, unitPrice *float64, currency string) model.Purchase {
+	purchaseDate := time.Now()
+	if date != nil {
+		purchaseDate = *date
+	}
+	return model.Purchase{
+		// 统一以UTC存储，保证SQLite中按文本比较时间的查询结果正确
+		PurchaseDate: purchaseDate.UTC(),
+		Quantity:     quantity,
+		UnitPrice:    unitPrice,
+		Currency:     strings.ToUpper(currency),
+	}
+}
+
+// translatePurchaseError 购买记录写入失败时的错误信息
+func translatePurchaseError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrPurchaseNotFound):
+		return notFoundError("购买记录不存在")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return fmt.Errorf("%w：该批刀片已被使用，剩余数量不足以扣除", ErrInsufficientStock)
+	}
+	return translateRepoError(err)
+}
+
+func (s *Service) CreatePurchase(req *model.CreatePurchaseRequest) (*model.Purchase, error) {
+	if (req.RazorID == nil) == (req.BladeID == nil) {
+		return nil, fmt.Errorf("%w: razor_id 和 blade_id 必须且只能填写一个", ErrInvalidParam)
+	}
+
+	purchase := newPurchase(req.PurchaseDate, req.Quantity, req.UnitPrice, req.Currency)
+	purchase.RazorID = req.RazorID
+	purchase.BladeID = req.BladeID
+	purchase.Vendor = req.Vendor
+	purchase.Notes = req.Notes
+
+	if err := s.repo.CreatePurchase(&purchase); err != nil {
+		return nil, translatePurchaseError(err)
+	}
+	if purchase.BladeID != nil {
+		s.evaluateStock(*purchase.BladeID)
+	}
+
+	return &purchase, nil
+}
+
+func (s *Service) GetPurchaseByID(id uint) (*model.Purchase, error) {
+	purchase, err := s.repo.GetPurchaseByID(id)
+	if err != nil {
+		return nil, translatePurchaseError(err)
+	}
+	return purchase, nil
+}
+
+func (s *Service) GetPurchases(req *model.PurchaseListRequest) (*model.PaginationResponse, error) {
+	sorts, err := parseSort(req.Sort, purchaseSortFields)
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseTimeParam("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeParam("to", req.To)
+	if err != nil {
+		return nil, err
+	}
+	filter := model.PurchaseFilter{
+		RazorID:  req.RazorID,
+		BladeID:  req.BladeID,
+		ItemType: req.ItemType,
+		Vendor:   req.Vendor,
+		From:     from,
+		To:       to,
+		Sort:     sorts,
+	}
+
+	offset := normalizePage(&req.PaginationRequest)
+	purchases, total, err := s.repo.GetPurchases(filter, offset, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginationResponse{
+		Items:      purchases,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.PageSize))),
+	}, nil
+}
+
+func (s *Service) UpdatePurchase(id uint, req *model.UpdatePurchaseRequest) (*model.Purchase, error) {
+	purchase, err := s.repo.GetPurchaseByID(id)
+	if err != nil {
+		return nil, translatePurchaseError(err)
+	}
+
+	if req.PurchaseDate != nil {
+		purchase.PurchaseDate = req.PurchaseDate.UTC()
+	}
+	if req.Quantity != 0 {
+		purchase.Quantity = req.Quantity
+	}
+	if req.UnitPrice != nil {
+		purchase.UnitPrice = req.UnitPrice
+	}
+	if req.Currency != "" {
+		purchase.Currency = strings.ToUpper(req.Currency)
+	}
+	purchase.Vendor = req.Vendor
+	purchase.Notes = req.Notes
+
+	if err := s.repo.UpdatePurchase(purchase); err != nil {
+		return nil, translatePurchaseError(err)
+	}
+	if purchase.BladeID != nil {
+		s.evaluateStock(*purchase.BladeID)
+	}
+
+	return purchase, nil
+}
+
+func (s *Service) DeletePurchase(id uint) error {
+	purchase, err := s.repo.GetPurchaseByID(id)
+	if err != nil {
+		return translatePurchaseError(err)
+	}
+	if err := s.repo.DeletePurchase(id); err != nil {
+		return translatePurchaseError(err)
+	}
+	if purchase.BladeID != nil {
+		s.evaluateStock(*purchase.BladeID)
+	}
+	return nil
+}
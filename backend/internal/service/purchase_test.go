@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+func TestPurchaseAdjustsStock(t *testing.T) {
+	s := newTestService(t, "")
+	razor := mustRazor(t, s, "Merkur", "34C")
+	blade := mustBlade(t, s, "Astra", "SP", 0, razor.ID)
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+
+	assertStock := func(step string, total, remaining int) {
+		t.Helper()
+		got, err := s.GetBladeByID(blade.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalQuantity != total || got.RemainingQuantity != remaining {
+			t.Errorf("%s: stock %d/%d, want %d/%d", step, got.RemainingQuantity, got.TotalQuantity, remaining, total)
+		}
+	}
+
+	first, err := s.CreatePurchase(&model.CreatePurchaseRequest{BladeID: &blade.ID, PurchaseDate: &at, Quantity: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStock("purchase 10", 10, 10)
+	for i := 0; i < 3; i++ {
+		_, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{
+			UsageTime: at.Add(time.Duration(i+1) * time.Hour), RazorID: razor.ID, BladeID: blade.ID, NeedBladeChange: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertStock("3 changes", 10, 7)
+
+	second, err := s.CreatePurchase(&model.CreatePurchaseRequest{BladeID: &blade.ID, PurchaseDate: &at, Quantity: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStock("purchase 5", 15, 12)
+
+	if _, err := s.UpdatePurchase(first.ID, &model.UpdatePurchaseRequest{Quantity: 6}); err != nil {
+		t.Fatal(err)
+	}
+	assertStock("edit 10 to 6", 11, 8)
+
+	// 只改日期和备注不影响库存
+	later := at.Add(24 * time.Hour)
+	if _, err := s.UpdatePurchase(second.ID, &model.UpdatePurchaseRequest{PurchaseDate: &later, Notes: "gift"}); err != nil {
+		t.Fatal(err)
+	}
+	assertStock("edit date", 11, 8)
+
+	if err := s.DeletePurchase(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertStock("delete 5", 6, 3)
+
+	// 已换过3片，只剩2片的购买记录会使剩余数量为负
+	if _, err := s.UpdatePurchase(first.ID, &model.UpdatePurchaseRequest{Quantity: 2}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("edit 6 to 2: err = %v, want ErrInsufficientStock", err)
+	}
+	assertStock("rejected edit", 6, 3)
+	if got, _ := s.GetPurchaseByID(first.ID); got.Quantity != 6 {
+		t.Errorf("quantity after rejected edit = %d, want 6", got.Quantity)
+	}
+	if err := s.DeletePurchase(first.ID); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("delete used purchase: err = %v, want ErrInsufficientStock", err)
+	}
+	assertStock("rejected delete", 6, 3)
+}
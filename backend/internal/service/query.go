@@ -0,0 +1,115 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"razor-blade/internal/model"
+	"strings"
+	"time"
+)
+
+// ErrInvalidParam 查询参数不合法
+var ErrInvalidParam = errors.New("参数错误")
+
+// 各列表允许排序的字段白名单
+var (
+	razorSortFields = map[string]bool{
+		"id": true, "brand": true, "model": true, "purchase_date": true,
+		"price": true, "created_at": true, "updated_at": true,
+	}
+	bladeSortFields = map[string]bool{
+		"id": true, "brand": true, "model": true, "purchase_date": true, "unit_price": true,
+		"total_quantity": true, "remaining_quantity": true, "created_at": true, "updated_at": true,
+	}
+	usageRecordSortFields = map[string]bool{
+		"id": true, "usage_time": true, "rating": true, "blade_usage_count": true,
+		"created_at": true, "updated_at": true,
+	}
+	purchaseSortFields = map[string]bool{
+		"id": true, "purchase_date": true, "quantity": true, "unit_price": true,
+		"vendor": true, "created_at": true, "updated_at": true,
+	}
+)
+
+// parseSort 解析 field:asc|desc 形式的排序参数，多个字段以逗号分隔
+func parseSort(raw string, allowed map[string]bool) ([]model.SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var sorts []model.SortField
+	for _, part := range strings.Split(raw, ",") {
+		field, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if !allowed[field] {
+			return nil, fmt.Errorf("%w: 不支持按 %q 排序", ErrInvalidParam, field)
+		}
+		switch strings.ToLower(direction) {
+		case "", "asc":
+			sorts = append(sorts, model.SortField{Field: field})
+		case "desc":
+			sorts = append(sorts, model.SortField{Field: field, Desc: true})
+		default:
+			return nil, fmt.Errorf("%w: 排序方向 %q 应为 asc 或 desc", ErrInvalidParam, direction)
+		}
+	}
+	return sorts, nil
+}
+
+// parseTimeParam 解析RFC3339或YYYY-MM-DD格式的时间参数，空字符串返回nil
+func parseTimeParam(name, raw string) (*time.Time, error) {
+	return parseTimeParamIn(name, raw, time.Local)
+}
+
+// parseTimeParamIn 同parseTimeParam，YYYY-MM-DD按loc时区解析
+func parseTimeParamIn(name, raw string, loc *time.Location) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, loc); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%w: %s 应为RFC3339或YYYY-MM-DD格式", ErrInvalidParam, name)
+}
+
+// normalizePage 填充分页默认值并返回offset
+func normalizePage(req *model.PaginationRequest) int {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 10
+	}
+	return (req.Page - 1) * req.PageSize
+}
+
+// cursorPayload 游标的序列化格式，对客户端不透明
+type cursorPayload struct {
+	UsageTime time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	Desc      bool      `json:"d,omitempty"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// encodeCursor 将分页位置编码为URL安全的字符串
+func encodeCursor(c model.UsageRecordCursor) string {
+	data, _ := json.Marshal(cursorPayload{UsageTime: c.UsageTime.UTC(), ID: c.ID, Desc: c.Desc, Backward: c.Backward})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解析客户端回传的游标
+func decodeCursor(raw string) (*model.UsageRecordCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的cursor", ErrInvalidParam)
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(data, &p); err != nil || p.ID == 0 {
+		return nil, fmt.Errorf("%w: 无效的cursor", ErrInvalidParam)
+	}
+	return &model.UsageRecordCursor{UsageTime: p.UsageTime, ID: p.ID, Desc: p.Desc, Backward: p.Backward}, nil
+}
@@ -2,29 +2,71 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"razor-blade/internal/alert"
+	"razor-blade/internal/config"
 	"razor-blade/internal/model"
 	"razor-blade/internal/repository"
-
-	"gorm.io/gorm"
 )
 
+// ErrIncompatibleBlade 剃须刀与刀片未声明兼容（reject策略）
+var ErrIncompatibleBlade = errors.New("该刀片未声明兼容此剃须刀")
+
+// ErrInsufficientStock 换刀或修改购买记录后刀片剩余数量会小于0
+var ErrInsufficientStock = errors.New("刀片库存不足")
+
+// ErrNotFound 要操作的数据不存在或属于其他用户
+var ErrNotFound = errors.New("数据不存在")
+
+// notFoundError 保留具体的提示信息，errors.Is判断为ErrNotFound
+type notFoundError string
+
+func (e notFoundError) Error() string { return string(e) }
+
+func (e notFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// DependencyError 删除的剃须刀或刀片仍被引用且未指定处理方式，Report列出引用方
+type DependencyError struct {
+	Report *model.DependencyReport
+}
+
+func (e *DependencyError) Error() string {
+	hint := "可指定 cascade=true 一起移入回收站或 reassign_to 转移使用记录"
+	if e.Report.Entity == model.AuditEntityRazor {
+		return fmt.Sprintf("剃须刀仍有%d条使用记录和%d个兼容刀片，%s",
+			len(e.Report.UsageRecordIDs), len(e.Report.CompatibleBladeIDs), hint)
+	}
+	return fmt.Sprintf("刀片仍有%d条使用记录，%s", len(e.Report.UsageRecordIDs), hint)
+}
+
 type Service struct {
-	repo *repository.Repository
+	repo     repository.Store
+	cfg      *config.Config
+	alerts   *alert.Evaluator // 为nil时不检查库存告警
+	sessions *memorySessions  // 数据库只读时保存登录会话，否则为nil
 }
 
-func NewService(repo *repository.Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo repository.Store, cfg *config.Config, alerts *alert.Evaluator) *Service {
+	s := &Service{repo: repo, cfg: cfg, alerts: alerts}
+	if cfg.Database.ReadOnly {
+		s.sessions = newMemorySessions()
+	}
+	return s
 }
 
 // Razor服务方法
 func (s *Service) CreateRazor(req *model.CreateRazorRequest) (*model.Razor, error) {
 	razor := &model.Razor{
-		Brand:        req.Brand,
-		Model:        req.Model,
-		PurchaseDate: req.PurchaseDate,
-		Price:        req.Price,
-		Notes:        req.Notes,
+		Brand: req.Brand,
+		Model: req.Model,
+		Notes: req.Notes,
+	}
+	// 购买信息转为首条购买记录
+	if req.PurchaseDate != nil || req.Price != nil {
+		razor.Purchases = []model.Purchase{
+			newPurchase(req.PurchaseDate, 1, req.Price, req.Currency),
+		}
 	}
 
 	if err := s.repo.CreateRazor(razor); err != nil {
@@ -35,19 +77,27 @@ func (s *Service) CreateRazor(req *model.CreateRazorRequest) (*model.Razor, erro
 }
 
 func (s *Service) GetRazorByID(id uint) (*model.Razor, error) {
-	return s.repo.GetRazorByID(id)
+	razor, err := s.repo.GetRazorByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	return razor, nil
 }
 
-func (s *Service) GetRazors(req *model.PaginationRequest) (*model.PaginationResponse, error) {
-	if req.Page == 0 {
-		req.Page = 1
+func (s *Service) GetRazors(req *model.RazorListRequest) (*model.PaginationResponse, error) {
+	sorts, err := parseSort(req.Sort, razorSortFields)
+	if err != nil {
+		return nil, err
 	}
-	if req.PageSize == 0 {
-		req.PageSize = 10
+	filter := model.RazorFilter{
+		Brand: req.Brand,
+		Model: req.Model,
+		Query: req.Q,
+		Sort:  sorts,
 	}
 
-	offset := (req.Page - 1) * req.PageSize
-	razors, total, err := s.repo.GetRazors(offset, req.PageSize)
+	offset := normalizePage(&req.PaginationRequest)
+	razors, total, err := s.repo.GetRazors(filter, offset, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -66,10 +116,7 @@ func (s *Service) GetRazors(req *model.PaginationRequest) (*model.PaginationResp
 func (s *Service) UpdateRazor(id uint, req *model.UpdateRazorRequest) (*model.Razor, error) {
 	razor, err := s.repo.GetRazorByID(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("剃须刀不存在")
-		}
-		return nil, err
+		return nil, translateRepoError(err)
 	}
 
 	if req.Brand != "" {
@@ -78,68 +125,94 @@ func (s *Service) UpdateRazor(id uint, req *model.UpdateRazorRequest) (*model.Ra
 	if req.Model != "" {
 		razor.Model = req.Model
 	}
-	if req.PurchaseDate != nil {
-		razor.PurchaseDate = req.PurchaseDate
-	}
-	if req.Price != nil {
-		razor.Price = req.Price
-	}
 	razor.Notes = req.Notes
 
 	if err := s.repo.UpdateRazor(razor); err != nil {
-		return nil, err
+		return nil, translateRepoError(err)
 	}
 
 	return razor, nil
 }
 
-func (s *Service) DeleteRazor(id uint) error {
-	// 检查是否存在使用记录
-	_, err := s.repo.GetRazorByID(id)
+// DeleteRazor 删除剃须刀，仍有使用记录或兼容刀片时需要指定级联删除或转移，否则返回*DependencyError
+func (s *Service) DeleteRazor(id uint, req *model.DeleteRequest) error {
+	opts, err := deleteOptions(id, req)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("剃须刀不存在")
-		}
 		return err
 	}
+	// 级联删除的使用记录归还换过的刀片库存
+	var blades []uint
+	if opts.Cascade {
+		blades = s.changedBlades(model.UsageRecordFilter{RazorID: id})
+	}
+	if err := s.repo.DeleteRazor(id, opts); err != nil {
+		return translateRepoError(err)
+	}
+	s.evaluateStock(blades...)
+	return nil
+}
 
-	return s.repo.DeleteRazor(id)
+// deleteOptions 校验删除时依赖的处理方式
+func deleteOptions(id uint, req *model.DeleteRequest) (model.DeleteOptions, error) {
+	if req.Cascade && req.ReassignTo != 0 {
+		return model.DeleteOptions{}, fmt.Errorf("%w: cascade 和 reassign_to 不能同时指定", ErrInvalidParam)
+	}
+	if req.ReassignTo == id {
+		return model.DeleteOptions{}, fmt.Errorf("%w: 不能转移到被删除的数据自身", ErrInvalidParam)
+	}
+	return model.DeleteOptions{Cascade: req.Cascade, ReassignTo: req.ReassignTo}, nil
 }
 
 // Blade服务方法
 func (s *Service) CreateBlade(req *model.CreateBladeRequest) (*model.Blade, error) {
 	blade := &model.Blade{
-		Brand:             req.Brand,
-		Model:             req.Model,
-		CompatibleRazors:  req.CompatibleRazors,
-		PurchaseDate:      req.PurchaseDate,
-		UnitPrice:         req.UnitPrice,
-		TotalQuantity:     req.TotalQuantity,
-		RemainingQuantity: req.RemainingQuantity,
-		Notes:             req.Notes,
+		Brand:              req.Brand,
+		Model:              req.Model,
+		CompatibleRazorIDs: req.CompatibleRazorIDs,
+		LowStockThreshold:  req.LowStockThreshold,
+		Notes:              req.Notes,
+	}
+	// 购买信息转为首条购买记录，库存由购买记录累加
+	if req.TotalQuantity > 0 {
+		blade.Purchases = []model.Purchase{
+			newPurchase(req.PurchaseDate, req.TotalQuantity, req.UnitPrice, req.Currency),
+		}
 	}
 
 	if err := s.repo.CreateBlade(blade); err != nil {
-		return nil, err
+		return nil, translateBladeError(err)
 	}
+	s.evaluateStock(blade.ID)
 
 	return blade, nil
 }
 
 func (s *Service) GetBladeByID(id uint) (*model.Blade, error) {
-	return s.repo.GetBladeByID(id)
+	blade, err := s.repo.GetBladeByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	return blade, nil
 }
 
-func (s *Service) GetBlades(req *model.PaginationRequest) (*model.PaginationResponse, error) {
-	if req.Page == 0 {
-		req.Page = 1
+func (s *Service) GetBlades(req *model.BladeListRequest) (*model.PaginationResponse, error) {
+	sorts, err := parseSort(req.Sort, bladeSortFields)
+	if err != nil {
+		return nil, err
 	}
-	if req.PageSize == 0 {
-		req.PageSize = 10
+	filter := model.BladeFilter{
+		Brand: req.Brand,
+		Model: req.Model,
+		Query: req.Q,
+		Sort:  sorts,
+	}
+	if req.LowStock {
+		threshold := s.cfg.Inventory.LowStockThreshold
+		filter.LowStockThreshold = &threshold
 	}
 
-	offset := (req.Page - 1) * req.PageSize
-	blades, total, err := s.repo.GetBlades(offset, req.PageSize)
+	offset := normalizePage(&req.PaginationRequest)
+	blades, total, err := s.repo.GetBlades(filter, offset, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -158,10 +231,7 @@ func (s *Service) GetBlades(req *model.PaginationRequest) (*model.PaginationResp
 func (s *Service) UpdateBlade(id uint, req *model.UpdateBladeRequest) (*model.Blade, error) {
 	blade, err := s.repo.GetBladeByID(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("刀片不存在")
-		}
-		return nil, err
+		return nil, translateRepoError(err)
 	}
 
 	if req.Brand != "" {
@@ -170,102 +240,177 @@ func (s *Service) UpdateBlade(id uint, req *model.UpdateBladeRequest) (*model.Bl
 	if req.Model != "" {
 		blade.Model = req.Model
 	}
-	blade.CompatibleRazors = req.CompatibleRazors
-	if req.PurchaseDate != nil {
-		blade.PurchaseDate = req.PurchaseDate
+	if req.CompatibleRazorIDs != nil {
+		blade.CompatibleRazorIDs = req.CompatibleRazorIDs
 	}
-	if req.UnitPrice != nil {
-		blade.UnitPrice = req.UnitPrice
+	if req.ClearLowStockThreshold {
+		blade.LowStockThreshold = nil
+	} else if req.LowStockThreshold != nil {
+		blade.LowStockThreshold = req.LowStockThreshold
 	}
-	blade.TotalQuantity = req.TotalQuantity
-	blade.RemainingQuantity = req.RemainingQuantity
 	blade.Notes = req.Notes
 
 	if err := s.repo.UpdateBlade(blade); err != nil {
-		return nil, err
+		return nil, translateBladeError(err)
 	}
+	s.evaluateStock(blade.ID)
 
 	return blade, nil
 }
 
-func (s *Service) DeleteBlade(id uint) error {
-	_, err := s.repo.GetBladeByID(id)
+// GetCompatibleBlades 获取声明兼容指定剃须刀的刀片
+func (s *Service) GetCompatibleBlades(razorID uint) ([]model.Blade, error) {
+	blades, err := s.repo.GetCompatibleBlades(razorID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("刀片不存在")
-		}
-		return err
+		return nil, translateRepoError(err)
 	}
-
-	return s.repo.DeleteBlade(id)
+	return blades, nil
 }
 
-// UsageRecord服务方法
-func (s *Service) CreateUsageRecord(req *model.CreateUsageRecordRequest) (*model.UsageRecord, error) {
-	// 验证剃须刀和刀片是否存在
-	_, err := s.repo.GetRazorByID(req.RazorID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("剃须刀不存在")
-		}
-		return nil, err
+// 刀片写入时ErrRazorNotFound指向的是兼容列表中的剃须刀
+func translateBladeError(err error) error {
+	if errors.Is(err, repository.ErrRazorNotFound) {
+		return fmt.Errorf("%w: 兼容的剃须刀不存在", ErrInvalidParam)
 	}
+	return translateRepoError(err)
+}
 
-	blade, err := s.repo.GetBladeByID(req.BladeID)
+// DeleteBlade 删除刀片，仍有使用记录时需要指定级联删除或转移，否则返回*DependencyError
+func (s *Service) DeleteBlade(id uint, req *model.DeleteRequest) error {
+	opts, err := deleteOptions(id, req)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("刀片不存在")
-		}
-		return nil, err
+		return err
 	}
-
-	// 如果需要更换刀片，检查库存并减少数量
-	if req.NeedBladeChange {
-		if blade.RemainingQuantity <= 0 {
-			return nil, errors.New("刀片库存不足，无法更换")
-		}
-		// 减少刀片库存
-		blade.RemainingQuantity--
-		if err := s.repo.UpdateBlade(blade); err != nil {
-			return nil, errors.New("更新刀片库存失败")
-		}
+	if err := s.repo.DeleteBlade(id, opts); err != nil {
+		return translateRepoError(err)
 	}
+	s.evaluateStock(opts.ReassignTo)
+	return nil
+}
 
+// UsageRecord服务方法
+func (s *Service) CreateUsageRecord(req *model.CreateUsageRecordRequest) (*model.UsageRecord, error) {
 	record := &model.UsageRecord{
-		UsageTime:       req.UsageTime,
+		// 统一以UTC存储，保证SQLite中按文本比较时间的查询结果正确
+		UsageTime:       req.UsageTime.UTC(),
 		RazorID:         req.RazorID,
 		BladeID:         req.BladeID,
-		BladeUsageCount: req.BladeUsageCount,
 		Rating:          req.Rating,
 		ExperienceText:  req.ExperienceText,
 		NeedBladeChange: req.NeedBladeChange,
+		// 使用次数由仓储层在写入时按该剃须刀的换刀记录计算
+		BladeUsageCountOverride: req.BladeUsageCountOverride,
 	}
 
-	if record.BladeUsageCount == 0 {
-		record.BladeUsageCount = 1
+	warnings, err := s.checkCompatibility(record.RazorID, record.BladeID)
+	if err != nil {
+		return nil, err
 	}
 
+	// 校验剃须刀和刀片、扣减库存、写入记录在仓储层的同一事务内完成
 	if err := s.repo.CreateUsageRecord(record); err != nil {
+		return nil, translateUsageReferenceError(err)
+	}
+	s.evaluateStock(record.BladeID)
+
+	created, err := s.repo.GetUsageRecordByID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	created.Warnings = warnings
+	return created, nil
+}
+
+// checkCompatibility 按配置的策略检查剃须刀与刀片是否声明兼容，
+// warn模式下返回提示信息，reject模式下返回错误
+func (s *Service) checkCompatibility(razorID, bladeID uint) ([]string, error) {
+	if s.cfg.Usage.CompatibilityCheck == config.CompatibilityCheckOff {
+		return nil, nil
+	}
+
+	compatible, err := s.repo.IsBladeCompatible(razorID, bladeID)
+	if err != nil {
 		return nil, err
 	}
+	if compatible {
+		return nil, nil
+	}
 
-	return s.repo.GetUsageRecordByID(record.ID)
+	if s.cfg.Usage.CompatibilityCheck == config.CompatibilityCheckReject {
+		return nil, ErrIncompatibleBlade
+	}
+	return []string{"该刀片未声明兼容此剃须刀"}, nil
+}
+
+// 将仓储层的错误转换为面向用户的提示
+func translateRepoError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrRazorNotFound):
+		return notFoundError("剃须刀不存在")
+	case errors.Is(err, repository.ErrBladeNotFound):
+		return notFoundError("刀片不存在")
+	case errors.Is(err, repository.ErrUsageRecordNotFound):
+		return notFoundError("使用记录不存在")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return fmt.Errorf("%w，无法更换", ErrInsufficientStock)
+	case errors.Is(err, repository.ErrReassignTargetNotFound):
+		return fmt.Errorf("%w: 转移的目标不存在", ErrInvalidParam)
+	}
+	var dep *repository.DependencyError
+	if errors.As(err, &dep) {
+		return &DependencyError{Report: dep.Report}
+	}
+	return err
+}
+
+// translateUsageReferenceError 使用记录引用的剃须刀或刀片不存在属于请求参数错误
+func translateUsageReferenceError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrRazorNotFound):
+		return fmt.Errorf("%w: 剃须刀不存在", ErrInvalidParam)
+	case errors.Is(err, repository.ErrBladeNotFound):
+		return fmt.Errorf("%w: 刀片不存在", ErrInvalidParam)
+	}
+	return translateRepoError(err)
 }
 
 func (s *Service) GetUsageRecordByID(id uint) (*model.UsageRecord, error) {
-	return s.repo.GetUsageRecordByID(id)
+	record, err := s.repo.GetUsageRecordByID(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	return record, nil
 }
 
-func (s *Service) GetUsageRecords(req *model.PaginationRequest) (*model.PaginationResponse, error) {
-	if req.Page == 0 {
-		req.Page = 1
+func (s *Service) GetUsageRecords(req *model.UsageRecordListRequest) (*model.PaginationResponse, error) {
+	sorts, err := parseSort(req.Sort, usageRecordSortFields)
+	if err != nil {
+		return nil, err
 	}
-	if req.PageSize == 0 {
-		req.PageSize = 10
+	from, err := parseTimeParam("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeParam("to", req.To)
+	if err != nil {
+		return nil, err
+	}
+	filter := model.UsageRecordFilter{
+		RazorID:   req.RazorID,
+		BladeID:   req.BladeID,
+		Query:     req.Q,
+		RatingMin: req.RatingMin,
+		RatingMax: req.RatingMax,
+		From:      from,
+		To:        to,
+		Sort:      sorts,
+	}
+	if req.Cursor != "" || req.Limit > 0 {
+		return s.getUsageRecordsByCursor(req, filter)
 	}
 
-	offset := (req.Page - 1) * req.PageSize
-	records, total, err := s.repo.GetUsageRecords(offset, req.PageSize)
+	offset := normalizePage(&req.PaginationRequest)
+	records, total, err := s.repo.GetUsageRecords(filter, offset, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -281,40 +426,113 @@ func (s *Service) GetUsageRecords(req *model.PaginationRequest) (*model.Paginati
 	}, nil
 }
 
+// getUsageRecordsByCursor 键集分页，不计算总数，适合翻阅期间有新记录插入的场景
+func (s *Service) getUsageRecordsByCursor(req *model.UsageRecordListRequest, filter model.UsageRecordFilter) (*model.PaginationResponse, error) {
+	cursor := model.UsageRecordCursor{Desc: true}
+	if req.Cursor != "" {
+		decoded, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = *decoded
+	} else if len(filter.Sort) > 0 {
+		// 游标模式只支持按使用时间排序
+		if len(filter.Sort) > 1 || filter.Sort[0].Field != "usage_time" {
+			return nil, fmt.Errorf("%w: 游标分页只支持按 usage_time 排序", ErrInvalidParam)
+		}
+		cursor.Desc = filter.Sort[0].Desc
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
+	}
+	// 多取一条用于判断是否还有更多
+	records, err := s.repo.GetUsageRecordsByCursor(filter, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(records) > limit
+	if hasMore {
+		if cursor.Backward {
+			records = records[1:]
+		} else {
+			records = records[:limit]
+		}
+	}
+
+	resp := &model.PaginationResponse{Items: records, PageSize: limit}
+	if len(records) == 0 {
+		return resp, nil
+	}
+	first, last := records[0], records[len(records)-1]
+	// 向后翻时，来时的方向一定还有上一页；向前翻时同理有下一页
+	if hasMore || cursor.Backward {
+		resp.NextCursor = encodeCursor(model.UsageRecordCursor{UsageTime: last.UsageTime, ID: last.ID, Desc: cursor.Desc})
+	}
+	if (hasMore && cursor.Backward) || (!cursor.Backward && cursor.ID != 0) {
+		resp.PrevCursor = encodeCursor(model.UsageRecordCursor{UsageTime: first.UsageTime, ID: first.ID, Desc: cursor.Desc, Backward: true})
+	}
+	return resp, nil
+}
+
 func (s *Service) UpdateUsageRecord(id uint, req *model.UpdateUsageRecordRequest) (*model.UsageRecord, error) {
 	record, err := s.repo.GetUsageRecordByID(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("使用记录不存在")
+		return nil, translateRepoError(err)
+	}
+
+	// 只在更换了剃须刀或刀片时检查兼容性，未改动的历史记录不受策略变化影响
+	var warnings []string
+	if req.RazorID != record.RazorID || req.BladeID != record.BladeID {
+		if warnings, err = s.checkCompatibility(req.RazorID, req.BladeID); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 
-	record.UsageTime = req.UsageTime
+	oldBladeID := record.BladeID
+	record.UsageTime = req.UsageTime.UTC()
 	record.RazorID = req.RazorID
 	record.BladeID = req.BladeID
-	record.BladeUsageCount = req.BladeUsageCount
+	record.BladeUsageCountOverride = req.BladeUsageCountOverride
 	record.Rating = req.Rating
 	record.ExperienceText = req.ExperienceText
 	record.NeedBladeChange = req.NeedBladeChange
 
+	// 换刀标记或刀片变化引起的库存差额由仓储层在同一事务内处理
 	if err := s.repo.UpdateUsageRecord(record); err != nil {
-		return nil, err
+		return nil, translateUsageReferenceError(err)
 	}
+	s.evaluateStock(oldBladeID, record.BladeID)
 
-	return s.repo.GetUsageRecordByID(record.ID)
+	updated, err := s.repo.GetUsageRecordByID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	updated.Warnings = warnings
+	return updated, nil
 }
 
 func (s *Service) DeleteUsageRecord(id uint) error {
-	_, err := s.repo.GetUsageRecordByID(id)
+	record, err := s.repo.GetUsageRecordByID(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("使用记录不存在")
-		}
-		return err
+		return translateRepoError(err)
+	}
+	if err := s.repo.DeleteUsageRecord(id); err != nil {
+		return translateRepoError(err)
 	}
+	s.evaluateStock(record.BladeID)
+	return nil
+}
 
-	return s.repo.DeleteUsageRecord(id)
+// RecountBladeInventory 按使用记录重新计算刀片剩余库存
+func (s *Service) RecountBladeInventory(id uint) (*model.Blade, error) {
+	blade, err := s.repo.RecountBladeInventory(id)
+	if err != nil {
+		return nil, translateRepoError(err)
+	}
+	s.evaluateStock(blade.ID)
+	return blade, nil
 }
 
 // 统计服务方法
@@ -339,4 +557,4 @@ func (s *Service) GetDashboardData() (map[string]interface{}, error) {
 
 func (s *Service) GetStatistics() (map[string]interface{}, error) {
 	return s.repo.GetUsageStatistics()
-}
\ No newline at end of file
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"razor-blade/internal/model"
+	"testing"
+)
+
+func TestUpdateBladeKeepsLowStockThreshold(t *testing.T) {
+	s := newTestService(t, "")
+	threshold := 5
+	blade, err := s.CreateBlade(&model.CreateBladeRequest{Brand: "Astra", Model: "SP", TotalQuantity: 10, LowStockThreshold: &threshold})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thresholdOf := func() *int {
+		t.Helper()
+		got, err := s.GetBladeByID(blade.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got.LowStockThreshold
+	}
+
+	// 只改备注，阈值保持不变
+	if _, err := s.UpdateBlade(blade.ID, &model.UpdateBladeRequest{Notes: "好用"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := thresholdOf(); got == nil || *got != 5 {
+		t.Fatalf("threshold after notes update = %v, want 5", got)
+	}
+
+	changed := 3
+	if _, err := s.UpdateBlade(blade.ID, &model.UpdateBladeRequest{LowStockThreshold: &changed}); err != nil {
+		t.Fatal(err)
+	}
+	if got := thresholdOf(); got == nil || *got != 3 {
+		t.Fatalf("threshold after update = %v, want 3", got)
+	}
+
+	if _, err := s.UpdateBlade(blade.ID, &model.UpdateBladeRequest{ClearLowStockThreshold: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := thresholdOf(); got != nil {
+		t.Errorf("threshold after clear = %d, want global setting", *got)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"fmt"
+	"razor-blade/internal/model"
+	"time"
+)
+
+// maxTimeSeriesBuckets 单次查询允许的最大桶数
+const maxTimeSeriesBuckets = 1000
+
+// GetTimeSeries 按天/周/月分桶统计使用次数、平均评分、换刀次数和花费
+func (s *Service) GetTimeSeries(req *model.TimeSeriesRequest) (*model.TimeSeries, error) {
+	bucket := req.Bucket
+	if bucket == "" {
+		bucket = model.BucketDay
+	}
+	loc, err := loadLocation(req.TZ)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := parseTimeParamIn("from", req.From, loc)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeParamIn("to", req.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	// 默认截止到当前时间，起点为最近30天/12周/12个月
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := defaultSeriesStart(*to, bucket)
+		from = &start
+	}
+	if !from.Before(*to) {
+		return nil, fmt.Errorf("%w: from 必须早于 to", ErrInvalidParam)
+	}
+
+	boundaries, err := bucketBoundaries(from.In(loc), to.In(loc), bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.UsageRecordFilter{
+		RazorID: req.RazorID,
+		BladeID: req.BladeID,
+		From:    from,
+		To:      to,
+	}
+	points, err := s.repo.GetUsageTimeSeries(filter, boundaries)
+	if err != nil {
+		return nil, err
+	}
+
+	conv := newCurrencyConverter(s.cfg.Cost.BaseCurrency, s.cfg.Cost.ExchangeRates)
+	if err := s.addSeriesSpend(points, filter.BladeID, conv); err != nil {
+		return nil, err
+	}
+
+	return &model.TimeSeries{
+		Bucket:                bucket,
+		Timezone:              loc.String(),
+		From:                  from.In(loc),
+		To:                    to.In(loc),
+		BaseCurrency:          conv.base,
+		Points:                points,
+		UnconvertedCurrencies: conv.unconvertedCurrencies(),
+	}, nil
+}
+
+// addSeriesSpend 按存储层聚合的各刀片换刀次数计算每个桶的花费，单价与花费统计一样按基础货币加权平均
+func (s *Service) addSeriesSpend(points []model.TimeSeriesPoint, bladeID uint, conv *currencyConverter) error {
+	if len(points) == 0 {
+		return nil
+	}
+	purchases, err := s.repo.GetAllPurchases(model.PurchaseFilter{BladeID: bladeID})
+	if err != nil {
+		return err
+	}
+	unitPrices := bladeUnitPrices(purchases, conv)
+	for i := range points {
+		for id, changes := range points[i].ChangesByBlade {
+			points[i].Spend += unitPrices[id] * float64(changes)
+		}
+	}
+	return nil
+}
+
+// loadLocation 解析tz查询参数，为空时使用服务器本地时区
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 未知的时区 %q", ErrInvalidParam, tz)
+	}
+	return loc, nil
+}
+
+// defaultSeriesStart 未指定from时的默认起点
+func defaultSeriesStart(to time.Time, bucket string) time.Time {
+	switch bucket {
+	case model.BucketWeek:
+		return to.AddDate(0, 0, -12*7)
+	case model.BucketMonth:
+		return to.AddDate(0, -12, 0)
+	default:
+		return to.AddDate(0, 0, -30)
+	}
+}
+
+// bucketBoundaries 生成覆盖[from, to)的桶边界，首个桶按粒度对齐到from所在的日/周一/月初。
+// 边界在from所在时区按日历推算，夏令时切换日的桶长度不是24小时
+func bucketBoundaries(from, to time.Time, bucket string) ([]time.Time, error) {
+	start := truncateToBucket(from, bucket)
+	boundaries := []time.Time{start}
+	for start.Before(to) {
+		start = nextBucket(start, bucket)
+		boundaries = append(boundaries, start)
+		if len(boundaries) > maxTimeSeriesBuckets+1 {
+			return nil, fmt.Errorf("%w: 时间范围过大，最多 %d 个时间桶", ErrInvalidParam, maxTimeSeriesBuckets)
+		}
+	}
+	return boundaries, nil
+}
+
+func truncateToBucket(t time.Time, bucket string) time.Time {
+	year, month, day := t.Date()
+	switch bucket {
+	case model.BucketWeek:
+		// 以周一为一周的开始
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case model.BucketMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case model.BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case model.BucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"math"
+	"razor-blade/internal/model"
+	"testing"
+	"time"
+)
+
+func TestGetTimeSeriesConvertsSpend(t *testing.T) {
+	s := newTestService(t, "")
+	s.cfg.Cost.ExchangeRates = map[string]float64{"usd": 7}
+	razor := mustRazor(t, s, "Merkur", "34C")
+	astra := mustBlade(t, s, "Astra", "SP", 1, razor.ID)
+	feather := mustBlade(t, s, "Feather", "Hi-Stainless", 1, razor.ID)
+
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	price := func(v float64) *float64 { return &v }
+	for _, req := range []model.CreatePurchaseRequest{
+		{BladeID: &astra.ID, PurchaseDate: &at, Quantity: 10, UnitPrice: price(1)},
+		{BladeID: &astra.ID, PurchaseDate: &at, Quantity: 10, UnitPrice: price(0.5), Currency: "USD"},
+		{BladeID: &feather.ID, PurchaseDate: &at, Quantity: 5, UnitPrice: price(100), Currency: "JPY"},
+	} {
+		if _, err := s.CreatePurchase(&req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	day := 24 * time.Hour
+	for _, u := range []struct {
+		offset  time.Duration
+		bladeID uint
+		change  bool
+	}{
+		{0, astra.ID, true},
+		{day, astra.ID, true},
+		{day + time.Hour, feather.ID, true}, // JPY没有汇率，不计入花费
+		{2 * day, astra.ID, false},
+	} {
+		_, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{
+			UsageTime: at.Add(u.offset), RazorID: razor.ID, BladeID: u.bladeID, NeedBladeChange: u.change,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	series, err := s.GetTimeSeries(&model.TimeSeriesRequest{From: "2024-03-01", To: "2024-03-04", TZ: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if series.BaseCurrency != "CNY" || len(series.UnconvertedCurrencies) != 1 || series.UnconvertedCurrencies[0] != "JPY" {
+		t.Errorf("currency = %s, unconverted %v", series.BaseCurrency, series.UnconvertedCurrencies)
+	}
+	// 单价为 (10×1 + 10×0.5×7) / 20 = 2.25 CNY，与花费统计一致
+	want := []float64{2.25, 2.25, 0}
+	if len(series.Points) != len(want) {
+		t.Fatalf("points = %d, want %d", len(series.Points), len(want))
+	}
+	for i, p := range series.Points {
+		if math.Abs(p.Spend-want[i]) > 1e-9 {
+			t.Errorf("point %d spend = %v, want %v", i, p.Spend, want[i])
+		}
+	}
+
+}
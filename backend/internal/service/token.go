@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"sort"
+	"time"
+)
+
+// 令牌列表中展示的前缀长度，包含rbt_
+const tokenPrefixLen = 12
+
+// translateTokenError API令牌操作失败时的错误信息
+func translateTokenError(err error) error {
+	if errors.Is(err, repository.ErrAPITokenNotFound) {
+		return notFoundError("API令牌不存在")
+	}
+	return err
+}
+
+// CreateAPIToken 创建API令牌，明文令牌只在创建时返回一次
+func (s *Service) CreateAPIToken(req *model.CreateAPITokenRequest) (*model.CreateAPITokenResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at 必须晚于当前时间", ErrInvalidParam)
+	}
+
+	token, err := newToken(model.APITokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	apiToken := model.APIToken{
+		Name:      req.Name,
+		Prefix:    token[:tokenPrefixLen],
+		TokenHash: hashToken(token),
+		Scopes:    uniqueScopes(req.Scopes),
+	}
+	if req.ExpiresAt != nil {
+		expires := req.ExpiresAt.UTC()
+		apiToken.ExpiresAt = &expires
+	}
+	if err := s.repo.CreateAPIToken(&apiToken); err != nil {
+		return nil, err
+	}
+	return &model.CreateAPITokenResponse{APIToken: apiToken, Token: token}, nil
+}
+
+func (s *Service) GetAPITokens() ([]model.APIToken, error) {
+	return s.repo.GetAPITokens()
+}
+
+// RevokeAPIToken 撤销API令牌，撤销后立即失效
+func (s *Service) RevokeAPIToken(id uint) error {
+	return translateTokenError(s.repo.RevokeAPIToken(id, time.Now()))
+}
+
+// GetAPITokenEvents 分页查询API令牌的审计记录
+func (s *Service) GetAPITokenEvents(id uint, req *model.APITokenEventListRequest) (*model.PaginationResponse, error) {
+	offset := normalizePage(&req.PaginationRequest)
+	events, total, err := s.repo.GetAPITokenEvents(id, offset, req.PageSize)
+	if err != nil {
+		return nil, translateTokenError(err)
+	}
+
+	return &model.PaginationResponse{
+		Items:      events,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(req.PageSize))),
+	}, nil
+}
+
+// AuthenticateAPIToken 返回API令牌及其所属用户，令牌已撤销或过期时返回ErrUnauthorized
+func (s *Service) AuthenticateAPIToken(token string) (*model.User, *model.APIToken, error) {
+	apiToken, err := s.repo.GetAPITokenByHash(hashToken(token))
+	if errors.Is(err, repository.ErrAPITokenNotFound) || (err == nil && !apiToken.Active(time.Now())) {
+		return nil, nil, fmt.Errorf("%w: API令牌无效、已过期或已撤销", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.repo.GetUserByID(apiToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, apiToken, nil
+}
+
+// RecordAPITokenUse 记录一次API令牌的使用，数据库只读时不记录
+func (s *Service) RecordAPITokenUse(event *model.APITokenEvent) error {
+	if s.cfg.Database.ReadOnly {
+		return nil
+	}
+	event.Event = model.TokenEventUsed
+	event.CreatedAt = time.Now().UTC()
+	return s.repo.RecordAPITokenUse(event)
+}
+
+// uniqueScopes 去重并排序权限范围
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
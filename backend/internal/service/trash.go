@@ -0,0 +1,65 @@
+package service
+
+import "razor-blade/internal/model"
+
+// RestoreRazor 从回收站恢复剃须刀及与其一起删除的使用记录
+func (s *Service) RestoreRazor(id uint) error {
+	if err := s.repo.RestoreRazor(id); err != nil {
+		return translateRepoError(err)
+	}
+	s.evaluateStock(s.changedBlades(model.UsageRecordFilter{RazorID: id})...)
+	return nil
+}
+
+// RestoreBlade 从回收站恢复刀片及与其一起删除的使用记录
+func (s *Service) RestoreBlade(id uint) error {
+	if err := s.repo.RestoreBlade(id); err != nil {
+		return translateRepoError(err)
+	}
+	s.evaluateStock(id)
+	return nil
+}
+
+// RestoreUsageRecord 从回收站恢复使用记录，换过刀片的记录重新扣减库存
+func (s *Service) RestoreUsageRecord(id uint) error {
+	if err := s.repo.RestoreUsageRecord(id); err != nil {
+		return translateRepoError(err)
+	}
+	if record, err := s.repo.GetUsageRecordByID(id); err == nil {
+		s.evaluateStock(record.BladeID)
+	}
+	return nil
+}
+
+// changedBlades 返回满足条件的使用记录中换过的刀片，删除或恢复这些记录会改变其库存
+func (s *Service) changedBlades(filter model.UsageRecordFilter) []uint {
+	records, err := s.repo.GetAllUsageRecords(filter)
+	if err != nil {
+		return nil
+	}
+	var ids []uint
+	for _, record := range records {
+		if record.NeedBladeChange {
+			ids = append(ids, record.BladeID)
+		}
+	}
+	return ids
+}
+
+// GetTrash 查询回收站，entity为空时返回全部类型
+func (s *Service) GetTrash(req *model.TrashRequest) (*model.Trash, error) {
+	trash, err := s.repo.GetTrash()
+	if err != nil {
+		return nil, err
+	}
+	if req.Entity != "" && req.Entity != model.TrashEntityRazors {
+		trash.Razors = []model.Razor{}
+	}
+	if req.Entity != "" && req.Entity != model.TrashEntityBlades {
+		trash.Blades = []model.Blade{}
+	}
+	if req.Entity != "" && req.Entity != model.TrashEntityUsageRecords {
+		trash.UsageRecords = []model.UsageRecord{}
+	}
+	return trash, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"razor-blade/internal/config"
+	"razor-blade/internal/model"
+	"razor-blade/internal/repository"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, compatibility string) *Service {
+	t.Helper()
+	cfg := &config.Config{
+		Usage:     config.UsageConfig{CompatibilityCheck: compatibility},
+		Inventory: config.InventoryConfig{LowStockThreshold: 2},
+		Cost:      config.CostConfig{BaseCurrency: "CNY"},
+	}
+	return NewService(repository.NewMemoryStore(), cfg, nil)
+}
+
+func mustRazor(t *testing.T, s *Service, brand, name string) *model.Razor {
+	t.Helper()
+	razor, err := s.CreateRazor(&model.CreateRazorRequest{Brand: brand, Model: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return razor
+}
+
+func mustBlade(t *testing.T, s *Service, brand, name string, quantity int, razorIDs ...uint) *model.Blade {
+	t.Helper()
+	blade, err := s.CreateBlade(&model.CreateBladeRequest{
+		Brand: brand, Model: name, TotalQuantity: quantity, CompatibleRazorIDs: razorIDs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blade
+}
+
+func TestUpdateUsageRecordChecksCompatibility(t *testing.T) {
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+
+	t.Run("reject", func(t *testing.T) {
+		s := newTestService(t, config.CompatibilityCheckReject)
+		razor := mustRazor(t, s, "Merkur", "34C")
+		other := mustRazor(t, s, "Gillette", "Tech")
+		blade := mustBlade(t, s, "Astra", "SP", 5, razor.ID)
+
+		record, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{UsageTime: at, RazorID: razor.ID, BladeID: blade.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.UpdateUsageRecord(record.ID, &model.UpdateUsageRecordRequest{UsageTime: at, RazorID: other.ID, BladeID: blade.ID})
+		if !errors.Is(err, ErrIncompatibleBlade) {
+			t.Fatalf("switch to incompatible razor: err = %v, want ErrIncompatibleBlade", err)
+		}
+		if got, _ := s.GetUsageRecordByID(record.ID); got.RazorID != razor.ID {
+			t.Errorf("razor after rejected update = %d, want %d", got.RazorID, razor.ID)
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		s := newTestService(t, config.CompatibilityCheckWarn)
+		razor := mustRazor(t, s, "Merkur", "34C")
+		compatible := mustBlade(t, s, "Astra", "SP", 5, razor.ID)
+		incompatible := mustBlade(t, s, "Feather", "Pro", 5)
+
+		record, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{UsageTime: at, RazorID: razor.ID, BladeID: compatible.ID})
+		if err != nil || len(record.Warnings) != 0 {
+			t.Fatalf("create: %v, warnings %v", err, record.Warnings)
+		}
+		updated, err := s.UpdateUsageRecord(record.ID, &model.UpdateUsageRecordRequest{UsageTime: at, RazorID: razor.ID, BladeID: incompatible.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(updated.Warnings) != 1 || updated.BladeID != incompatible.ID {
+			t.Errorf("switch to incompatible blade: blade %d, warnings %v", updated.BladeID, updated.Warnings)
+		}
+
+		// 未更换剃须刀或刀片时不再提示
+		updated, err = s.UpdateUsageRecord(record.ID, &model.UpdateUsageRecordRequest{
+			UsageTime: at, RazorID: razor.ID, BladeID: incompatible.ID, ExperienceText: "ok",
+		})
+		if err != nil || len(updated.Warnings) != 0 {
+			t.Errorf("unchanged pairing: %v, warnings %v", err, updated.Warnings)
+		}
+	})
+}
+
+func TestGetUsageRecordsCursor(t *testing.T) {
+	s := newTestService(t, "")
+	razor := mustRazor(t, s, "Merkur", "34C")
+	blade := mustBlade(t, s, "Astra", "SP", 5, razor.ID)
+	at := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		// 前三条时间相同
+		offset := time.Duration(max(i-2, 0)) * time.Hour
+		record, err := s.CreateUsageRecord(&model.CreateUsageRecordRequest{UsageTime: at.Add(offset), RazorID: razor.ID, BladeID: blade.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, record.ID)
+	}
+
+	page := func(cursor string) *model.PaginationResponse {
+		t.Helper()
+		resp, err := s.GetUsageRecords(&model.UsageRecordListRequest{Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	pageIDs := func(resp *model.PaginationResponse) []uint {
+		var got []uint
+		for _, record := range resp.Items.([]model.UsageRecord) {
+			got = append(got, record.ID)
+		}
+		return got
+	}
+
+	// 默认新记录在前：5 4 | 3 2 | 1
+	first := page("")
+	second := page(first.NextCursor)
+	third := page(second.NextCursor)
+	got := append(append(pageIDs(first), pageIDs(second)...), pageIDs(third)...)
+	want := []uint{ids[4], ids[3], ids[2], ids[1], ids[0]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+	if first.PrevCursor != "" || third.NextCursor != "" {
+		t.Errorf("first page prev %q, last page next %q", first.PrevCursor, third.NextCursor)
+	}
+	if back := page(third.PrevCursor); fmt.Sprint(pageIDs(back)) != fmt.Sprint(pageIDs(second)) {
+		t.Errorf("back from last page = %v, want %v", pageIDs(back), pageIDs(second))
+	}
+
+	for name, cursor := range map[string]string{
+		"not base64":  "!!!",
+		"not json":    base64.RawURLEncoding.EncodeToString([]byte("page=2")),
+		"zero id":     base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2024-03-01T07:00:00Z","i":0}`)),
+		"bad time":    base64.RawURLEncoding.EncodeToString([]byte(`{"t":"yesterday","i":1}`)),
+		"wrong types": base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2024-03-01T07:00:00Z","i":"1"}`)),
+	} {
+		if _, err := s.GetUsageRecords(&model.UsageRecordListRequest{Cursor: cursor}); !errors.Is(err, ErrInvalidParam) {
+			t.Errorf("%s: err = %v, want ErrInvalidParam", name, err)
+		}
+	}
+}
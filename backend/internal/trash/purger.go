@@ -0,0 +1,75 @@
+package trash
+
+import (
+	"razor-blade/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Purger 定期彻底删除在回收站中超过保留期的数据，覆盖全部用户
+type Purger struct {
+	store     repository.Store
+	retention time.Duration
+	interval  time.Duration
+	logger    *logrus.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewPurger(store repository.Store, retention, interval time.Duration, logger *logrus.Logger) *Purger {
+	return &Purger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start 启动后立即清理一次，之后按间隔执行。保留期或间隔不大于0时不启动
+func (p *Purger) Start() {
+	if p.retention <= 0 || p.interval <= 0 {
+		p.logger.Info("Trash purge disabled")
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.Purge()
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期清理，等待进行中的清理完成
+func (p *Purger) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// Purge 执行一次清理，出错只记录日志
+func (p *Purger) Purge() {
+	result, err := p.store.PurgeTrash(time.Now().Add(-p.retention))
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to purge trash")
+		return
+	}
+	if result.Razors+result.Blades+result.UsageRecords == 0 {
+		return
+	}
+	p.logger.WithFields(logrus.Fields{
+		"razors":        result.Razors,
+		"blades":        result.Blades,
+		"usage_records": result.UsageRecords,
+	}).Info("Purged expired trash")
+}
@@ -4,42 +4,93 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"razor-blade/internal/config"
+
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-func InitDB(dbPath string) (*gorm.DB, error) {
-	// 在Windows开发环境中，如果CGO不可用，使用内存数据库
-	var dsn string
+// InitDB 根据配置的驱动连接数据库，连接失败时返回错误，不退回到内存数据库。
+// ReadOnly时SQLite以只读方式打开，不执行任何写入
+func InitDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case "", "sqlite":
+		return initSQLite(cfg.Path, cfg.ReadOnly)
+	case "postgres":
+		return initPostgres(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+}
+
+func initPostgres(dsn string) (*gorm.DB, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("database.dsn is required for postgres driver")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	return db, nil
+}
+
+func initSQLite(dbPath string, readOnly bool) (*gorm.DB, error) {
 	if dbPath == "" || dbPath == ":memory:" {
-		dsn = ":memory:"
-	} else {
-		// 确保数据库目录存在
-		dir := filepath.Dir(dbPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create database directory: %w", err)
-		}
-		dsn = dbPath
-	}
-
-	// 连接数据库，添加pragma参数以支持CGO禁用的情况
-	db, err := gorm.Open(sqlite.Open(dsn+"?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"), &gorm.Config{
+		return nil, fmt.Errorf("database.path must be a file for sqlite driver, set database.mode to memory for a non-persistent store")
+	}
+
+	// 连接数据库：等待锁而不是立即返回busy，事务以IMMEDIATE方式开始，
+	// 避免并发的读后写事务在升级写锁时相互冲突
+	dsn := dbPath + "?_busy_timeout=10000&_journal_mode=WAL&_foreign_keys=on&_txlock=immediate"
+	if readOnly {
+		// 只读时不能切换日志模式
+		dsn = "file:" + dbPath + "?mode=ro&_busy_timeout=10000&_foreign_keys=on"
+	} else if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// SQLite按文本比较时间，统一以UTC写入
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
-		// 如果文件数据库失败，尝试使用内存数据库
-		if dsn != ":memory:" {
-			fmt.Printf("Warning: Failed to open file database, falling back to memory database: %v\n", err)
-			db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-				Logger: logger.Default.LogMode(logger.Info),
-			})
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect database: %w", err)
-		}
+		return nil, fmt.Errorf("failed to open database %s: %w", dbPath, err)
+	}
+	// gorm.Open不一定建立连接，在这里确认文件可以打开
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", dbPath, err)
 	}
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+// SQLiteWritable 判断SQLite数据库文件及其所在目录是否可写，WAL模式需要在目录中创建-wal和-shm文件。
+// 文件不存在时只检查目录，目录不存在时检查能否创建
+func SQLiteWritable(dbPath string) bool {
+	if f, err := os.OpenFile(dbPath, os.O_WRONLY, 0); err == nil {
+		f.Close()
+	} else if !os.IsNotExist(err) {
+		return false
+	}
+
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".razor-blade-write-check-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
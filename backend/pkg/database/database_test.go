@@ -0,0 +1,91 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"razor-blade/internal/config"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestInitDBRejectsBadDriverConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  config.DatabaseConfig
+		want string
+	}{
+		{"unknown driver", config.DatabaseConfig{Driver: "mysql"}, "unsupported database driver"},
+		{"postgres without dsn", config.DatabaseConfig{Driver: "postgres"}, "database.dsn is required"},
+		{"sqlite without path", config.DatabaseConfig{Driver: "sqlite"}, "database.path must be a file"},
+		{"sqlite in memory", config.DatabaseConfig{Path: ":memory:"}, "database.path must be a file"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := InitDB(&tc.cfg)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestInitSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "razor-blade.db")
+	db := openTestDB(t, &config.DatabaseConfig{Path: path})
+	if db.Dialector.Name() != "sqlite" {
+		t.Errorf("dialector = %s", db.Dialector.Name())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("database file not created: %v", err)
+	}
+
+	var foreignKeys int
+	var journalMode string
+	db.Raw("PRAGMA foreign_keys").Scan(&foreignKeys)
+	db.Raw("PRAGMA journal_mode").Scan(&journalMode)
+	if foreignKeys != 1 || journalMode != "wal" {
+		t.Errorf("foreign_keys=%d journal_mode=%s, want 1 and wal", foreignKeys, journalMode)
+	}
+	if !SQLiteWritable(path) {
+		t.Error("SQLiteWritable = false for a fresh database")
+	}
+}
+
+func TestInitSQLiteReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "razor-blade.db")
+	rw := openTestDB(t, &config.DatabaseConfig{Path: path})
+	if err := rw.Exec("CREATE TABLE t (v INTEGER)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ro := openTestDB(t, &config.DatabaseConfig{Path: path, ReadOnly: true})
+	var count int64
+	if err := ro.Raw("SELECT COUNT(*) FROM t").Scan(&count).Error; err != nil {
+		t.Errorf("read from read-only database: %v", err)
+	}
+	if err := ro.Exec("INSERT INTO t (v) VALUES (1)").Error; err == nil {
+		t.Error("write to read-only database succeeded")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if _, err := InitDB(&config.DatabaseConfig{Path: missing, ReadOnly: true}); err == nil {
+		t.Error("read-only open of a missing file succeeded")
+	}
+}
+
+func openTestDB(t *testing.T, cfg *config.DatabaseConfig) *gorm.DB {
+	t.Helper()
+	db, err := InitDB(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = db.Logger.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
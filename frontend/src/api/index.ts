@@ -13,7 +13,34 @@ import type {
   CreateUsageRecordRequest,
   UpdateUsageRecordRequest,
   DashboardData,
-  Statistics
+  Statistics,
+  BladeLifetime,
+  Alert,
+  BladeForecastReport,
+  MountedBlade,
+  TimeSeries,
+  TimeSeriesRequest,
+  CostReport,
+  Purchase,
+  CreatePurchaseRequest,
+  UpdatePurchaseRequest,
+  PurchaseListRequest,
+  User,
+  LoginRequest,
+  RegisterRequest,
+  AuthResponse,
+  APIToken,
+  APITokenEvent,
+  CreateAPITokenRequest,
+  CreateAPITokenResponse,
+  AuditEvent,
+  AuditListRequest,
+  Trash,
+  TrashRequest,
+  DeleteRequest,
+  ExportRequest,
+  ImportRequest,
+  ImportResult
 } from '@/types'
 
 const api = axios.create({
@@ -21,9 +48,26 @@ const api = axios.create({
   timeout: 10000,
 })
 
+// 登录令牌保存在localStorage中
+const TOKEN_KEY = 'razor-blade-token'
+
+export const getToken = (): string | null => localStorage.getItem(TOKEN_KEY)
+
+export const setToken = (token: string | null) => {
+  if (token) {
+    localStorage.setItem(TOKEN_KEY, token)
+  } else {
+    localStorage.removeItem(TOKEN_KEY)
+  }
+}
+
 // 请求拦截器
 api.interceptors.request.use(
   (config) => {
+    const token = getToken()
+    if (token) {
+      config.headers.Authorization = `Bearer ${token}`
+    }
     return config
   },
   (error) => {
@@ -38,10 +82,45 @@ api.interceptors.response.use(
   },
   (error) => {
     console.error('API Error:', error)
+    // 令牌失效时回到登录页
+    if (error.response?.status === 401 && window.location.pathname !== '/login') {
+      setToken(null)
+      window.location.href = '/login'
+    }
     return Promise.reject(error)
   }
 )
 
+// 用户认证API
+export const authAPI = {
+  register: (data: RegisterRequest): Promise<APIResponse<AuthResponse>> =>
+    api.post('/auth/register', data),
+
+  login: (data: LoginRequest): Promise<APIResponse<AuthResponse>> =>
+    api.post('/auth/login', data),
+
+  logout: (): Promise<APIResponse<null>> =>
+    api.post('/auth/logout'),
+
+  me: (): Promise<APIResponse<User>> =>
+    api.get('/auth/me')
+}
+
+// API令牌管理
+export const tokenAPI = {
+  create: (data: CreateAPITokenRequest): Promise<APIResponse<CreateAPITokenResponse>> =>
+    api.post('/tokens', data),
+
+  getList: (): Promise<APIResponse<APIToken[]>> =>
+    api.get('/tokens'),
+
+  revoke: (id: number): Promise<APIResponse<null>> =>
+    api.delete(`/tokens/${id}`),
+
+  getEvents: (id: number, params?: PaginationRequest): Promise<APIResponse<PaginationResponse<APITokenEvent>>> =>
+    api.get(`/tokens/${id}/events`, { params })
+}
+
 // 剃须刀相关API
 export const razorAPI = {
   create: (data: CreateRazorRequest): Promise<APIResponse<Razor>> =>
@@ -56,8 +135,14 @@ export const razorAPI = {
   update: (id: number, data: UpdateRazorRequest): Promise<APIResponse<Razor>> =>
     api.put(`/razors/${id}`, data),
 
-  delete: (id: number): Promise<APIResponse<null>> =>
-    api.delete(`/razors/${id}`)
+  delete: (id: number, params?: DeleteRequest): Promise<APIResponse<null>> =>
+    api.delete(`/razors/${id}`, { params }),
+
+  restore: (id: number): Promise<APIResponse<null>> =>
+    api.post(`/razors/${id}/restore`),
+
+  getMountedBlade: (id: number): Promise<APIResponse<MountedBlade>> =>
+    api.get(`/razors/${id}/mounted-blade`)
 }
 
 // 刀片相关API
@@ -74,8 +159,17 @@ export const bladeAPI = {
   update: (id: number, data: UpdateBladeRequest): Promise<APIResponse<Blade>> =>
     api.put(`/blades/${id}`, data),
 
-  delete: (id: number): Promise<APIResponse<null>> =>
-    api.delete(`/blades/${id}`)
+  delete: (id: number, params?: DeleteRequest): Promise<APIResponse<null>> =>
+    api.delete(`/blades/${id}`, { params }),
+
+  restore: (id: number): Promise<APIResponse<null>> =>
+    api.post(`/blades/${id}/restore`),
+
+  getForecast: (params?: { window_days?: number; lead_time_days?: number }): Promise<APIResponse<BladeForecastReport>> =>
+    api.get('/blades/forecast', { params }),
+
+  getLifetime: (id: number, params?: { threshold?: number }): Promise<APIResponse<BladeLifetime>> =>
+    api.get(`/blades/${id}/lifetime`, { params })
 }
 
 // 使用记录相关API
@@ -93,7 +187,28 @@ export const usageRecordAPI = {
     api.put(`/usage-records/${id}`, data),
 
   delete: (id: number): Promise<APIResponse<null>> =>
-    api.delete(`/usage-records/${id}`)
+    api.delete(`/usage-records/${id}`),
+
+  restore: (id: number): Promise<APIResponse<null>> =>
+    api.post(`/usage-records/${id}/restore`)
+}
+
+// 购买记录相关API
+export const purchaseAPI = {
+  create: (data: CreatePurchaseRequest): Promise<APIResponse<Purchase>> =>
+    api.post('/purchases', data),
+
+  getById: (id: number): Promise<APIResponse<Purchase>> =>
+    api.get(`/purchases/${id}`),
+
+  getList: (params?: PurchaseListRequest): Promise<APIResponse<PaginationResponse<Purchase>>> =>
+    api.get('/purchases', { params }),
+
+  update: (id: number, data: UpdatePurchaseRequest): Promise<APIResponse<Purchase>> =>
+    api.put(`/purchases/${id}`, data),
+
+  delete: (id: number): Promise<APIResponse<null>> =>
+    api.delete(`/purchases/${id}`)
 }
 
 // 统计相关API
@@ -102,7 +217,54 @@ export const statisticsAPI = {
     api.get('/dashboard'),
 
   getStatistics: (): Promise<APIResponse<Statistics>> =>
-    api.get('/statistics')
+    api.get('/statistics'),
+
+  getTimeSeries: (params?: TimeSeriesRequest): Promise<APIResponse<TimeSeries>> =>
+    api.get('/statistics/timeseries', { params }),
+
+  getCost: (params?: { tz?: string }): Promise<APIResponse<CostReport>> =>
+    api.get('/statistics/cost', { params })
+}
+
+// 库存告警API
+export const alertAPI = {
+  getList: (params?: PaginationRequest & { blade_id?: number; open?: boolean }): Promise<APIResponse<PaginationResponse<Alert>>> =>
+    api.get('/alerts', { params })
+}
+
+// 审计日志
+export const auditAPI = {
+  getList: (params?: AuditListRequest): Promise<APIResponse<PaginationResponse<AuditEvent>>> =>
+    api.get('/audit', { params })
+}
+
+// 回收站相关API
+export const trashAPI = {
+  get: (params?: TrashRequest): Promise<APIResponse<Trash>> =>
+    api.get('/trash', { params })
+}
+
+// 导出相关API，返回文件内容，数据量大时耗时较长因此不设超时
+export const exportAPI = {
+  download: (params?: ExportRequest): Promise<Blob> =>
+    api.get('/export', { params, responseType: 'blob', timeout: 0 })
+}
+
+// 管理API，只有管理员可以访问
+export const adminAPI = {
+  // 下载数据库备份，包含全部用户的数据
+  backup: (): Promise<Blob> =>
+    api.get('/admin/backup', { responseType: 'blob', timeout: 0 })
+}
+
+// 导入相关API，文件内容作为请求体，格式与导出相同
+export const importAPI = {
+  upload: (file: Blob, params?: ImportRequest): Promise<APIResponse<ImportResult>> =>
+    api.post('/import', file, {
+      params,
+      headers: { 'Content-Type': 'application/octet-stream' },
+      timeout: 0
+    })
 }
 
 export default api
\ No newline at end of file
@@ -1,8 +1,15 @@
 import { createRouter, createWebHistory } from 'vue-router'
+import { getToken } from '@/api'
 
 const router = createRouter({
   history: createWebHistory(),
   routes: [
+    {
+      path: '/login',
+      name: 'Login',
+      component: () => import('@/views/Login.vue'),
+      meta: { public: true }
+    },
     {
       path: '/',
       name: 'Dashboard',
@@ -31,4 +38,11 @@ const router = createRouter({
   ]
 })
 
+// 未登录时跳转到登录页
+router.beforeEach((to) => {
+  if (!to.meta.public && !getToken()) {
+    return { name: 'Login' }
+  }
+})
+
 export default router
\ No newline at end of file
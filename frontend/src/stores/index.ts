@@ -6,7 +6,8 @@ import type {
   UsageRecord,
   DashboardData,
   Statistics,
-  PaginationRequest
+  PaginationRequest,
+  DeleteRequest
 } from '@/types'
 import {
   razorAPI,
@@ -83,10 +84,10 @@ export const useRazorStore = defineStore('razor', () => {
     }
   }
 
-  const deleteRazor = async (id: number) => {
+  const deleteRazor = async (id: number, params?: DeleteRequest) => {
     loading.value = true
     try {
-      const response = await razorAPI.delete(id)
+      const response = await razorAPI.delete(id, params)
       if (response.success) {
         await fetchRazors()
         return response
@@ -180,10 +181,10 @@ export const useBladeStore = defineStore('blade', () => {
     }
   }
 
-  const deleteBlade = async (id: number) => {
+  const deleteBlade = async (id: number, params?: DeleteRequest) => {
     loading.value = true
     try {
-      const response = await bladeAPI.delete(id)
+      const response = await bladeAPI.delete(id, params)
       if (response.success) {
         await fetchBlades()
         return response
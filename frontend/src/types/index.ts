@@ -4,23 +4,28 @@ export interface Razor {
   model: string
   purchase_date?: string
   price?: number
+  currency: string
   notes: string
   created_at: string
   updated_at: string
+  deleted_at: string | null // 非空表示在回收站中
 }
 
 export interface Blade {
   id: number
   brand: string
   model: string
-  compatible_razors: string
+  compatible_razor_ids: number[]
   purchase_date?: string
   unit_price?: number
+  currency: string
   total_quantity: number
   remaining_quantity: number
+  low_stock_threshold?: number | null
   notes: string
   created_at: string
   updated_at: string
+  deleted_at: string | null
 }
 
 export interface UsageRecord {
@@ -29,13 +34,16 @@ export interface UsageRecord {
   razor_id: number
   blade_id: number
   blade_usage_count: number
+  blade_usage_count_override?: number | null
   rating?: number
   experience_text: string
   need_blade_change: boolean
   created_at: string
   updated_at: string
+  deleted_at: string | null
   razor: Razor
   blade: Blade
+  warnings?: string[]
 }
 
 export interface CreateRazorRequest {
@@ -43,54 +51,109 @@ export interface CreateRazorRequest {
   model: string
   purchase_date?: string
   price?: number
+  currency?: string
   notes?: string
 }
 
 export interface UpdateRazorRequest {
   brand?: string
   model?: string
-  purchase_date?: string
-  price?: number
   notes?: string
 }
 
+// 删除剃须刀或刀片时使用记录的处理方式，都不指定时仍有依赖会返回409和DependencyReport
+export interface DeleteRequest {
+  cascade?: boolean
+  reassign_to?: number
+}
+
+export interface DependencyReport {
+  entity: 'razor' | 'blade'
+  id: number
+  usage_record_ids: number[]
+  compatible_blade_ids?: number[]
+}
+
 export interface CreateBladeRequest {
   brand: string
   model: string
-  compatible_razors?: string
+  compatible_razor_ids?: number[]
   purchase_date?: string
   unit_price?: number
+  currency?: string
   total_quantity?: number
-  remaining_quantity?: number
+  low_stock_threshold?: number
   notes?: string
 }
 
 export interface UpdateBladeRequest {
   brand?: string
   model?: string
-  compatible_razors?: string
+  compatible_razor_ids?: number[]
+  low_stock_threshold?: number
+  clear_low_stock_threshold?: boolean
+  notes?: string
+}
+
+export interface Purchase {
+  id: number
+  razor_id: number | null
+  blade_id: number | null
+  purchase_date: string
+  quantity: number
+  unit_price?: number | null
+  currency: string
+  vendor: string
+  notes: string
+  created_at: string
+  updated_at: string
+}
+
+export interface CreatePurchaseRequest {
+  razor_id?: number
+  blade_id?: number
   purchase_date?: string
+  quantity: number
   unit_price?: number
-  total_quantity?: number
-  remaining_quantity?: number
+  currency?: string
+  vendor?: string
+  notes?: string
+}
+
+export interface UpdatePurchaseRequest {
+  purchase_date?: string
+  quantity?: number
+  unit_price?: number
+  currency?: string
+  vendor?: string
   notes?: string
 }
 
+export interface PurchaseListRequest extends PaginationRequest {
+  razor_id?: number
+  blade_id?: number
+  item_type?: 'razor' | 'blade'
+  vendor?: string
+  from?: string
+  to?: string
+  sort?: string
+}
+
 export interface CreateUsageRecordRequest {
   usage_time: string
   razor_id: number
   blade_id: number
-  blade_usage_count?: number
+  blade_usage_count_override?: number
   rating?: number
   experience_text?: string
   need_blade_change?: boolean
 }
 
 export interface UpdateUsageRecordRequest {
-  usage_time?: string
-  razor_id?: number
-  blade_id?: number
-  blade_usage_count?: number
+  usage_time: string
+  razor_id: number
+  blade_id: number
+  blade_usage_count_override?: number
   rating?: number
   experience_text?: string
   need_blade_change?: boolean
@@ -114,6 +177,8 @@ export interface PaginationResponse<T = any> {
   page_size: number
   total: number
   total_pages: number
+  next_cursor?: string
+  prev_cursor?: string
 }
 
 export interface Statistics {
@@ -123,7 +188,261 @@ export interface Statistics {
   average_rating: number
 }
 
+export interface TimeSeriesPoint {
+  start: string
+  end: string
+  shave_count: number
+  average_rating: number | null
+  blade_changes: number
+  spend: number
+}
+
+export interface TimeSeries {
+  bucket: 'day' | 'week' | 'month'
+  timezone: string
+  from: string
+  to: string
+  base_currency: string
+  points: TimeSeriesPoint[]
+  unconverted_currencies?: string[]
+}
+
+export interface TimeSeriesRequest {
+  from?: string
+  to?: string
+  bucket?: 'day' | 'week' | 'month'
+  razor_id?: number
+  blade_id?: number
+  tz?: string
+}
+
+export interface MonthlySpend {
+  month: string
+  razors: number
+  blades: number
+  total: number
+}
+
+export interface RazorCost {
+  razor_id: number
+  brand: string
+  model: string
+  price: number | null
+  shaves: number
+  blades_used: number
+  blade_spend: number
+  razor_cost_per_shave: number | null
+  blade_cost_per_shave: number | null
+  cost_per_shave: number | null
+}
+
+export interface CostReport {
+  base_currency: string
+  total_spend: number
+  razor_spend: number
+  blade_spend: number
+  total_shaves: number
+  razor_cost_per_shave: number | null
+  blade_cost_per_shave: number | null
+  cost_per_shave: number | null
+  monthly_spend: MonthlySpend[]
+  razors: RazorCost[]
+  unconverted_currencies?: string[]
+}
+
+export interface BladeLife {
+  razor_id: number
+  start: string
+  end: string
+  shaves: number
+  average_rating: number | null
+  completed: boolean
+}
+
+export interface BladeLifetime {
+  blade_id: number
+  completed_lives: number
+  average_shaves: number | null
+  median_shaves: number | null
+  rating_threshold: number
+  drop_shave: number | null
+  rating_by_shave: { shave_index: number; average_rating: number | null; samples: number }[]
+  lives: BladeLife[]
+}
+
+export interface MountedBlade {
+  razor_id: number
+  blade: Blade | null
+  shave_count: number
+  next_shave_index: number
+  last_used_at: string | null
+}
+
+export interface BladeForecast {
+  blade_id: number
+  brand: string
+  model: string
+  remaining_quantity: number
+  changes_in_window: number
+  daily_rate: number
+  run_out_date: string | null
+  run_out_earliest: string | null
+  run_out_latest: string | null
+  reorder_date: string | null
+  reorder_now: boolean
+}
+
+export interface BladeForecastReport {
+  generated_at: string
+  window_days: number
+  lead_time_days: number
+  confidence_level: number
+  items: BladeForecast[]
+}
+
+export interface Alert {
+  id: number
+  blade_id: number
+  level: 'low_stock' | 'out_of_stock'
+  remaining_quantity: number
+  threshold: number
+  message: string
+  created_at: string
+  resolved_at: string | null
+}
+
 export interface DashboardData {
   statistics: Statistics
   recent_records: UsageRecord[]
-}
\ No newline at end of file
+}
+export interface User {
+  id: number
+  username: string
+  created_at: string
+  updated_at: string
+}
+
+export interface LoginRequest {
+  username: string
+  password: string
+}
+
+export type RegisterRequest = LoginRequest
+
+export interface AuthResponse {
+  token: string
+  expires_at: string
+  user: User
+}
+
+export type TokenScope = 'read' | 'write' | 'admin'
+
+export interface APIToken {
+  id: number
+  name: string
+  prefix: string
+  scopes: TokenScope[]
+  expires_at: string | null
+  last_used_at: string | null
+  revoked_at: string | null
+  created_at: string
+}
+
+export interface CreateAPITokenRequest {
+  name: string
+  scopes: TokenScope[]
+  expires_at?: string
+}
+
+// 明文令牌只在创建时返回一次
+export interface CreateAPITokenResponse extends APIToken {
+  token: string
+}
+
+export interface APITokenEvent {
+  id: number
+  token_id: number
+  event: 'created' | 'used' | 'revoked'
+  method: string
+  path: string
+  status_code: number
+  client_ip: string
+  created_at: string
+}
+
+export type AuditEntity = 'razor' | 'blade' | 'usage_record' | 'purchase'
+
+export type AuditAction = 'create' | 'update' | 'delete' | 'restore'
+
+// 新增和恢复时before为null，删除时after为null，更新时只包含有变化的字段
+export interface AuditEvent {
+  id: number
+  actor_user_id: number
+  actor_token_id: number | null
+  request_id: string
+  entity_type: AuditEntity
+  entity_id: number
+  action: AuditAction
+  before: Record<string, unknown> | null
+  after: Record<string, unknown> | null
+  created_at: string
+}
+
+export interface AuditListRequest extends PaginationRequest {
+  entity?: AuditEntity
+  id?: number
+  action?: AuditAction
+  from?: string
+  to?: string
+}
+
+// 回收站中的数据，按删除时间倒序
+export interface Trash {
+  razors: Razor[]
+  blades: Blade[]
+  usage_records: UsageRecord[]
+}
+
+export interface TrashRequest {
+  entity?: 'razors' | 'blades' | 'usage_records'
+}
+
+export type ExportFormat = 'csv' | 'json' | 'ndjson'
+export type ExportEntity = 'razors' | 'blades' | 'usage_records'
+
+export interface ExportRequest {
+  format?: ExportFormat
+  // 逗号分隔的ExportEntity，为空时导出全部
+  entities?: string
+  from?: string
+  to?: string
+}
+
+export interface ImportRequest {
+  format?: ExportFormat
+  // 导入单个CSV文件时指定其中的实体
+  entity?: ExportEntity
+  dry_run?: boolean
+}
+
+export interface ImportRowError {
+  entity: ExportEntity
+  line: number
+  error: string
+}
+
+export interface ImportEntityResult {
+  created: number
+  matched: number
+  // 文件中的ID到导入后ID的映射
+  id_map: Record<string, number>
+}
+
+// 导入结果，errors非空时没有写入任何数据
+export interface ImportResult {
+  dry_run: boolean
+  razors: ImportEntityResult
+  blades: ImportEntityResult
+  usage_records: ImportEntityResult
+  errors: ImportRowError[]
+}